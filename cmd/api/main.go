@@ -2,9 +2,13 @@ package main
 
 import (
 	"github.com/rs/zerolog/log"
+	"runtime"
+	"tarkov-build-optimiser/internal/candidate_tree"
 	"tarkov-build-optimiser/internal/db"
 	"tarkov-build-optimiser/internal/env"
+	"tarkov-build-optimiser/internal/optimiser"
 	"tarkov-build-optimiser/internal/router"
+	"time"
 )
 
 func main() {
@@ -18,7 +22,26 @@ func main() {
 		log.Fatal().Err(err).Msg("Failed to connect to db")
 	}
 
-	cfg := router.Config{DB: dbClient}
+	// live optimisations share one DataService so item data is only loaded once
+	dataService := candidate_tree.CreateDataService(dbClient.Conn)
+	start := time.Now()
+	err = dataService.Warm()
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to load item data")
+	}
+	log.Info().Msgf("Loaded item data in %s", time.Since(start))
+
+	maxConcurrent := environment.OptimiseMaxConcurrent
+	if maxConcurrent < 1 {
+		maxConcurrent = runtime.NumCPU()
+	}
+	optimiserService := optimiser.NewService(dataService, optimiser.Config{
+		MaxConcurrent: maxConcurrent,
+		MaxQueued:     environment.OptimiseMaxQueued,
+		Timeout:       time.Duration(environment.OptimiseTimeoutSeconds) * time.Second,
+	})
+
+	cfg := router.Config{DB: dbClient, Optimiser: optimiserService, Data: dataService}
 	r := router.NewRouter(cfg)
 
 	err = r.Start(":8080")
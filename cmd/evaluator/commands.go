@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/cli"
+	"tarkov-build-optimiser/internal/gunsmith"
+	"tarkov-build-optimiser/internal/jobs"
+	"tarkov-build-optimiser/internal/models"
+
+	"github.com/rs/zerolog/log"
+)
+
+func runStatus(db *sql.DB, cmd cli.EvaluatorCommand, selection jobs.Selection) error {
+	if cmd.ShowFailed {
+		builds, err := models.GetFailedBuilds(db, selection.Filter())
+		if err != nil {
+			return err
+		}
+		return jobs.WriteFailedBuilds(os.Stdout, builds)
+	}
+
+	if cmd.ShardCount > 0 {
+		counts, err := models.GetShardStatusCounts(db, selection.Filter(), cmd.ShardCount)
+		if err != nil {
+			return err
+		}
+		return jobs.WriteShardStatusCounts(os.Stdout, counts, cmd.ShardCount)
+	}
+
+	counts, err := models.GetBuildStatusCounts(db, selection.Filter())
+	if err != nil {
+		return err
+	}
+	return jobs.WriteStatusCounts(os.Stdout, counts)
+}
+
+func runRequeue(db *sql.DB, cmd cli.EvaluatorCommand, selection jobs.Selection) error {
+	requeued, err := models.RequeueBuilds(db, selection.Filter(), cmd.Statuses)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msgf("Requeued %d builds", requeued)
+	return nil
+}
+
+func runPurge(db *sql.DB, cmd cli.EvaluatorCommand, selection jobs.Selection) error {
+	err := purgeSelection(db, selection)
+	if err != nil {
+		return err
+	}
+	log.Info().Msg("Optimum builds purged.")
+
+	if cmd.PurgeCache {
+		err = models.PurgeConflictFreeCache(db)
+		if err != nil {
+			return err
+		}
+		log.Info().Msg("Conflict-free cache purged.")
+	}
+
+	return nil
+}
+
+// purgeSelection deletes the selected optimum builds, truncating the table when nothing was selected
+func purgeSelection(db *sql.DB, selection jobs.Selection) error {
+	filter := selection.Filter()
+	if filter.IsEmpty() {
+		return models.PurgeOptimumBuilds(db)
+	}
+
+	purged, err := models.PurgeOptimumBuildsByFilter(db, filter)
+	if err != nil {
+		return err
+	}
+	log.Info().Msgf("Purged %d optimum builds", purged)
+	return nil
+}
+
+// runDryRun builds the candidate tree of every selected build and reports its search space and predicted cost
+// without queueing or evaluating anything
+func runDryRun(ctx context.Context, db *sql.DB, cmd cli.EvaluatorCommand, selection jobs.Selection, workerCount int) error {
+	weaponIds, traderLevels, err := selectedBuilds(db, selection)
+	if err != nil {
+		return err
+	}
+
+	model, err := jobs.LoadCostModel(db)
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msgf("Estimating %d weapons with %d trader level combinations", len(weaponIds), len(traderLevels))
+	estimates, err := jobs.EstimateBuilds(ctx, candidate_tree.CreateDataService(db), jobs.EstimateParams{
+		WeaponIDs:    weaponIds,
+		TraderLevels: traderLevels,
+		BuildType:    selection.BuildType,
+		Profile:      selection.Profile,
+		Shard:        selection.Shard,
+		Workers:      workerCount,
+	}, model)
+	if err != nil {
+		return err
+	}
+
+	jobs.SortEstimates(estimates, cmd.SortBy)
+	return jobs.WriteEstimates(os.Stdout, estimates, cmd.Format)
+}
+
+// runSolve solves the selected gunsmith tasks, at max trader levels unless trader levels were selected
+func runSolve(ctx context.Context, db *sql.DB, cmd cli.EvaluatorCommand, selection jobs.Selection) error {
+	tasks, err := gunsmith.LoadTasks(cmd.TasksFile)
+	if err != nil {
+		return err
+	}
+	tasks, err = gunsmith.SelectTasks(tasks, cmd.TaskIDs)
+	if err != nil {
+		return err
+	}
+
+	traderLevels := selection.TraderLevels
+	if len(traderLevels) == 0 {
+		traderLevels = [][]models.TraderLevel{maxTraderLevels()}
+	}
+
+	log.Info().Msgf("Solving %d tasks with %d trader level combinations", len(tasks), len(traderLevels))
+	solutions := jobs.SolveTasks(ctx, candidate_tree.CreateDataService(db), jobs.SolveParams{
+		Tasks:        tasks,
+		TraderLevels: traderLevels,
+		Profile:      selection.Profile,
+		Timeout:      cmd.SolveTimeout,
+	})
+
+	return jobs.WriteSolutions(os.Stdout, solutions, cmd.Format)
+}
+
+// maxTraderLevels is every trader at level 4
+func maxTraderLevels() []models.TraderLevel {
+	levels := make([]models.TraderLevel, 0, len(models.TraderNames))
+	for _, name := range models.TraderNames {
+		levels = append(levels, models.TraderLevel{Name: name, Level: 4})
+	}
+	return levels
+}
+
+// runExportTree writes the selected weapon's candidate tree, as the evaluator would search it, at max trader levels
+// unless trader levels were selected
+func runExportTree(db *sql.DB, cmd cli.EvaluatorCommand, selection jobs.Selection) error {
+	if len(selection.WeaponIDs) != 1 {
+		return fmt.Errorf("%q matches %d weapons, export-tree needs exactly one", cmd.Selector.Weapons[0], len(selection.WeaponIDs))
+	}
+
+	traderLevels := maxTraderLevels()
+	if len(selection.TraderLevels) == 1 {
+		traderLevels = selection.TraderLevels[0]
+	}
+	constraints := selection.Profile.Constraints(traderLevels)
+
+	tree, err := candidate_tree.CreateWeaponCandidateTree(selection.WeaponIDs[0], selection.BuildType, constraints, candidate_tree.CreateDataService(db))
+	if err != nil {
+		return err
+	}
+
+	out := os.Stdout
+	if cmd.Output != "" {
+		out, err = os.Create(cmd.Output)
+		if err != nil {
+			return err
+		}
+		defer out.Close()
+	}
+
+	if cmd.Format == cli.FormatJSON {
+		err = tree.WriteJSON(out)
+	} else {
+		err = tree.WriteDOT(out)
+	}
+	if err != nil {
+		return err
+	}
+
+	if cmd.Output != "" {
+		log.Info().Msgf("Wrote the candidate tree of %s to %s", selection.WeaponIDs[0], cmd.Output)
+	}
+	return nil
+}
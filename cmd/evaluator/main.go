@@ -1,268 +1,279 @@
 package main
 
 import (
+	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
 	"runtime"
 	"sync"
+	"syscall"
 	"tarkov-build-optimiser/internal/candidate_tree"
 	"tarkov-build-optimiser/internal/cli"
 	"tarkov-build-optimiser/internal/db"
 	"tarkov-build-optimiser/internal/env"
 	"tarkov-build-optimiser/internal/evaluator"
+	"tarkov-build-optimiser/internal/jobs"
 	"tarkov-build-optimiser/internal/models"
+	"time"
 
 	"github.com/rs/zerolog/log"
 )
 
 func main() {
-	flags := cli.GetFlags()
+	cmd, err := cli.ParseEvaluatorArgs(os.Args[1:], os.Stderr)
+	if errors.Is(err, cli.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	// SIGINT/SIGTERM stop workers claiming new builds, see jobs.WaitForWorkers
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
 	// Set log level based on CLI flag
-	cli.SetLogLevel(flags.LogLevel)
+	cli.SetLogLevel(cmd.LogLevel)
 	environment, err := env.Get()
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to get environment variables")
 	}
-	flags.Fresh = flags.Fresh || environment.EvaluatorFresh
 
 	dbClient, err := db.CreateBuildOptimiserDBClient(environment)
 	if err != nil {
 		log.Fatal().Err(err).Msg("Failed to connect to db")
 	}
 
-	// Create cache (choose between memory or database)
+	selection, err := jobs.ResolveSelection(dbClient.Conn, cmd.Selector)
+	if err != nil {
+		log.Fatal().Err(err).Msg("Failed to resolve selection")
+	}
+
+	switch cmd.Name {
+	case cli.CommandStatus:
+		err = runStatus(dbClient.Conn, cmd, selection)
+	case cli.CommandRequeue:
+		err = runRequeue(dbClient.Conn, cmd, selection)
+	case cli.CommandPurge:
+		err = runPurge(dbClient.Conn, cmd, selection)
+	case cli.CommandSolve:
+		err = runSolve(ctx, dbClient.Conn, cmd, selection)
+	case cli.CommandExport:
+		err = runExportTree(dbClient.Conn, cmd, selection)
+	default:
+		cmd.Fresh = cmd.Fresh || environment.EvaluatorFresh
+		workerCount := runtime.NumCPU() * environment.EvaluatorPoolSizeFactor
+		if cmd.DryRun {
+			err = runDryRun(ctx, dbClient.Conn, cmd, selection, workerCount)
+		} else {
+			err = runEvaluate(ctx, dbClient.Conn, cmd, selection, workerCount)
+		}
+	}
+	if err != nil {
+		log.Fatal().Err(err).Msgf("%s failed", cmd.Name)
+	}
+}
+
+func runEvaluate(ctx context.Context, db *sql.DB, cmd cli.EvaluatorCommand, selection jobs.Selection, workerCount int) error {
+	// Create cache (choose between memory, database or tiered)
 	var cache evaluator.Cache
-	if flags.UseDatabaseCache {
-		cache = evaluator.NewDatabaseCache(dbClient.Conn)
+	var tieredCache *evaluator.TieredCache
+	var memoryCache *evaluator.MemoryCache
+	if cmd.UseTieredCache {
+		tieredCache = evaluator.NewTieredCache(evaluator.NewDatabaseCache(db), cmd.CacheSize)
+		cache = tieredCache
+		log.Info().Msgf("Using TIERED cache for conflict-free items (%d entries in memory)", cmd.CacheSize)
+	} else if cmd.UseDatabaseCache {
+		cache = evaluator.NewDatabaseCache(db)
 		log.Info().Msg("Using DATABASE cache for conflict-free items")
 	} else {
-		cache = evaluator.NewMemoryCache()
+		memoryCache = evaluator.NewMemoryCache()
+		cache = memoryCache
 		log.Info().Msg("Using MEMORY cache for conflict-free items")
-	}
 
-	log.Info().Msg("Creating evaluator status entry")
+		if cmd.CacheSnapshot != "" && !cmd.Fresh {
+			loaded, err := memoryCache.LoadSnapshot(cmd.CacheSnapshot)
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to load cache snapshot %s. Starting cold.", cmd.CacheSnapshot)
+			} else {
+				log.Info().Msgf("Loaded %d cache entries from snapshot %s", loaded, cmd.CacheSnapshot)
+			}
+		}
+	}
 
-	if flags.Fresh || flags.PurgeOptimumBuilds {
+	if cmd.Fresh {
 		log.Info().Msg("Purging optimum builds (Fresh run requested).")
-		err = models.PurgeOptimumBuilds(dbClient.Conn)
+		err := purgeSelection(db, selection)
 		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to purge optimum builds.")
-			return
+			return fmt.Errorf("failed to purge optimum builds: %w", err)
 		}
 		log.Info().Msg("Models purged.")
-	} else {
-		log.Info().Msg("Resuming evaluator. Resetting stale InProgress builds to Pending.")
-		err = models.ResetInProgressBuilds(dbClient.Conn)
+	}
+
+	// builds are keyed by profile, so --ignored-slots' profile is stored for the API to serve them by name
+	if selection.ProfileDerived {
+		err := models.UpsertConstraintProfile(db, *selection.Profile)
 		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to reset in-progress builds.")
+			return fmt.Errorf("failed to store constraint profile %s: %w", selection.Profile.Name, err)
 		}
+		log.Info().Msgf("Evaluating with constraint profile %s", selection.Profile.Name)
 	}
 
-	traderLevels := evaluator.GenerateTraderLevelVariations(models.TraderNames)
-	traderLevels = evaluator.SortTraderLevelsByAvg(traderLevels)
+	weaponIds, traderLevels, err := selectedBuilds(db, selection)
+	if err != nil {
+		return err
+	}
 
-	var weaponIds []string
-	if flags.TestRun {
-		log.Info().Msg("Using test weapon IDs")
-		weaponIds = []string{
-			"5447a9cd4bdc2dbd208b4567", // Colt M4A1 5.56x45 assault rifle
-			"6895bb82c4519957df062f82", // Radian Weapons Model 1 FA 5.56x45 assault rifle
-		}
-		traderLevels = [][]models.TraderLevel{
-			{
-				{Name: "Jaeger", Level: 1},
-				{Name: "Prapor", Level: 1},
-				{Name: "Skier", Level: 1},
-				{Name: "Peacekeeper", Level: 1},
-				{Name: "Mechanic", Level: 1},
-			},
-			{
-				{Name: "Jaeger", Level: 2},
-				{Name: "Prapor", Level: 2},
-				{Name: "Skier", Level: 2},
-				{Name: "Peacekeeper", Level: 2},
-				{Name: "Mechanic", Level: 2},
-			},
-			{
-				{Name: "Jaeger", Level: 3},
-				{Name: "Prapor", Level: 3},
-				{Name: "Skier", Level: 3},
-				{Name: "Peacekeeper", Level: 3},
-				{Name: "Mechanic", Level: 3},
-			},
-			{
-				{Name: "Jaeger", Level: 4},
-				{Name: "Prapor", Level: 4},
-				{Name: "Skier", Level: 4},
-				{Name: "Peacekeeper", Level: 4},
-				{Name: "Mechanic", Level: 4},
-			},
-		}
-	} else {
-		log.Info().Msg("Fetching weapon IDs")
-		weaponIds, err = models.GetAllWeaponIds(dbClient.Conn)
+	log.Info().Msgf("Evaluating %d weapons with %d trader level combinations", len(weaponIds), len(traderLevels))
+	if selection.Shard.IsSet() {
+		log.Info().Msgf("Only evaluating shard %s", selection.Shard)
+	}
+
+	if cmd.TraceDir != "" {
+		err := os.MkdirAll(cmd.TraceDir, 0o755)
 		if err != nil {
-			log.Fatal().Err(err).Msg("Failed to get all weapon IDs")
+			return fmt.Errorf("failed to create trace directory: %w", err)
 		}
+		log.Info().Msgf("Tracing every build's search to %s", cmd.TraceDir)
 	}
 
-	workerCount := runtime.NumCPU() * environment.EvaluatorPoolSizeFactor
+	dataService := candidate_tree.CreateDataService(db)
+	retry := models.RetryPolicy{
+		MaxAttempts: cmd.MaxAttempts,
+		Backoff:     cmd.RetryBackoff,
+		MaxBackoff:  jobs.DefaultMaxRetryBackoff,
+	}
+	// without --build-type or --profile builds of every type or profile are claimed, as API requests can be for any
+	claimFilter := selection.ClaimFilter(cmd.ClaimAnyBuildType, cmd.ClaimAnyProfile)
+	finished := evaluate(ctx, weaponIds, dataService, workerCount, traderLevels, selection, claimFilter, db, cache, retry, cmd.ShutdownTimeout, cmd.Watch, cmd.TraceDir)
+
+	if tieredCache != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+		err := tieredCache.Close(ctx)
+		cancel()
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to flush tiered cache")
+		}
+		stats := tieredCache.Stats()
+		log.Info().Msgf("Tiered cache: %d hits, %d backing hits, %d misses, %d evictions, %d writes",
+			stats.Hits, stats.BackingHits, stats.Misses, stats.Evictions, stats.Writes)
+	}
 
-	log.Info().Msgf("Evaluating %d weapons", len(weaponIds))
+	if memoryCache != nil && cmd.CacheSnapshot != "" {
+		saved, err := memoryCache.SaveSnapshot(cmd.CacheSnapshot)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to save cache snapshot %s", cmd.CacheSnapshot)
+		} else {
+			log.Info().Msgf("Saved %d cache entries to snapshot %s", saved, cmd.CacheSnapshot)
+		}
+	}
 
-	dataService := candidate_tree.CreateDataService(dbClient.Conn)
-	evaluate(weaponIds, dataService, workerCount, traderLevels, dbClient.Conn, cache)
+	if !finished {
+		log.Warn().Msg("Evaluator stopped before all builds finished.")
+		return nil
+	}
 
 	log.Info().Msg("Evaluator done.")
+	return nil
 }
 
-type EvaluationResult struct {
-	Result         *evaluator.Build
-	Weapon         *candidate_tree.CandidateTree
-	EvaluationType string
-	BuildID        int
-}
+// selectedBuilds returns the weapons and trader levels to evaluate, defaulting to every weapon and every
+// combination of trader levels when the selection doesn't narrow them
+func selectedBuilds(db *sql.DB, selection jobs.Selection) ([]string, [][]models.TraderLevel, error) {
+	traderLevels := selection.TraderLevels
+	if len(traderLevels) == 0 {
+		traderLevels = evaluator.GenerateTraderLevelVariations(models.TraderNames)
+		traderLevels = evaluator.SortTraderLevelsByAvg(traderLevels)
+	}
+
+	weaponIds := selection.WeaponIDs
+	if len(weaponIds) == 0 {
+		log.Info().Msg("Fetching weapon IDs")
+		var err error
+		weaponIds, err = models.GetAllWeaponIds(db)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get all weapon IDs: %w", err)
+		}
+	}
 
-type Candidateinput struct {
-	weaponID    string
-	constraints models.EvaluationConstraints
-	BuildID     int
+	return weaponIds, traderLevels, nil
 }
 
-func evaluate(weaponIds []string, dataProvider candidate_tree.TreeDataProvider, workerCount int, traderLevels [][]models.TraderLevel, db *sql.DB, cache evaluator.Cache) {
-	inputChan := make(chan Candidateinput, workerCount*2)
-	resultsChan := make(chan EvaluationResult, workerCount*2)
-	wg := sync.WaitGroup{}
+// evaluate runs workers until the queue is drained (never, when watching) or ctx is cancelled, returning false if
+// in-flight builds had to be abandoned when shutting down.
+func evaluate(ctx context.Context, weaponIds []string, dataProvider candidate_tree.TreeDataProvider, workerCount int, traderLevels [][]models.TraderLevel, selection jobs.Selection, claimFilter models.BuildFilter, db *sql.DB, cache evaluator.Cache, retry models.RetryPolicy, shutdownTimeout time.Duration, watch bool, traceDir string) bool {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	resultsChan := make(chan jobs.EvaluationResult, workerCount*2)
 
 	// Start a goroutine to continuously flush results (prevents memory accumulation)
-	// This allows results to be processed for side effects without keeping them in memory
+	// Only the search statistics are kept, the build itself has already been saved by the worker
 	resultsWg := sync.WaitGroup{}
 	resultsWg.Add(1)
 	go func() {
 		defer resultsWg.Done()
 		for result := range resultsChan {
-			// Results are flushed immediately after being received
-			// Add any side effects processing here in the future
-			_ = result // Currently just discarded to prevent accumulation
+			err := models.InsertBuildStats(db, result.ToBuildStats())
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to save stats for build %d", result.BuildID)
+			}
+		}
+	}()
+
+	// Builds left InProgress by crashed evaluators are requeued once their heartbeat goes stale
+	go jobs.RunReaper(ctx, db, jobs.DefaultReapInterval, jobs.DefaultStaleAfter)
+
+	// Workers start claiming while the queue is still being seeded
+	seeded := make(chan struct{})
+	go func() {
+		defer close(seeded)
+		err := jobs.Seed(ctx, db, weaponIds, traderLevels, selection)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to seed build queue")
 		}
 	}()
 
+	wg := sync.WaitGroup{}
+	workers := make([]*jobs.Worker, 0, workerCount)
 	for i := 0; i < workerCount; i++ {
 		wg.Add(1)
 
+		worker := &jobs.Worker{
+			ID:           jobs.NewWorkerID(i),
+			DB:           db,
+			DataProvider: dataProvider,
+			Cache:        cache,
+			Results:      resultsChan,
+			Retry:        retry,
+			TraceDir:     traceDir,
+			// only claim the selected builds, other evaluators may be working through the rest
+			Filter: claimFilter,
+		}
+		workers = append(workers, worker)
 		go func() {
 			defer wg.Done()
-
-			for input := range inputChan {
-				log.Info().Msgf("Processing input for weapon %s", input.weaponID)
-
-				err := models.SetBuildInProgress(db, input.BuildID)
-				if err != nil {
-					log.Error().Err(err).Msgf("Failed to update evaluator status to inprogress for weapon %s", input.weaponID)
-					err2 := models.SetBuildFailed(db, input.BuildID)
-					if err2 != nil {
-						log.Error().Err(err2).Msgf("Failed to set build failed for build %d", input.BuildID)
-					}
-					continue
-				}
-
-				weapon, err := candidate_tree.CreateWeaponCandidateTree(input.weaponID, "recoil", input.constraints, dataProvider)
-				if err != nil {
-					log.Error().Err(err).Msgf("Failed to create weapon tree for %s. Skipping", input.weaponID)
-					err2 := models.SetBuildFailed(db, input.BuildID)
-					if err2 != nil {
-						log.Error().Err(err2).Msgf("Failed to set build failed for build %d", input.BuildID)
-					}
-					continue
-				}
-
-				weapon.SortAllowedItems("recoil-min")
-
-				log.Info().Msgf("Generated weapon candidate tree for %s with constraints %v", input.weaponID, input.constraints)
-				build := evaluator.FindBestBuild(weapon, "recoil", map[string]bool{}, cache)
-
-				log.Info().Msgf("Evaluation complete - weapon %s with constraints %v", input.weaponID, input.constraints)
-
-				evaledWeapon, err := build.ToEvaluatedWeapon()
-				if err != nil {
-					log.Error().Err(err).Msgf("Failed to convert result to evaluated weapon for weapon %s with constraints %v", input.weaponID, input.constraints)
-					err2 := models.SetBuildFailed(db, input.BuildID)
-					if err2 != nil {
-						log.Error().Err(err2).Msgf("Failed to set build failed for build %d", input.BuildID)
-					}
-					continue
-				}
-
-				evaluationResult := evaledWeapon.ToItemEvaluationResult()
-
-				err = models.SetBuildCompleted(db, input.BuildID, &evaluationResult)
-				if err != nil {
-					log.Error().Err(err).Msgf("Failed to save build for weapon %s with constraints %v", input.weaponID, input.constraints)
-					err2 := models.SetBuildFailed(db, input.BuildID)
-					if err2 != nil {
-						log.Error().Err(err2).Msgf("Failed to set build failed for build %d", input.BuildID)
-					}
-					continue
-				}
-
-				log.Info().Msgf("Saved build for weapon %s with constraints %v", input.weaponID, input.constraints)
-
-				resultsChan <- EvaluationResult{
-					BuildID:        input.BuildID,
-					EvaluationType: "recoil",
-					Weapon:         weapon,
-					Result:         build,
-				}
-			}
+			worker.Run(ctx, seeded)
 		}()
 	}
 
-	// Send work to the input channel
-	for i := 0; i < len(weaponIds); i++ {
-		for j := 0; j < len(traderLevels); j++ {
-			log.Debug().Msgf("Sending work for weapon %s with constraints %v", weaponIds[i], traderLevels[j])
-
-			constraints := models.EvaluationConstraints{
-				TraderLevels:     traderLevels[j],
-				IgnoredSlotNames: []string{"Scope", "Ubgl", "Tactical"},
-				IgnoredItemIDs:   []string{},
-			}
-
-			// Check if build already exists and is completed
-			existingBuild, err := models.GetOptimumBuildByConstraints(db, weaponIds[i], "recoil", constraints)
-			if err != nil {
-				log.Error().Err(err).Msgf("Failed to check existing build for weapon %s", weaponIds[i])
-				continue
-			}
-
-			var buildID int
-			if existingBuild != nil {
-				if existingBuild.Status == models.EvaluationCompleted.ToString() {
-					log.Debug().Msgf("Skipping completed build for weapon %s with constraints %v", weaponIds[i], traderLevels[j])
-					continue
-				}
-				buildID = existingBuild.BuildID
-				log.Debug().Msgf("Resuming build %d for weapon %s with constraints %v (status: %s)", buildID, weaponIds[i], traderLevels[j], existingBuild.Status)
-			} else {
-				log.Debug().Msgf("Creating new pending build for weapon %s with constraints %v", weaponIds[i], traderLevels[j])
-				buildID, err = models.CreatePendingOptimumBuild(db, weaponIds[i], "recoil", constraints)
-				if err != nil {
-					log.Error().Err(err).Msgf("Failed to create evaluator status for weapon %s", weaponIds[i])
-					return
-				}
-			}
+	workersDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(workersDone)
+	}()
 
-			log.Debug().Msgf("Sending to input %s, %v", weaponIds[i], constraints)
-			inputChan <- Candidateinput{
-				weaponID:    weaponIds[i],
-				constraints: constraints,
-				BuildID:     buildID,
-			}
-		}
+	if !jobs.WaitForWorkers(ctx, db, workers, workersDone, shutdownTimeout) {
+		// abandoned workers may still send results, so resultsChan is left open
+		return false
 	}
 
-	close(inputChan)
-	wg.Wait()
 	close(resultsChan)
 	resultsWg.Wait()
+
+	return ctx.Err() == nil
 }
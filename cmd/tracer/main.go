@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"tarkov-build-optimiser/internal/cli"
+	"tarkov-build-optimiser/internal/tracer"
+)
+
+func main() {
+	cmd, err := cli.ParseTracerArgs(os.Args[1:], os.Stderr)
+	if errors.Is(err, cli.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	err = run(cmd)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s failed: %v\n", cmd.Name, err)
+		os.Exit(1)
+	}
+}
+
+func run(cmd cli.TracerCommand) error {
+	file, err := os.Open(cmd.File)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if cmd.Name == cli.CommandSummary {
+		events, err := tracer.ReadAll(file)
+		if err != nil {
+			return err
+		}
+		return tracer.WriteSummary(os.Stdout, tracer.Summarise(events), cmd.Limit)
+	}
+
+	reader, err := tracer.NewReader(file)
+	if err != nil {
+		return err
+	}
+	_, err = tracer.WriteReplay(os.Stdout, reader, cmd.SlotID, cmd.ItemID)
+	return err
+}
@@ -0,0 +1,334 @@
+package analysis
+
+import (
+	"database/sql"
+	"slices"
+	"tarkov-build-optimiser/internal/builds"
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/models"
+)
+
+// maxTraderLevel is the highest level a trader can be upgraded to
+const maxTraderLevel = 4
+
+// StoredBuilds reads the precomputed optimum builds
+type StoredBuilds interface {
+	// GetCompletedBuild returns nil if the build hasn't been evaluated yet
+	GetCompletedBuild(itemID string, buildType string, constraints models.EvaluationConstraints) (*models.ItemEvaluationResult, error)
+}
+
+type dbStoredBuilds struct {
+	db *sql.DB
+}
+
+func NewStoredBuilds(db *sql.DB) StoredBuilds {
+	return &dbStoredBuilds{db: db}
+}
+
+func (s *dbStoredBuilds) GetCompletedBuild(itemID string, buildType string, constraints models.EvaluationConstraints) (*models.ItemEvaluationResult, error) {
+	build, err := models.GetOptimumBuildByConstraints(s.db, itemID, buildType, constraints)
+	if err != nil {
+		return nil, err
+	}
+	if build == nil || build.Status != models.EvaluationCompleted.ToString() {
+		return nil, nil
+	}
+
+	return build, nil
+}
+
+// UnlockedItem is a mod which fits the weapon and can be bought once a trader is upgraded, but not before
+type UnlockedItem struct {
+	ID       string `json:"item_id"`
+	Name     string `json:"name"`
+	PriceRub int    `json:"price_rub"`
+	// InBuild is true if the upgraded build uses the item
+	InBuild bool `json:"in_build"`
+}
+
+// TraderUpgrade is the effect of levelling up one trader on a weapon's optimum build
+type TraderUpgrade struct {
+	Trader    string `json:"trader"`
+	FromLevel int    `json:"from_level"`
+	ToLevel   int    `json:"to_level"`
+	// Evaluated is false when the build before or after the upgrade hasn't been precomputed, Improvement is then zero
+	Evaluated     bool             `json:"evaluated"`
+	Improvement   builds.StatDelta `json:"improvement"`
+	UnlockedItems []UnlockedItem   `json:"unlocked_items"`
+}
+
+// WeaponUpgrades are a weapon's possible trader upgrades, best first
+type WeaponUpgrades struct {
+	WeaponID     string               `json:"weapon_id"`
+	WeaponName   string               `json:"weapon_name"`
+	BuildType    string               `json:"build_type"`
+	TraderLevels []models.TraderLevel `json:"trader_levels"`
+	Upgrades     []TraderUpgrade      `json:"upgrades"`
+}
+
+// buildStats are the weapon level stat sums of a build
+func buildStats(build *models.ItemEvaluationResult) builds.StatDelta {
+	return builds.StatDelta{
+		Recoil:     build.RecoilSum,
+		Ergonomics: build.ErgonomicsSum,
+		Weight:     build.WeightSum,
+		Accuracy:   build.AccuracySum,
+	}
+}
+
+// buildItemIDs adds the IDs of every item fitted to a build to ids
+func buildItemIDs(item *models.ItemEvaluationResult, ids map[string]bool) {
+	for i := range item.Slots {
+		if item.Slots[i].IsEmpty {
+			continue
+		}
+		ids[item.Slots[i].Item.ID] = true
+		buildItemIDs(&item.Slots[i].Item, ids)
+	}
+}
+
+// modOffers is a mod which fits the weapon with its trader offers
+type modOffers struct {
+	mod    *models.WeaponMod
+	offers []models.TraderOffer
+}
+
+// weaponModOffers returns every mod which can be fitted to the weapon, directly or to another mod, that the
+// constraints don't rule out
+func weaponModOffers(data candidate_tree.TreeDataProvider, weapon *models.Weapon, constraints models.EvaluationConstraints) ([]modOffers, error) {
+	mods := make([]modOffers, 0)
+	visited := make(map[string]bool)
+	slots := slices.Clone(weapon.Slots)
+	for len(slots) > 0 {
+		slot := slots[0]
+		slots = slots[1:]
+
+		allowedItems, err := data.GetAllowedItemsBySlotID(slot.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, allowedItem := range allowedItems {
+			if visited[allowedItem.ID] {
+				continue
+			}
+			visited[allowedItem.ID] = true
+
+			// as when populating candidate trees, an item which can't be loaded isn't a weapon mod
+			mod, err := data.GetWeaponModById(allowedItem.ID)
+			if err != nil || mod == nil {
+				continue
+			}
+			if slices.Contains(constraints.IgnoredItemIDs, mod.ID) || constraints.ExcludesCategory(mod.CategoryID, mod.CategoryName) {
+				continue
+			}
+
+			offers, err := data.GetTraderOffer(mod.ID)
+			if err != nil {
+				return nil, err
+			}
+			mods = append(mods, modOffers{mod: mod, offers: offers})
+
+			modSlots, err := data.GetSlotsByItemID(mod.ID)
+			if err != nil {
+				return nil, err
+			}
+			slots = append(slots, modSlots...)
+		}
+	}
+
+	return mods, nil
+}
+
+// upgradedLevels returns the trader levels with one trader a level higher
+func upgradedLevels(traderLevels []models.TraderLevel, trader string) []models.TraderLevel {
+	upgraded := slices.Clone(traderLevels)
+	for i := range upgraded {
+		if upgraded[i].Name == trader {
+			upgraded[i].Level++
+		}
+	}
+	return upgraded
+}
+
+// RankTraderUpgrades compares the weapon's precomputed build at the constraints' trader levels with the build after
+// each possible single trader level up, ranked by how much they improve the build type's stat. Upgrades which
+// haven't been precomputed are ranked last. The constraints' trader levels should have a level for every trader, see
+// models.CompleteTraderLevels.
+func RankTraderUpgrades(stored StoredBuilds, data candidate_tree.TreeDataProvider, weaponID string, buildType string, constraints models.EvaluationConstraints) (*WeaponUpgrades, error) {
+	weapon, err := data.GetWeaponById(weaponID)
+	if err != nil {
+		return nil, err
+	}
+
+	mods, err := weaponModOffers(data, weapon, constraints)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := stored.GetCompletedBuild(weaponID, buildType, constraints)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &WeaponUpgrades{
+		WeaponID:     weapon.ID,
+		WeaponName:   weapon.Name,
+		BuildType:    buildType,
+		TraderLevels: constraints.TraderLevels,
+		Upgrades:     make([]TraderUpgrade, 0),
+	}
+
+	for _, traderLevel := range constraints.TraderLevels {
+		if traderLevel.Level >= maxTraderLevel {
+			continue
+		}
+
+		upgradedConstraints := constraints
+		upgradedConstraints.TraderLevels = upgradedLevels(constraints.TraderLevels, traderLevel.Name)
+
+		upgrade := TraderUpgrade{
+			Trader:        traderLevel.Name,
+			FromLevel:     traderLevel.Level,
+			ToLevel:       traderLevel.Level + 1,
+			UnlockedItems: make([]UnlockedItem, 0),
+		}
+
+		upgraded, err := stored.GetCompletedBuild(weaponID, buildType, upgradedConstraints)
+		if err != nil {
+			return nil, err
+		}
+		upgradedItemIDs := make(map[string]bool)
+		if current != nil && upgraded != nil {
+			upgrade.Evaluated = true
+			upgrade.Improvement = buildStats(upgraded).Sub(buildStats(current))
+			buildItemIDs(upgraded, upgradedItemIDs)
+		}
+
+		for _, m := range mods {
+			if _, available := candidate_tree.CheapestOffer(m.offers, constraints.TraderLevels); available {
+				continue
+			}
+			price, available := candidate_tree.CheapestOffer(m.offers, upgradedConstraints.TraderLevels)
+			if !available {
+				continue
+			}
+			upgrade.UnlockedItems = append(upgrade.UnlockedItems, UnlockedItem{
+				ID:       m.mod.ID,
+				Name:     m.mod.Name,
+				PriceRub: price,
+				InBuild:  upgradedItemIDs[m.mod.ID],
+			})
+		}
+
+		result.Upgrades = append(result.Upgrades, upgrade)
+	}
+
+	rank(result.Upgrades, func(u TraderUpgrade) (bool, float64) {
+		return u.Evaluated, u.Improvement.Score(buildType)
+	})
+
+	return result, nil
+}
+
+// WeaponImprovement is one weapon's part in an upgrade across several weapons
+type WeaponImprovement struct {
+	WeaponID      string           `json:"weapon_id"`
+	WeaponName    string           `json:"weapon_name"`
+	Evaluated     bool             `json:"evaluated"`
+	Improvement   builds.StatDelta `json:"improvement"`
+	UnlockedItems []UnlockedItem   `json:"unlocked_items"`
+}
+
+// AggregatedUpgrade is the effect of levelling up one trader on several weapons' optimum builds
+type AggregatedUpgrade struct {
+	Trader    string `json:"trader"`
+	FromLevel int    `json:"from_level"`
+	ToLevel   int    `json:"to_level"`
+	// Improvement is the sum of the evaluated weapons' improvements
+	Improvement      builds.StatDelta    `json:"improvement"`
+	EvaluatedWeapons int                 `json:"evaluated_weapons"`
+	Weapons          []WeaponImprovement `json:"weapons"`
+}
+
+// AggregatedUpgrades are the possible trader upgrades across several weapons, best first
+type AggregatedUpgrades struct {
+	BuildType    string               `json:"build_type"`
+	TraderLevels []models.TraderLevel `json:"trader_levels"`
+	Upgrades     []AggregatedUpgrade  `json:"upgrades"`
+}
+
+// RankTraderUpgradesForWeapons ranks the single trader level ups by their summed improvement to each weapon's build,
+// e.g. across a user's favourite weapons. See RankTraderUpgrades.
+func RankTraderUpgradesForWeapons(stored StoredBuilds, data candidate_tree.TreeDataProvider, weaponIDs []string, buildType string, constraints models.EvaluationConstraints) (*AggregatedUpgrades, error) {
+	result := &AggregatedUpgrades{
+		BuildType:    buildType,
+		TraderLevels: constraints.TraderLevels,
+		Upgrades:     make([]AggregatedUpgrade, 0),
+	}
+
+	// every weapon has the same upgrades, in trader order
+	byTrader := make(map[string]*AggregatedUpgrade)
+	for _, traderLevel := range constraints.TraderLevels {
+		if traderLevel.Level >= maxTraderLevel {
+			continue
+		}
+		result.Upgrades = append(result.Upgrades, AggregatedUpgrade{
+			Trader:    traderLevel.Name,
+			FromLevel: traderLevel.Level,
+			ToLevel:   traderLevel.Level + 1,
+			Weapons:   make([]WeaponImprovement, 0, len(weaponIDs)),
+		})
+	}
+	for i := range result.Upgrades {
+		byTrader[result.Upgrades[i].Trader] = &result.Upgrades[i]
+	}
+
+	for _, weaponID := range weaponIDs {
+		weaponUpgrades, err := RankTraderUpgrades(stored, data, weaponID, buildType, constraints)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, upgrade := range weaponUpgrades.Upgrades {
+			aggregated := byTrader[upgrade.Trader]
+			aggregated.Weapons = append(aggregated.Weapons, WeaponImprovement{
+				WeaponID:      weaponUpgrades.WeaponID,
+				WeaponName:    weaponUpgrades.WeaponName,
+				Evaluated:     upgrade.Evaluated,
+				Improvement:   upgrade.Improvement,
+				UnlockedItems: upgrade.UnlockedItems,
+			})
+			if upgrade.Evaluated {
+				aggregated.Improvement = aggregated.Improvement.Add(upgrade.Improvement)
+				aggregated.EvaluatedWeapons++
+			}
+		}
+	}
+
+	rank(result.Upgrades, func(u AggregatedUpgrade) (bool, float64) {
+		return u.EvaluatedWeapons > 0, u.Improvement.Score(buildType)
+	})
+
+	return result, nil
+}
+
+// rank sorts upgrades by whether they were evaluated and then by score, highest first, keeping trader order for ties
+func rank[T any](upgrades []T, key func(T) (bool, float64)) {
+	slices.SortStableFunc(upgrades, func(a, b T) int {
+		evaluatedA, scoreA := key(a)
+		evaluatedB, scoreB := key(b)
+		if evaluatedA != evaluatedB {
+			if evaluatedA {
+				return -1
+			}
+			return 1
+		}
+		if scoreA > scoreB {
+			return -1
+		}
+		if scoreA < scoreB {
+			return 1
+		}
+		return 0
+	})
+}
@@ -0,0 +1,187 @@
+package analysis
+
+import (
+	"fmt"
+	"tarkov-build-optimiser/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testBuilds are stored builds keyed by weapon and Prapor and Mechanic levels, every other trader is at level 4
+type testBuilds map[string]*models.ItemEvaluationResult
+
+func buildKey(weaponID string, prapor int, mechanic int) string {
+	return fmt.Sprintf("%s/%d/%d", weaponID, prapor, mechanic)
+}
+
+func (b testBuilds) GetCompletedBuild(itemID string, _ string, constraints models.EvaluationConstraints) (*models.ItemEvaluationResult, error) {
+	levels := make(map[string]int)
+	for _, level := range constraints.TraderLevels {
+		levels[level.Name] = level.Level
+	}
+	return b[buildKey(itemID, levels["Prapor"], levels["Mechanic"])], nil
+}
+
+// testData are weapons with a stock slot and a muzzle slot. Prapor sells the cheap stock at level 1 and the good
+// stock at level 2, Mechanic sells the brake at level 3.
+type testData struct {
+	weapons map[string]*models.Weapon
+}
+
+func newTestData() *testData {
+	slots := []models.Slot{{ID: "slot-stock", Name: "Stock"}, {ID: "slot-muzzle", Name: "Muzzle"}}
+	return &testData{weapons: map[string]*models.Weapon{
+		"weapon":   {ID: "weapon", Name: "weapon", Slots: slots},
+		"weapon-2": {ID: "weapon-2", Name: "weapon 2", Slots: slots},
+	}}
+}
+
+var testMods = map[string]*models.WeaponMod{
+	"cheap-stock": {ID: "cheap-stock", Name: "cheap stock"},
+	"good-stock":  {ID: "good-stock", Name: "good stock"},
+	"brake":       {ID: "brake", Name: "brake"},
+}
+
+func (d *testData) GetWeaponById(id string) (*models.Weapon, error) {
+	weapon, ok := d.weapons[id]
+	if !ok {
+		return nil, fmt.Errorf("No results for %s", id)
+	}
+	return weapon, nil
+}
+
+func (d *testData) GetSlotsByItemID(string) ([]models.Slot, error) {
+	return []models.Slot{}, nil
+}
+
+func (d *testData) GetWeaponModById(id string) (*models.WeaponMod, error) {
+	mod, ok := testMods[id]
+	if !ok {
+		return nil, fmt.Errorf("weapon mod with id %s not found", id)
+	}
+	return mod, nil
+}
+
+func (d *testData) GetAllowedItemsBySlotID(id string) ([]*models.AllowedItem, error) {
+	switch id {
+	case "slot-stock":
+		return []*models.AllowedItem{{ID: "cheap-stock"}, {ID: "good-stock"}}, nil
+	case "slot-muzzle":
+		return []*models.AllowedItem{{ID: "brake"}}, nil
+	}
+	return []*models.AllowedItem{}, nil
+}
+
+func (d *testData) GetTraderOffer(id string) ([]models.TraderOffer, error) {
+	switch id {
+	case "cheap-stock":
+		return []models.TraderOffer{{Trader: "Prapor", MinTraderLevel: 1, PriceRub: 1000}}, nil
+	case "good-stock":
+		return []models.TraderOffer{{Trader: "Prapor", MinTraderLevel: 2, PriceRub: 5000}}, nil
+	case "brake":
+		return []models.TraderOffer{{Trader: "Mechanic", MinTraderLevel: 3, PriceRub: 8000}}, nil
+	}
+	return []models.TraderOffer{}, nil
+}
+
+func (d *testData) IsWeapon(id string) (bool, error) {
+	_, ok := d.weapons[id]
+	return ok, nil
+}
+
+func stockBuild(stockID string, recoil float64, muzzleID string) *models.ItemEvaluationResult {
+	build := &models.ItemEvaluationResult{
+		Slots: []models.SlotEvaluationResult{
+			{ID: "slot-stock", Item: models.ItemEvaluationResult{ID: stockID}},
+			{ID: "slot-muzzle", IsEmpty: true},
+		},
+		RecoilSum: models.NewStat(recoil),
+	}
+	if muzzleID != "" {
+		build.Slots[1] = models.SlotEvaluationResult{ID: "slot-muzzle", Item: models.ItemEvaluationResult{ID: muzzleID}}
+	}
+	return build
+}
+
+func testConstraints(prapor int, mechanic int) models.EvaluationConstraints {
+	levels, err := models.CompleteTraderLevels([]models.TraderLevel{{Name: "Prapor", Level: prapor}, {Name: "Mechanic", Level: mechanic}})
+	if err != nil {
+		panic(err)
+	}
+	return models.EvaluationConstraints{TraderLevels: levels}
+}
+
+func TestRankTraderUpgrades(t *testing.T) {
+	stored := testBuilds{
+		buildKey("weapon", 1, 2): stockBuild("cheap-stock", -5, ""),
+		buildKey("weapon", 2, 2): stockBuild("good-stock", -9, ""),
+		buildKey("weapon", 1, 3): stockBuild("cheap-stock", -15, "brake"),
+	}
+
+	upgrades, err := RankTraderUpgrades(stored, newTestData(), "weapon", "recoil", testConstraints(1, 2))
+	require.NoError(t, err)
+
+	require.Len(t, upgrades.Upgrades, 2)
+	mechanic := upgrades.Upgrades[0]
+	assert.Equal(t, "Mechanic", mechanic.Trader)
+	assert.Equal(t, 3, mechanic.ToLevel)
+	assert.True(t, mechanic.Evaluated)
+	assert.Equal(t, models.NewStat(-10), mechanic.Improvement.Recoil)
+	assert.Equal(t, []UnlockedItem{{ID: "brake", Name: "brake", PriceRub: 8000, InBuild: true}}, mechanic.UnlockedItems)
+
+	prapor := upgrades.Upgrades[1]
+	assert.Equal(t, "Prapor", prapor.Trader)
+	assert.Equal(t, models.NewStat(-4), prapor.Improvement.Recoil)
+	assert.Equal(t, []UnlockedItem{{ID: "good-stock", Name: "good stock", PriceRub: 5000, InBuild: true}}, prapor.UnlockedItems)
+}
+
+func TestRankTraderUpgrades_NotPrecomputedRankedLast(t *testing.T) {
+	stored := testBuilds{
+		buildKey("weapon", 1, 2): stockBuild("cheap-stock", -5, ""),
+		buildKey("weapon", 2, 2): stockBuild("cheap-stock", -5, ""),
+	}
+
+	upgrades, err := RankTraderUpgrades(stored, newTestData(), "weapon", "recoil", testConstraints(1, 2))
+	require.NoError(t, err)
+
+	require.Len(t, upgrades.Upgrades, 2)
+	assert.Equal(t, "Prapor", upgrades.Upgrades[0].Trader)
+	assert.True(t, upgrades.Upgrades[0].Evaluated)
+	// the good stock is unlocked but not worth using
+	assert.False(t, upgrades.Upgrades[0].UnlockedItems[0].InBuild)
+	assert.Equal(t, "Mechanic", upgrades.Upgrades[1].Trader)
+	assert.False(t, upgrades.Upgrades[1].Evaluated)
+	assert.Len(t, upgrades.Upgrades[1].UnlockedItems, 1)
+}
+
+func TestRankTraderUpgrades_MaxLevelTradersLeftOut(t *testing.T) {
+	upgrades, err := RankTraderUpgrades(testBuilds{}, newTestData(), "weapon", "recoil", testConstraints(4, 4))
+	require.NoError(t, err)
+
+	assert.Empty(t, upgrades.Upgrades)
+}
+
+func TestRankTraderUpgradesForWeapons(t *testing.T) {
+	stored := testBuilds{
+		buildKey("weapon", 1, 2):   stockBuild("cheap-stock", -5, ""),
+		buildKey("weapon", 2, 2):   stockBuild("good-stock", -9, ""),
+		buildKey("weapon", 1, 3):   stockBuild("cheap-stock", -7, "brake"),
+		buildKey("weapon-2", 1, 2): stockBuild("cheap-stock", -5, ""),
+		buildKey("weapon-2", 2, 2): stockBuild("good-stock", -9, ""),
+		buildKey("weapon-2", 1, 3): stockBuild("cheap-stock", -10, "brake"),
+	}
+
+	upgrades, err := RankTraderUpgradesForWeapons(stored, newTestData(), []string{"weapon", "weapon-2"}, "recoil", testConstraints(1, 2))
+	require.NoError(t, err)
+
+	// Mechanic is best for weapon-2 alone, but Prapor is better across both
+	require.Len(t, upgrades.Upgrades, 2)
+	assert.Equal(t, "Prapor", upgrades.Upgrades[0].Trader)
+	assert.Equal(t, models.NewStat(-8), upgrades.Upgrades[0].Improvement.Recoil)
+	assert.Equal(t, 2, upgrades.Upgrades[0].EvaluatedWeapons)
+	assert.Len(t, upgrades.Upgrades[0].Weapons, 2)
+	assert.Equal(t, "Mechanic", upgrades.Upgrades[1].Trader)
+	assert.Equal(t, models.NewStat(-7), upgrades.Upgrades[1].Improvement.Recoil)
+}
@@ -0,0 +1,187 @@
+package builds
+
+import (
+	"slices"
+	"tarkov-build-optimiser/internal/models"
+	"tarkov-build-optimiser/internal/stats"
+)
+
+// StatDelta is the change in a build's stats, negative recoil and weight and positive ergonomics and accuracy are
+// improvements
+type StatDelta struct {
+	Recoil     models.Stat `json:"recoil"`
+	Ergonomics models.Stat `json:"ergonomics"`
+	Weight     float64     `json:"weight"`
+	Accuracy   float64     `json:"accuracy"`
+}
+
+type ItemRef struct {
+	ID   string `json:"item_id"`
+	Name string `json:"name"`
+}
+
+// Swap is a slot whose item differs from the optimal build's, swapping it replaces everything fitted to it too
+type Swap struct {
+	// Path is the IDs of the slots from the weapon down to and including the slot
+	Path     []string `json:"path"`
+	SlotID   string   `json:"slot_id"`
+	SlotName string   `json:"slot_name"`
+	// Current and Optimal are nil when the slot is empty
+	Current *ItemRef `json:"current"`
+	Optimal *ItemRef `json:"optimal"`
+	// Delta is the change in the build's stats from this swap alone
+	Delta StatDelta `json:"delta"`
+}
+
+// Comparison is the gap between a build and the optimal build
+type Comparison struct {
+	BuildType string `json:"build_type"`
+	// Swaps are ordered by how much they improve the build type's stat, most first
+	Swaps []Swap `json:"swaps"`
+	// Improvement is the change in stats from making every swap
+	Improvement  StatDelta   `json:"improvement"`
+	CurrentStats stats.Final `json:"current_stats"`
+	// ImprovedStats are the in-game stats with every swap made, items in slots the optimiser didn't consider included
+	ImprovedStats stats.Final `json:"improved_stats"`
+}
+
+// subtreeStats sums the stats of an item and everything fitted to it. The sums stored on an ItemEvaluationResult's
+// items aren't used, builds from the evaluator only set them on the weapon.
+func subtreeStats(item *models.ItemEvaluationResult) StatDelta {
+	total := StatDelta{
+		Recoil:     item.RecoilModifier,
+		Ergonomics: item.ErgonomicsModifier,
+		Weight:     item.Weight,
+		Accuracy:   item.AccuracyModifier,
+	}
+	for i := range item.Slots {
+		if item.Slots[i].IsEmpty {
+			continue
+		}
+		total = total.Add(subtreeStats(&item.Slots[i].Item))
+	}
+	return total
+}
+
+func slotStats(slot *models.SlotEvaluationResult) StatDelta {
+	if slot == nil || slot.IsEmpty {
+		return StatDelta{}
+	}
+	return subtreeStats(&slot.Item)
+}
+
+func slotItem(slot *models.SlotEvaluationResult) *ItemRef {
+	if slot == nil || slot.IsEmpty {
+		return nil
+	}
+	return &ItemRef{ID: slot.Item.ID, Name: slot.Item.Name}
+}
+
+func findSlot(slots []models.SlotEvaluationResult, id string) *models.SlotEvaluationResult {
+	for i := range slots {
+		if slots[i].ID == id {
+			return &slots[i]
+		}
+	}
+	return nil
+}
+
+// Score is how much the delta improves the build type's stat, higher is better
+func (d StatDelta) Score(buildType string) float64 {
+	switch buildType {
+	case "ergonomics":
+		return d.Ergonomics.Points()
+	case "weight":
+		return -d.Weight
+	case "accuracy":
+		return d.Accuracy
+	default:
+		return -d.Recoil.Points()
+	}
+}
+
+// Sub returns the change from other to d
+func (d StatDelta) Sub(other StatDelta) StatDelta {
+	return StatDelta{
+		Recoil:     d.Recoil - other.Recoil,
+		Ergonomics: d.Ergonomics - other.Ergonomics,
+		Weight:     d.Weight - other.Weight,
+		Accuracy:   d.Accuracy - other.Accuracy,
+	}
+}
+
+// Add returns the sum of both deltas
+func (d StatDelta) Add(other StatDelta) StatDelta {
+	return StatDelta{
+		Recoil:     d.Recoil + other.Recoil,
+		Ergonomics: d.Ergonomics + other.Ergonomics,
+		Weight:     d.Weight + other.Weight,
+		Accuracy:   d.Accuracy + other.Accuracy,
+	}
+}
+
+// diffSlots walks the slots of the same item in both builds, comparing them by ID. Slots missing from the optimal
+// build, such as those ignored by its constraints, weren't considered by the optimiser and are left out.
+func diffSlots(current []models.SlotEvaluationResult, optimal []models.SlotEvaluationResult, path []string) []Swap {
+	swaps := make([]Swap, 0)
+	for i := range optimal {
+		optimalSlot := &optimal[i]
+		currentSlot := findSlot(current, optimalSlot.ID)
+		slotPath := append(slices.Clone(path), optimalSlot.ID)
+
+		currentItem := slotItem(currentSlot)
+		optimalItem := slotItem(optimalSlot)
+		if currentItem == nil && optimalItem == nil {
+			continue
+		}
+
+		if currentItem != nil && optimalItem != nil && currentItem.ID == optimalItem.ID {
+			swaps = append(swaps, diffSlots(currentSlot.Item.Slots, optimalSlot.Item.Slots, slotPath)...)
+			continue
+		}
+
+		swaps = append(swaps, Swap{
+			Path:     slotPath,
+			SlotID:   optimalSlot.ID,
+			SlotName: optimalSlot.Name,
+			Current:  currentItem,
+			Optimal:  optimalItem,
+			Delta:    slotStats(optimalSlot).Sub(slotStats(currentSlot)),
+		})
+	}
+
+	return swaps
+}
+
+// Compare diffs current against optimal, both builds of weapon, slot by slot. Each swap's delta is for that swap
+// alone, swaps which conflict with the rest of the current build may need others made with them.
+func Compare(weapon *models.Weapon, current *models.ItemEvaluationResult, optimal *models.ItemEvaluationResult, buildType string) *Comparison {
+	swaps := diffSlots(current.Slots, optimal.Slots, []string{})
+	slices.SortStableFunc(swaps, func(a, b Swap) int {
+		scoreA, scoreB := a.Delta.Score(buildType), b.Delta.Score(buildType)
+		if scoreA > scoreB {
+			return -1
+		}
+		if scoreA < scoreB {
+			return 1
+		}
+		return 0
+	})
+
+	improvement := StatDelta{}
+	for _, swap := range swaps {
+		improvement = improvement.Add(swap.Delta)
+	}
+
+	improved := *current
+	improved.RecoilSum += improvement.Recoil
+	improved.ErgonomicsSum += improvement.Ergonomics
+
+	return &Comparison{
+		BuildType:     buildType,
+		Swaps:         swaps,
+		Improvement:   improvement,
+		CurrentStats:  stats.Compute(weapon, current),
+		ImprovedStats: stats.Compute(weapon, &improved),
+	}
+}
@@ -0,0 +1,101 @@
+package builds
+
+import (
+	"tarkov-build-optimiser/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func evaluatedItem(id string, recoil float64, ergonomics float64, slots ...models.SlotEvaluationResult) models.ItemEvaluationResult {
+	return models.ItemEvaluationResult{
+		ID:                 id,
+		Name:               id,
+		RecoilModifier:     models.NewStat(recoil),
+		ErgonomicsModifier: models.NewStat(ergonomics),
+		Slots:              slots,
+	}
+}
+
+func filledSlot(id string, item models.ItemEvaluationResult) models.SlotEvaluationResult {
+	return models.SlotEvaluationResult{ID: id, Name: id, Item: item}
+}
+
+func emptySlot(id string) models.SlotEvaluationResult {
+	return models.SlotEvaluationResult{ID: id, Name: id, IsEmpty: true}
+}
+
+func TestCompare(t *testing.T) {
+	weapon := &models.Weapon{ID: "weapon", RecoilVertical: 100, RecoilHorizontal: 200, Ergonomics: 40}
+	current := &models.ItemEvaluationResult{
+		ID: "weapon",
+		Slots: []models.SlotEvaluationResult{
+			// same barrel, different muzzle device
+			filledSlot("slot-barrel", evaluatedItem("barrel", 0, -2, filledSlot("slot-muzzle", evaluatedItem("flash-hider", -2, 0)))),
+			filledSlot("slot-stock", evaluatedItem("light-stock", -5, 6)),
+			emptySlot("slot-grip"),
+			// not considered by the optimiser
+			filledSlot("slot-scope", evaluatedItem("scope", 0, -3)),
+		},
+		RecoilSum:     models.NewStat(-7),
+		ErgonomicsSum: models.NewStat(1),
+	}
+	optimal := &models.ItemEvaluationResult{
+		ID: "weapon",
+		Slots: []models.SlotEvaluationResult{
+			filledSlot("slot-barrel", evaluatedItem("barrel", 0, -2, filledSlot("slot-muzzle", evaluatedItem("brake", -12, -1)))),
+			filledSlot("slot-stock", evaluatedItem("heavy-stock", -9, 2)),
+			filledSlot("slot-grip", evaluatedItem("grip", -1, 2)),
+		},
+	}
+
+	comparison := Compare(weapon, current, optimal, "recoil")
+
+	require.Len(t, comparison.Swaps, 3)
+	assert.Equal(t, []string{"slot-barrel", "slot-muzzle"}, comparison.Swaps[0].Path)
+	assert.Equal(t, "flash-hider", comparison.Swaps[0].Current.ID)
+	assert.Equal(t, "brake", comparison.Swaps[0].Optimal.ID)
+	assert.Equal(t, models.NewStat(-10), comparison.Swaps[0].Delta.Recoil)
+	assert.Equal(t, models.NewStat(-1), comparison.Swaps[0].Delta.Ergonomics)
+	assert.Equal(t, "slot-stock", comparison.Swaps[1].SlotID)
+	assert.Equal(t, models.NewStat(-4), comparison.Swaps[1].Delta.Recoil)
+	assert.Equal(t, "slot-grip", comparison.Swaps[2].SlotID)
+	assert.Nil(t, comparison.Swaps[2].Current)
+
+	assert.Equal(t, models.NewStat(-15), comparison.Improvement.Recoil)
+	assert.Equal(t, models.NewStat(-3), comparison.Improvement.Ergonomics)
+	assert.Equal(t, 93, comparison.CurrentStats.RecoilVertical)
+	assert.Equal(t, 78, comparison.ImprovedStats.RecoilVertical)
+	assert.Equal(t, 38, comparison.ImprovedStats.Ergonomics)
+}
+
+func TestCompare_OrderedByBuildType(t *testing.T) {
+	weapon := &models.Weapon{ID: "weapon"}
+	current := &models.ItemEvaluationResult{ID: "weapon", Slots: []models.SlotEvaluationResult{
+		emptySlot("slot-stock"),
+		emptySlot("slot-grip"),
+	}}
+	optimal := &models.ItemEvaluationResult{ID: "weapon", Slots: []models.SlotEvaluationResult{
+		filledSlot("slot-stock", evaluatedItem("stock", -9, 2)),
+		filledSlot("slot-grip", evaluatedItem("grip", -1, 5)),
+	}}
+
+	comparison := Compare(weapon, current, optimal, "ergonomics")
+
+	require.Len(t, comparison.Swaps, 2)
+	assert.Equal(t, "slot-grip", comparison.Swaps[0].SlotID)
+	assert.Equal(t, "slot-stock", comparison.Swaps[1].SlotID)
+}
+
+func TestCompare_SameBuild(t *testing.T) {
+	build := &models.ItemEvaluationResult{ID: "weapon", Slots: []models.SlotEvaluationResult{
+		filledSlot("slot-stock", evaluatedItem("stock", -9, 2)),
+		emptySlot("slot-grip"),
+	}}
+
+	comparison := Compare(&models.Weapon{ID: "weapon"}, build, build, "recoil")
+
+	assert.Empty(t, comparison.Swaps)
+	assert.Equal(t, StatDelta{}, comparison.Improvement)
+}
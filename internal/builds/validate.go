@@ -0,0 +1,267 @@
+package builds
+
+import (
+	"fmt"
+	"slices"
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/models"
+	"tarkov-build-optimiser/internal/stats"
+)
+
+// Error codes of a NodeError
+const (
+	// ErrorUnknownSlot slots aren't slots of the item they were given for
+	ErrorUnknownSlot = "unknown_slot"
+	// ErrorDuplicateSlot slots were given more than once for the same item
+	ErrorDuplicateSlot = "duplicate_slot"
+	// ErrorUnknownItem items aren't weapon mods
+	ErrorUnknownItem = "unknown_item"
+	// ErrorNotAllowed items don't fit the slot
+	ErrorNotAllowed = "not_allowed"
+	// ErrorUnavailable items can't be bought from a trader at the trader levels
+	ErrorUnavailable = "unavailable"
+	// ErrorConflict items conflict with another item in the build
+	ErrorConflict = "conflict"
+	// ErrorRequiredSlot slots are required by the game but empty
+	ErrorRequiredSlot = "required_slot_empty"
+)
+
+// Build is a weapon and the mods fitted to it, as submitted by a user
+type Build struct {
+	WeaponID string `json:"weapon_id"`
+	Slots    []Slot `json:"slots"`
+}
+
+// Slot is one of an item's slots, Item is nil when the slot is empty
+type Slot struct {
+	SlotID string `json:"slot_id"`
+	Item   *Item  `json:"item"`
+}
+
+type Item struct {
+	ItemID string `json:"item_id"`
+	Slots  []Slot `json:"slots"`
+}
+
+// NodeError is a problem with one slot of a build, or the item in it
+type NodeError struct {
+	// Path is the IDs of the slots from the weapon down to and including the slot
+	Path     []string `json:"path"`
+	SlotID   string   `json:"slot_id"`
+	SlotName string   `json:"slot_name"`
+	ItemID   string   `json:"item_id,omitempty"`
+	// Code is one of the Error constants
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Validation is the outcome of validating a build
+type Validation struct {
+	Valid  bool        `json:"valid"`
+	Errors []NodeError `json:"errors"`
+	// Build is the submitted build with the stats of its items, in the form the optimiser returns builds. Slots which
+	// aren't slots of their item and items which aren't weapon mods are left out, the rest count towards the stats
+	// whatever their errors.
+	Build      models.ItemEvaluationResult `json:"build"`
+	FinalStats stats.Final                 `json:"final_stats"`
+}
+
+// fittedItem is an item in the build, kept to check conflicts once the whole build has been walked
+type fittedItem struct {
+	mod  *models.WeaponMod
+	slot models.Slot
+	path []string
+}
+
+type validator struct {
+	data         candidate_tree.TreeDataProvider
+	weapon       *models.Weapon
+	traderLevels []models.TraderLevel
+	errors       []NodeError
+	fitted       []fittedItem
+	weightSum    float64
+	accuracySum  float64
+}
+
+// Validate checks build against the rules the optimiser builds by, using the same item data: every item must be
+// allowed in its slot and available from a trader at traderLevels, no two items may conflict, and the game's required
+// slots must be filled. traderLevels should have a level for every trader, see models.CompleteTraderLevels. Errors are
+// only returned when the data can't be loaded, problems with the build are in the Validation.
+func Validate(data candidate_tree.TreeDataProvider, build Build, traderLevels []models.TraderLevel) (*Validation, error) {
+	weapon, err := data.GetWeaponById(build.WeaponID)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &validator{
+		data:         data,
+		weapon:       weapon,
+		traderLevels: traderLevels,
+		errors:       make([]NodeError, 0),
+		fitted:       make([]fittedItem, 0),
+		weightSum:    weapon.Weight,
+	}
+
+	slots, recoilSum, ergonomicsSum, err := v.validateSlots(weapon.Slots, build.Slots, []string{})
+	if err != nil {
+		return nil, err
+	}
+	v.validateConflicts()
+
+	result := &Validation{
+		Valid:  len(v.errors) == 0,
+		Errors: v.errors,
+		Build: models.ItemEvaluationResult{
+			ID:            weapon.ID,
+			Name:          weapon.Name,
+			Slots:         slots,
+			RecoilSum:     recoilSum,
+			ErgonomicsSum: ergonomicsSum,
+			WeightSum:     v.weightSum,
+			AccuracySum:   v.accuracySum,
+		},
+	}
+	result.FinalStats = stats.Compute(weapon, &result.Build)
+
+	return result, nil
+}
+
+func (v *validator) addError(path []string, slot models.Slot, itemID string, code string, message string) {
+	v.errors = append(v.errors, NodeError{
+		Path:     path,
+		SlotID:   slot.ID,
+		SlotName: slot.Name,
+		ItemID:   itemID,
+		Code:     code,
+		Message:  message,
+	})
+}
+
+// validateSlots validates the submitted slots of an item against its slots, returning the evaluated slots in the
+// item's order and the recoil and ergonomics of everything fitted to them
+func (v *validator) validateSlots(itemSlots []models.Slot, submitted []Slot, path []string) ([]models.SlotEvaluationResult, models.Stat, models.Stat, error) {
+	itemSlotsByID := make(map[string]models.Slot, len(itemSlots))
+	for _, slot := range itemSlots {
+		itemSlotsByID[slot.ID] = slot
+	}
+
+	filled := make(map[string]*models.ItemEvaluationResult, len(submitted))
+	seen := make(map[string]bool, len(submitted))
+	// slots given an item, whether or not it's a weapon mod
+	hasItem := make(map[string]bool, len(submitted))
+	for _, s := range submitted {
+		slotPath := append(slices.Clone(path), s.SlotID)
+		slot, ok := itemSlotsByID[s.SlotID]
+		if !ok {
+			v.addError(slotPath, models.Slot{ID: s.SlotID}, "", ErrorUnknownSlot, fmt.Sprintf("slot %s isn't a slot of this item", s.SlotID))
+			continue
+		}
+		if seen[s.SlotID] {
+			v.addError(slotPath, slot, "", ErrorDuplicateSlot, fmt.Sprintf("slot %s is given more than once", slot.Name))
+			continue
+		}
+		seen[s.SlotID] = true
+
+		if s.Item == nil {
+			continue
+		}
+		hasItem[s.SlotID] = true
+
+		item, err := v.validateItem(slot, *s.Item, slotPath)
+		if err != nil {
+			return nil, 0, 0, err
+		}
+		if item != nil {
+			filled[slot.ID] = item
+		}
+	}
+
+	results := make([]models.SlotEvaluationResult, 0, len(itemSlots))
+	recoilSum := models.Stat(0)
+	ergonomicsSum := models.Stat(0)
+	for _, slot := range itemSlots {
+		result := models.SlotEvaluationResult{ID: slot.ID, Name: slot.Name, IsEmpty: true}
+		item, ok := filled[slot.ID]
+		if ok {
+			result.Item = *item
+			result.IsEmpty = false
+			recoilSum += item.RecoilSum
+			ergonomicsSum += item.ErgonomicsSum
+		} else if slot.Required && !hasItem[slot.ID] {
+			v.addError(append(slices.Clone(path), slot.ID), slot, "", ErrorRequiredSlot, fmt.Sprintf("slot %s must be filled", slot.Name))
+		}
+		results = append(results, result)
+	}
+
+	return results, recoilSum, ergonomicsSum, nil
+}
+
+// validateItem validates an item and everything fitted to it, returning nil if it isn't a weapon mod
+func (v *validator) validateItem(slot models.Slot, item Item, path []string) (*models.ItemEvaluationResult, error) {
+	// as when populating candidate trees, an item which can't be loaded isn't a weapon mod
+	mod, err := v.data.GetWeaponModById(item.ItemID)
+	if err != nil || mod == nil {
+		v.addError(path, slot, item.ItemID, ErrorUnknownItem, fmt.Sprintf("item %s isn't a weapon mod", item.ItemID))
+		return nil, nil
+	}
+
+	allowedItems, err := v.data.GetAllowedItemsBySlotID(slot.ID)
+	if err != nil {
+		return nil, err
+	}
+	allowed := slices.ContainsFunc(allowedItems, func(allowedItem *models.AllowedItem) bool {
+		return allowedItem.ID == mod.ID
+	})
+	if !allowed {
+		v.addError(path, slot, mod.ID, ErrorNotAllowed, fmt.Sprintf("%s doesn't fit slot %s", mod.Name, slot.Name))
+	}
+
+	offers, err := v.data.GetTraderOffer(mod.ID)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := candidate_tree.CheapestOffer(offers, v.traderLevels); !ok {
+		v.addError(path, slot, mod.ID, ErrorUnavailable, fmt.Sprintf("%s can't be bought at the trader levels", mod.Name))
+	}
+
+	v.fitted = append(v.fitted, fittedItem{mod: mod, slot: slot, path: path})
+
+	accuracyModifier := mod.AccuracyModifier + candidate_tree.BarrelAccuracyModifier(v.weapon.CenterOfImpact, mod.CenterOfImpact)
+	v.weightSum += mod.Weight
+	v.accuracySum += accuracyModifier
+
+	modSlots, err := v.data.GetSlotsByItemID(mod.ID)
+	if err != nil {
+		return nil, err
+	}
+	slots, recoilSum, ergonomicsSum, err := v.validateSlots(modSlots, item.Slots, path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.ItemEvaluationResult{
+		ID:                 mod.ID,
+		Name:               mod.Name,
+		IsSubtree:          true,
+		RecoilModifier:     mod.RecoilModifier,
+		ErgonomicsModifier: mod.ErgonomicsModifier,
+		Weight:             mod.Weight,
+		AccuracyModifier:   accuracyModifier,
+		Slots:              slots,
+		RecoilSum:          mod.RecoilModifier + recoilSum,
+		ErgonomicsSum:      mod.ErgonomicsModifier + ergonomicsSum,
+	}, nil
+}
+
+// validateConflicts reports each pair of conflicting items once, against the item fitted later
+func (v *validator) validateConflicts() {
+	for i := 0; i < len(v.fitted); i++ {
+		for j := 0; j < i; j++ {
+			a, b := v.fitted[i], v.fitted[j]
+			if !slices.Contains(a.mod.ConflictingItems, b.mod.ID) && !slices.Contains(b.mod.ConflictingItems, a.mod.ID) {
+				continue
+			}
+			v.addError(a.path, a.slot, a.mod.ID, ErrorConflict, fmt.Sprintf("%s conflicts with %s in slot %s", a.mod.Name, b.mod.Name, b.slot.Name))
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package builds
+
+import (
+	"fmt"
+	"tarkov-build-optimiser/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testData is a weapon with a required barrel slot, whose barrel takes a muzzle device, and a stock slot
+type testData struct {
+	weapon  *models.Weapon
+	mods    map[string]*models.WeaponMod
+	slots   map[string][]models.Slot
+	allowed map[string][]*models.AllowedItem
+	offers  map[string][]models.TraderOffer
+}
+
+func newTestData() *testData {
+	return &testData{
+		weapon: &models.Weapon{
+			ID: "weapon", Name: "weapon", Weight: 3, RecoilVertical: 100, RecoilHorizontal: 200, Ergonomics: 40,
+			Slots: []models.Slot{
+				{ID: "slot-barrel", Name: "Barrel", Required: true},
+				{ID: "slot-stock", Name: "Stock"},
+			},
+		},
+		mods: map[string]*models.WeaponMod{
+			"barrel":  {ID: "barrel", Name: "barrel", ErgonomicsModifier: models.NewStat(-2), Weight: 0.5},
+			"brake":   {ID: "brake", Name: "brake", RecoilModifier: models.NewStat(-10), Weight: 0.1},
+			"stock":   {ID: "stock", Name: "stock", RecoilModifier: models.NewStat(-15), ErgonomicsModifier: models.NewStat(4.5), Weight: 0.4, ConflictingItems: []string{"brake"}},
+			"stock-2": {ID: "stock-2", Name: "stock 2", RecoilModifier: models.NewStat(-5), Weight: 0.3},
+		},
+		slots: map[string][]models.Slot{
+			"barrel": {{ID: "slot-muzzle", Name: "Muzzle"}},
+		},
+		allowed: map[string][]*models.AllowedItem{
+			"slot-barrel": {{ID: "barrel"}},
+			"slot-muzzle": {{ID: "brake"}},
+			"slot-stock":  {{ID: "stock"}, {ID: "stock-2"}},
+		},
+		offers: map[string][]models.TraderOffer{
+			"barrel":  {{Trader: "Prapor", MinTraderLevel: 1, PriceRub: 1000}},
+			"brake":   {{Trader: "Prapor", MinTraderLevel: 1, PriceRub: 1000}},
+			"stock":   {{Trader: "Prapor", MinTraderLevel: 1, PriceRub: 1000}},
+			"stock-2": {{Trader: "Mechanic", MinTraderLevel: 3, PriceRub: 1000}},
+		},
+	}
+}
+
+func (d *testData) GetWeaponById(id string) (*models.Weapon, error) {
+	if id != d.weapon.ID {
+		return nil, fmt.Errorf("No results for %s", id)
+	}
+	return d.weapon, nil
+}
+
+func (d *testData) GetSlotsByItemID(id string) ([]models.Slot, error) {
+	return d.slots[id], nil
+}
+
+func (d *testData) GetWeaponModById(id string) (*models.WeaponMod, error) {
+	mod, ok := d.mods[id]
+	if !ok {
+		return nil, fmt.Errorf("weapon mod with id %s not found", id)
+	}
+	return mod, nil
+}
+
+func (d *testData) GetAllowedItemsBySlotID(id string) ([]*models.AllowedItem, error) {
+	return d.allowed[id], nil
+}
+
+func (d *testData) GetTraderOffer(id string) ([]models.TraderOffer, error) {
+	return d.offers[id], nil
+}
+
+func (d *testData) IsWeapon(id string) (bool, error) {
+	return id == d.weapon.ID, nil
+}
+
+var maxTraderLevels = []models.TraderLevel{{Name: "Prapor", Level: 4}, {Name: "Mechanic", Level: 4}}
+
+func errorCodes(validation *Validation) []string {
+	codes := make([]string, 0, len(validation.Errors))
+	for _, err := range validation.Errors {
+		codes = append(codes, err.Code)
+	}
+	return codes
+}
+
+func TestValidate_ValidBuild(t *testing.T) {
+	build := Build{WeaponID: "weapon", Slots: []Slot{
+		{SlotID: "slot-barrel", Item: &Item{ItemID: "barrel", Slots: []Slot{{SlotID: "slot-muzzle", Item: &Item{ItemID: "brake"}}}}},
+		{SlotID: "slot-stock", Item: &Item{ItemID: "stock-2"}},
+	}}
+
+	validation, err := Validate(newTestData(), build, maxTraderLevels)
+	require.NoError(t, err)
+
+	assert.True(t, validation.Valid, validation.Errors)
+	assert.Empty(t, validation.Errors)
+	assert.Equal(t, models.NewStat(-15), validation.Build.RecoilSum)
+	assert.Equal(t, models.NewStat(-2), validation.Build.ErgonomicsSum)
+	assert.InDelta(t, 3.9, validation.Build.WeightSum, 1e-9)
+	// the barrel's sums include the brake fitted to it
+	barrel := validation.Build.Slots[0].Item
+	assert.Equal(t, "barrel", barrel.ID)
+	assert.Equal(t, models.NewStat(-10), barrel.RecoilSum)
+	assert.Equal(t, "brake", barrel.Slots[0].Item.ID)
+	assert.Equal(t, 85, validation.FinalStats.RecoilVertical)
+	assert.Equal(t, 170, validation.FinalStats.RecoilHorizontal)
+	assert.Equal(t, 38, validation.FinalStats.Ergonomics)
+}
+
+func TestValidate_Conflict(t *testing.T) {
+	build := Build{WeaponID: "weapon", Slots: []Slot{
+		{SlotID: "slot-barrel", Item: &Item{ItemID: "barrel", Slots: []Slot{{SlotID: "slot-muzzle", Item: &Item{ItemID: "brake"}}}}},
+		{SlotID: "slot-stock", Item: &Item{ItemID: "stock"}},
+	}}
+
+	validation, err := Validate(newTestData(), build, maxTraderLevels)
+	require.NoError(t, err)
+
+	assert.False(t, validation.Valid)
+	require.Len(t, validation.Errors, 1)
+	assert.Equal(t, ErrorConflict, validation.Errors[0].Code)
+	assert.Equal(t, "stock", validation.Errors[0].ItemID)
+	assert.Equal(t, []string{"slot-stock"}, validation.Errors[0].Path)
+	// conflicting items still count towards the stats
+	assert.Equal(t, models.NewStat(-25), validation.Build.RecoilSum)
+}
+
+func TestValidate_RequiredSlotAndUnavailableItem(t *testing.T) {
+	build := Build{WeaponID: "weapon", Slots: []Slot{
+		{SlotID: "slot-stock", Item: &Item{ItemID: "stock-2"}},
+	}}
+	traderLevels := []models.TraderLevel{{Name: "Prapor", Level: 4}, {Name: "Mechanic", Level: 2}}
+
+	validation, err := Validate(newTestData(), build, traderLevels)
+	require.NoError(t, err)
+
+	assert.False(t, validation.Valid)
+	assert.ElementsMatch(t, []string{ErrorUnavailable, ErrorRequiredSlot}, errorCodes(validation))
+	assert.True(t, validation.Build.Slots[0].IsEmpty)
+}
+
+func TestValidate_ItemsAndSlotsWhichDontFit(t *testing.T) {
+	build := Build{WeaponID: "weapon", Slots: []Slot{
+		{SlotID: "slot-barrel", Item: &Item{ItemID: "barrel", Slots: []Slot{
+			{SlotID: "slot-muzzle", Item: &Item{ItemID: "stock"}},
+			{SlotID: "slot-scope", Item: &Item{ItemID: "brake"}},
+		}}},
+		{SlotID: "slot-stock", Item: &Item{ItemID: "stock-2"}},
+		{SlotID: "slot-stock", Item: &Item{ItemID: "stock"}},
+		{SlotID: "slot-pistol-grip", Item: &Item{ItemID: "grip"}},
+	}}
+
+	validation, err := Validate(newTestData(), build, maxTraderLevels)
+	require.NoError(t, err)
+
+	assert.False(t, validation.Valid)
+	assert.ElementsMatch(t, []string{ErrorNotAllowed, ErrorUnknownSlot, ErrorDuplicateSlot, ErrorUnknownSlot}, errorCodes(validation))
+	for _, err := range validation.Errors {
+		if err.Code == ErrorNotAllowed {
+			assert.Equal(t, []string{"slot-barrel", "slot-muzzle"}, err.Path)
+			assert.Equal(t, "stock", err.ItemID)
+		}
+	}
+}
+
+func TestValidate_UnknownItem(t *testing.T) {
+	build := Build{WeaponID: "weapon", Slots: []Slot{
+		{SlotID: "slot-barrel", Item: &Item{ItemID: "not-a-mod"}},
+	}}
+
+	validation, err := Validate(newTestData(), build, maxTraderLevels)
+	require.NoError(t, err)
+
+	// the barrel slot was given an item, so it isn't also reported empty
+	assert.Equal(t, []string{ErrorUnknownItem}, errorCodes(validation))
+	assert.True(t, validation.Build.Slots[0].IsEmpty)
+}
@@ -0,0 +1,11 @@
+package candidate_tree
+
+// BarrelAccuracyModifier converts a barrel's center of impact into an accuracy modifier in percent, relative to the
+// weapon's own center of impact, so barrels can be compared with the accuracy modifiers of other mods. A smaller
+// center of impact is more accurate. Returns 0 when either is unknown.
+func BarrelAccuracyModifier(weaponCenterOfImpact float64, barrelCenterOfImpact float64) float64 {
+	if weaponCenterOfImpact <= 0 || barrelCenterOfImpact <= 0 {
+		return 0
+	}
+	return (weaponCenterOfImpact/barrelCenterOfImpact - 1) * 100
+}
@@ -0,0 +1,16 @@
+package candidate_tree
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBarrelAccuracyModifier(t *testing.T) {
+	// a barrel with half the weapon's center of impact is twice as accurate
+	assert.InDelta(t, 100.0, BarrelAccuracyModifier(0.08, 0.04), 1e-9)
+	assert.InDelta(t, -50.0, BarrelAccuracyModifier(0.04, 0.08), 1e-9)
+	assert.Equal(t, 0.0, BarrelAccuracyModifier(0.04, 0.04))
+	assert.Equal(t, 0.0, BarrelAccuracyModifier(0, 0.04))
+	assert.Equal(t, 0.0, BarrelAccuracyModifier(0.04, 0))
+}
@@ -1,6 +1,7 @@
 package candidate_tree
 
 import (
+	"math"
 	"sort"
 	"tarkov-build-optimiser/internal/models"
 
@@ -34,6 +35,64 @@ type CandidateTree struct {
 	allowedItemSlots   []*ItemSlot
 	allowedItemSlotMap map[string]*ItemSlot
 	Constraints        models.EvaluationConstraints
+	// size of the tree before and after useless and precomputed items were pruned
+	SizeBeforePruning TreeSize
+	SizeAfterPruning  TreeSize
+}
+
+// TreeSize counts the allowed items and slots below a weapon, each node counted once
+type TreeSize struct {
+	AllowedItems int `json:"allowed_items"`
+	Slots        int `json:"slots"`
+	// number of distinct builds the tree allows, ignoring conflicts. Every slot may also be left empty.
+	// Capped at math.MaxFloat64 so it can always be encoded.
+	Combinations float64 `json:"combinations"`
+}
+
+// Size walks the tree counting the allowed items and slots currently below the weapon
+func (wt *CandidateTree) Size() TreeSize {
+	size := TreeSize{}
+	var walk func(item *Item) float64
+	walk = func(item *Item) float64 {
+		combinations := 1.0
+		for _, slot := range item.Slots {
+			size.Slots++
+			// leaving the slot empty is always an option
+			options := 1.0
+			for _, allowed := range slot.AllowedItems {
+				size.AllowedItems++
+				options += walk(allowed)
+			}
+			combinations *= options
+		}
+		return math.Min(combinations, math.MaxFloat64)
+	}
+	size.Combinations = walk(wt.Item)
+	return size
+}
+
+// ConflictDensity is the fraction of pairs of distinct allowed items which conflict with each other,
+// 0 when there are fewer than two allowed items
+func (wt *CandidateTree) ConflictDensity() float64 {
+	ids := make([]string, 0, len(wt.allowedItemMap))
+	for id := range wt.allowedItemMap {
+		ids = append(ids, id)
+	}
+	if len(ids) < 2 {
+		return 0
+	}
+
+	conflicting := 0
+	for i := 0; i < len(ids); i++ {
+		for j := i + 1; j < len(ids); j++ {
+			if wt.AllowedItemConflicts[ids[i]][ids[j]] || wt.AllowedItemConflicts[ids[j]][ids[i]] {
+				conflicting++
+			}
+		}
+	}
+
+	pairs := len(ids) * (len(ids) - 1) / 2
+	return float64(conflicting) / float64(pairs)
 }
 
 // GetPrecomputedProvider exposes a precomputed subtree provider if the underlying dataService implements it.
@@ -69,8 +128,12 @@ func (wt *CandidateTree) AddCandidateItem(itemID string) {
 	wt.CandidateItems[itemID] = true
 }
 
-// UpdateAllowedItemSlots updates allowedItemSlots with the current state of the tree
+// SlotPathSeparator joins the slot IDs of ItemSlot.Path
+const SlotPathSeparator = "/"
+
+// UpdateAllowedItemSlots updates allowedItemSlots and the slots' paths with the current state of the tree
 func (wt *CandidateTree) UpdateAllowedItemSlots() {
+	wt.Item.updateSlotPaths("")
 	slots := wt.Item.GetDescendantSlots()
 	wt.allowedItemSlots = slots
 	wt.updateAllowedItemSlotsMap()
@@ -102,6 +165,30 @@ func (wt *CandidateTree) UpdateAllowedItems() {
 	wt.updateAllowedItemsMap()
 }
 
+// UpdateRequiredCategories marks the items which can supply one of the constraints' required categories, so they
+// survive pruning and aren't skipped while searching
+func (wt *CandidateTree) UpdateRequiredCategories() {
+	wt.Item.updateRequiredCategories(wt.Constraints)
+}
+
+// MeetsRequiredCategories reports whether a build of the given allowed items uses every required category
+func (wt *CandidateTree) MeetsRequiredCategories(itemIDs []string) bool {
+	for _, rule := range wt.Constraints.RequiredCategories {
+		met := false
+		for _, id := range itemIDs {
+			item := wt.allowedItemMap[id]
+			if item != nil && models.CategoryMatches(rule, item.CategoryID, item.CategoryName) {
+				met = true
+				break
+			}
+		}
+		if !met {
+			return false
+		}
+	}
+	return true
+}
+
 func (wt *CandidateTree) GetAllowedItem(id string) *Item {
 	return wt.allowedItemMap[id]
 }
@@ -124,6 +211,21 @@ func (wt *CandidateTree) SortAllowedItems(by string) {
 	}
 }
 
+// BestFirstSort returns the SortAllowedItems order which puts the items that can do the most for buildType first, so
+// a search finds good builds early and prunes more
+func BestFirstSort(buildType string) string {
+	switch buildType {
+	case "ergonomics":
+		return "ergonomics-max-desc"
+	case "weight":
+		return "weight-min"
+	case "accuracy":
+		return "accuracy-max-desc"
+	default:
+		return "recoil-min"
+	}
+}
+
 func (wt *CandidateTree) OrderSlotsByConstraint(slots []*ItemSlot) []*ItemSlot {
 	ordered := make([]*ItemSlot, len(slots))
 	copy(ordered, slots)
@@ -142,10 +244,68 @@ func CreateWeaponCandidateTree(id string, focusedStat string, constraints models
 		return nil, err
 	}
 
-	return constructCandidateTree(id, w.Name, w.RecoilModifier, w.ErgonomicsModifier, focusedStat, constraints, data)
+	return constructCandidateTree(id, w, focusedStat, constraints, data)
+}
+
+// CreateFullWeaponCandidateTree is CreateWeaponCandidateTree without pruning. Pruning keeps the items which are best
+// for a single stat, searches balancing several stats or requiring specific items need all of them.
+func CreateFullWeaponCandidateTree(id string, constraints models.EvaluationConstraints, data TreeDataProvider) (*CandidateTree, error) {
+	w, err := data.GetWeaponById(id)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to get weapon %s", id)
+		return nil, err
+	}
+
+	candidateTree, err := populateCandidateTree(id, w, constraints, data)
+	if err != nil {
+		return nil, err
+	}
+
+	candidateTree.SizeBeforePruning = candidateTree.Size()
+	candidateTree.UpdateRequiredCategories()
+	candidateTree.UpdateAllowedItems()
+	candidateTree.UpdateAllowedItemSlots()
+	candidateTree.SizeAfterPruning = candidateTree.SizeBeforePruning
+
+	return candidateTree, nil
 }
 
-func constructCandidateTree(id string, name string, recoilModifier int, ergoModifier int, focusedStat string, constraints models.EvaluationConstraints, data TreeDataProvider) (*CandidateTree, error) {
+func constructCandidateTree(id string, w *models.Weapon, focusedStat string, constraints models.EvaluationConstraints, data TreeDataProvider) (*CandidateTree, error) {
+	candidateTree, err := populateCandidateTree(id, w, constraints, data)
+	if err != nil {
+		return nil, err
+	}
+
+	candidateTree.SortAllowedItems(focusedStat)
+	candidateTree.SizeBeforePruning = candidateTree.Size()
+	candidateTree.UpdateRequiredCategories()
+
+	// pruning keeps the best items for recoil whatever their other stats, so only a recoil focus without a weight
+	// limit can use it. Precomputed subtrees were chosen for the focused stat.
+	if constraints.MaxWeight <= 0 && focusedStat != "weight" && focusedStat != "accuracy" {
+		if focusedStat == "recoil" {
+			candidateTree.pruneUselessAllowedItems()
+		}
+
+		// Hook: apply precomputed subtree pruning if dataService implements PrecomputedSubtreeProvider.
+		// Precomputed subtrees were chosen without category requirements, so they can't be used with them.
+		provider, ok := any(candidateTree.dataService).(PrecomputedSubtreeProvider)
+		if ok && len(constraints.RequiredCategories) == 0 {
+			ApplyPrecomputedPruning(candidateTree, focusedStat, provider)
+		}
+	}
+
+	candidateTree.UpdateAllowedItems()
+	candidateTree.updateAllowedItemsMap()
+	candidateTree.UpdateAllowedItemSlots()
+	candidateTree.updateAllowedItemSlotsMap()
+	candidateTree.SizeAfterPruning = candidateTree.Size()
+
+	return candidateTree, nil
+}
+
+// populateCandidateTree builds the weapon's tree of every allowed item within constraints, with potential values
+func populateCandidateTree(id string, w *models.Weapon, constraints models.EvaluationConstraints, data TreeDataProvider) (*CandidateTree, error) {
 	candidateTree := &CandidateTree{
 		dataService:          data,
 		AllowedItemConflicts: map[string]map[string]bool{},
@@ -158,9 +318,11 @@ func constructCandidateTree(id string, name string, recoilModifier int, ergoModi
 
 	item := &Item{
 		ID:                 id,
-		Name:               name,
-		RecoilModifier:     recoilModifier,
-		ErgonomicsModifier: ergoModifier,
+		Name:               w.Name,
+		RecoilModifier:     w.RecoilModifier,
+		ErgonomicsModifier: w.ErgonomicsModifier,
+		Weight:             w.Weight,
+		CenterOfImpact:     w.CenterOfImpact,
 		Slots:              []*ItemSlot{},
 		parentSlot:         nil,
 		Type:               "weapon",
@@ -176,18 +338,6 @@ func constructCandidateTree(id string, name string, recoilModifier int, ergoModi
 	}
 
 	item.CalculatePotentialValues()
-	candidateTree.SortAllowedItems(focusedStat)
-	candidateTree.pruneUselessAllowedItems()
-
-	// Hook: apply precomputed subtree pruning if dataService implements PrecomputedSubtreeProvider
-	if provider, ok := any(candidateTree.dataService).(PrecomputedSubtreeProvider); ok {
-		ApplyPrecomputedPruning(candidateTree, focusedStat, provider)
-	}
-
-	candidateTree.UpdateAllowedItems()
-	candidateTree.updateAllowedItemsMap()
-	candidateTree.UpdateAllowedItemSlots()
-	candidateTree.updateAllowedItemSlotsMap()
 
 	return candidateTree, nil
 }
@@ -0,0 +1,74 @@
+package candidate_tree
+
+import (
+	"tarkov-build-optimiser/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCandidateTree_Size(t *testing.T) {
+	nested := &ItemSlot{ID: "slot-nested", AllowedItems: []*Item{{ID: "item-C"}, {ID: "item-D"}}}
+	slotA := &ItemSlot{
+		ID:           "slot-A",
+		AllowedItems: []*Item{{ID: "item-A", Slots: []*ItemSlot{nested}}, {ID: "item-B"}},
+	}
+	slotB := &ItemSlot{ID: "slot-B"}
+	tree := &CandidateTree{Item: &Item{ID: "W", Slots: []*ItemSlot{slotA, slotB}}}
+
+	// slot-A: empty, item-B, or item-A with its nested slot empty, C or D (3) = 5 options
+	// slot-B: empty only = 1 option
+	assert.Equal(t, TreeSize{AllowedItems: 4, Slots: 3, Combinations: 5}, tree.Size())
+}
+
+func TestCandidateTree_ConflictDensity(t *testing.T) {
+	tree := &CandidateTree{
+		allowedItemMap: map[string]*Item{
+			"item-A": {ID: "item-A"},
+			"item-B": {ID: "item-B"},
+			"item-C": {ID: "item-C"},
+		},
+		AllowedItemConflicts: map[string]map[string]bool{
+			// conflicts are counted once whichever direction they're recorded in
+			"item-A": {"item-B": true, "not-allowed": true},
+			"item-B": {"item-A": true},
+		},
+	}
+
+	assert.InDelta(t, 1.0/3.0, tree.ConflictDensity(), 1e-9)
+	assert.Equal(t, 0.0, (&CandidateTree{}).ConflictDensity())
+}
+
+func TestCandidateTree_UpdateRequiredCategories(t *testing.T) {
+	grip := &Item{ID: "grip", CategoryName: "Foregrip"}
+	handguard := &Item{ID: "handguard", CategoryName: "Handguard", Slots: []*ItemSlot{{ID: "slot-grip", AllowedItems: []*Item{grip}}}}
+	stock := &Item{ID: "stock", CategoryName: "Stock"}
+	tree := &CandidateTree{
+		Item: &Item{ID: "W", Type: "weapon", Slots: []*ItemSlot{
+			{ID: "slot-handguard", AllowedItems: []*Item{handguard}},
+			{ID: "slot-stock", AllowedItems: []*Item{stock}},
+		}},
+		Constraints: models.EvaluationConstraints{RequiredCategories: []string{"foregrip"}},
+	}
+
+	tree.UpdateRequiredCategories()
+
+	assert.True(t, grip.ProvidesRequiredCategory)
+	assert.True(t, handguard.ProvidesRequiredCategory)
+	assert.False(t, stock.ProvidesRequiredCategory)
+}
+
+func TestCandidateTree_MeetsRequiredCategories(t *testing.T) {
+	tree := &CandidateTree{
+		allowedItemMap: map[string]*Item{
+			"grip":     {ID: "grip", CategoryID: "grip-category", CategoryName: "Foregrip"},
+			"silencer": {ID: "silencer", CategoryName: "Silencer"},
+		},
+		Constraints: models.EvaluationConstraints{RequiredCategories: []string{"grip-category", "Silencer"}},
+	}
+
+	assert.True(t, tree.MeetsRequiredCategories([]string{"silencer", "grip"}))
+	assert.False(t, tree.MeetsRequiredCategories([]string{"grip"}))
+	assert.False(t, tree.MeetsRequiredCategories(nil))
+	assert.True(t, (&CandidateTree{}).MeetsRequiredCategories(nil))
+}
@@ -28,6 +28,31 @@ func CreateDataService(db *sql.DB) *DataService {
 	}
 }
 
+// Warm loads every weapon mod, slot and allowed item up front so the first trees built from this DataService
+// don't pay for it
+func (tds *DataService) Warm() error {
+	err := tds.loadAllWeaponMods()
+	if err != nil {
+		return err
+	}
+
+	err = tds.loadAllSlots()
+	if err != nil {
+		return err
+	}
+
+	allAllowedItems, err := models.GetAllAllowedItems(tds.db)
+	if err != nil {
+		return err
+	}
+
+	tds.allowedItemBySlotIDMu.Lock()
+	tds.allowedItemBySlotIDCache = allAllowedItems
+	tds.allowedItemBySlotIDMu.Unlock()
+
+	return nil
+}
+
 func (tds *DataService) GetWeaponById(id string) (*models.Weapon, error) {
 	return models.GetWeaponById(tds.db, id)
 }
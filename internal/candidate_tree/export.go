@@ -0,0 +1,198 @@
+package candidate_tree
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"tarkov-build-optimiser/internal/models"
+)
+
+// TreeExport is a candidate tree as plain data, every allowed item included, for inspecting a weapon's search space
+type TreeExport struct {
+	WeaponID     string               `json:"weapon_id"`
+	Name         string               `json:"name"`
+	TraderLevels []models.TraderLevel `json:"trader_levels"`
+	// SizeBeforePruning is the tree after trader filtering, Size after pruning as well
+	SizeBeforePruning TreeSize     `json:"size_before_pruning"`
+	Size              TreeSize     `json:"size"`
+	ConflictDensity   float64      `json:"conflict_density"`
+	Weapon            ExportedItem `json:"weapon"`
+}
+
+// ExportedItem is an item of an exported tree with the slots below it
+type ExportedItem struct {
+	ID                 string          `json:"item_id"`
+	Name               string          `json:"name"`
+	CategoryName       string          `json:"category_name,omitempty"`
+	RecoilModifier     models.Stat     `json:"recoil_modifier"`
+	ErgonomicsModifier models.Stat     `json:"ergonomics_modifier"`
+	Weight             float64         `json:"weight"`
+	AccuracyModifier   float64         `json:"accuracy_modifier"`
+	PriceRub           int             `json:"price_rub"`
+	PotentialValues    PotentialValues `json:"potential_values"`
+	// ConflictingItems are the IDs of the other items in the tree this item can't be fitted with
+	ConflictingItems []string       `json:"conflicting_items"`
+	Slots            []ExportedSlot `json:"slots"`
+}
+
+// ExportedSlot is a slot of an exported tree with the items allowed in it
+type ExportedSlot struct {
+	ID   string `json:"slot_id"`
+	Name string `json:"name"`
+	// Path tells apart the slots of an item allowed in several slots, see ItemSlot.Path
+	Path            string          `json:"path"`
+	PotentialValues PotentialValues `json:"potential_values"`
+	AllowedItems    []ExportedItem  `json:"allowed_items"`
+}
+
+// treeItemIDs returns the IDs of every item in the tree, the weapon included
+func (wt *CandidateTree) treeItemIDs() map[string]bool {
+	ids := map[string]bool{wt.Item.ID: true}
+	var walk func(item *Item)
+	walk = func(item *Item) {
+		for _, slot := range item.Slots {
+			for _, allowed := range slot.AllowedItems {
+				ids[allowed.ID] = true
+				walk(allowed)
+			}
+		}
+	}
+	walk(wt.Item)
+	return ids
+}
+
+// conflictsInTree returns the IDs of the items in the tree the item conflicts with, either way round
+func (wt *CandidateTree) conflictsInTree(item *Item, inTree map[string]bool) []string {
+	conflicts := make([]string, 0)
+	for _, conflict := range item.ConflictingItems {
+		if inTree[conflict.ID] && !slices.Contains(conflicts, conflict.ID) {
+			conflicts = append(conflicts, conflict.ID)
+		}
+	}
+	for id, conflictsWith := range wt.AllowedItemConflicts {
+		if conflictsWith[item.ID] && inTree[id] && !slices.Contains(conflicts, id) {
+			conflicts = append(conflicts, id)
+		}
+	}
+	slices.Sort(conflicts)
+	return conflicts
+}
+
+func (wt *CandidateTree) exportItem(item *Item, inTree map[string]bool) ExportedItem {
+	exported := ExportedItem{
+		ID:                 item.ID,
+		Name:               item.Name,
+		CategoryName:       item.CategoryName,
+		RecoilModifier:     item.RecoilModifier,
+		ErgonomicsModifier: item.ErgonomicsModifier,
+		Weight:             item.Weight,
+		AccuracyModifier:   item.AccuracyModifier,
+		PriceRub:           item.PriceRub,
+		PotentialValues:    item.PotentialValues,
+		ConflictingItems:   wt.conflictsInTree(item, inTree),
+		Slots:              make([]ExportedSlot, 0, len(item.Slots)),
+	}
+	for _, slot := range item.Slots {
+		exportedSlot := ExportedSlot{
+			ID:              slot.ID,
+			Name:            slot.Name,
+			Path:            slot.Path,
+			PotentialValues: slot.PotentialValues,
+			AllowedItems:    make([]ExportedItem, 0, len(slot.AllowedItems)),
+		}
+		for _, allowed := range slot.AllowedItems {
+			exportedSlot.AllowedItems = append(exportedSlot.AllowedItems, wt.exportItem(allowed, inTree))
+		}
+		exported.Slots = append(exported.Slots, exportedSlot)
+	}
+	return exported
+}
+
+// Export returns the tree, as it currently is, as plain data
+func (wt *CandidateTree) Export() TreeExport {
+	return TreeExport{
+		WeaponID:          wt.Item.ID,
+		Name:              wt.Item.Name,
+		TraderLevels:      wt.Constraints.TraderLevels,
+		SizeBeforePruning: wt.SizeBeforePruning,
+		Size:              wt.Size(),
+		ConflictDensity:   wt.ConflictDensity(),
+		Weapon:            wt.exportItem(wt.Item, wt.treeItemIDs()),
+	}
+}
+
+// WriteJSON writes the tree's Export as indented JSON
+func (wt *CandidateTree) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(wt.Export())
+}
+
+// dotQuote quotes s as a Graphviz DOT string
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// WriteDOT writes the tree as a Graphviz DOT graph: items are boxes, labelled with their potential recoil and
+// ergonomics, slots are ellipses and conflicting items are joined by red dashed edges. The same item can be allowed in
+// several slots, so nodes are per position in the tree and every position of conflicting items is joined.
+func (wt *CandidateTree) WriteDOT(w io.Writer) error {
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "digraph %s {\n", dotQuote(wt.Item.Name))
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [fontname=\"Helvetica\", fontsize=10];\n")
+
+	inTree := wt.treeItemIDs()
+	nodes := make(map[string][]string)
+	items := make(map[string]*Item)
+	next := 0
+	var walk func(item *Item) string
+	walk = func(item *Item) string {
+		node := fmt.Sprintf("i%d", next)
+		next++
+		nodes[item.ID] = append(nodes[item.ID], node)
+		items[item.ID] = item
+
+		label := fmt.Sprintf("%s\nrecoil %g..%g\nergo %g..%g", item.Name,
+			item.PotentialValues.MinRecoil.Points(), item.PotentialValues.MaxRecoil.Points(),
+			item.PotentialValues.MinErgonomics.Points(), item.PotentialValues.MaxErgonomics.Points())
+		fmt.Fprintf(b, "  %s [shape=box, label=%s];\n", node, dotQuote(label))
+
+		for _, slot := range item.Slots {
+			slotNode := fmt.Sprintf("s%d", next)
+			next++
+			fmt.Fprintf(b, "  %s [shape=ellipse, style=filled, fillcolor=lightgrey, label=%s];\n", slotNode, dotQuote(slot.Name))
+			fmt.Fprintf(b, "  %s -> %s;\n", node, slotNode)
+			for _, allowed := range slot.AllowedItems {
+				fmt.Fprintf(b, "  %s -> %s;\n", slotNode, walk(allowed))
+			}
+		}
+		return node
+	}
+	walk(wt.Item)
+
+	// each pair is joined once, whichever item lists the conflict
+	ids := make([]string, 0, len(items))
+	for id := range items {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	for _, id := range ids {
+		for _, conflict := range wt.conflictsInTree(items[id], inTree) {
+			if conflict <= id {
+				continue
+			}
+			for _, from := range nodes[id] {
+				for _, to := range nodes[conflict] {
+					fmt.Fprintf(b, "  %s -> %s [dir=none, color=red, style=dashed, constraint=false];\n", from, to)
+				}
+			}
+		}
+	}
+
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
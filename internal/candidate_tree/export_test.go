@@ -0,0 +1,67 @@
+package candidate_tree
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newExportTree has the same rail cover allowed in two slots, and a stock conflicting with it
+func newExportTree() *CandidateTree {
+	cover := func() *Item { return &Item{ID: "item-cover", Name: "rail cover"} }
+	handguard := &Item{ID: "item-handguard", Name: "handguard", Slots: []*ItemSlot{
+		{ID: "slot-left", Name: "left rail", AllowedItems: []*Item{cover()}},
+		{ID: "slot-right", Name: "right rail", AllowedItems: []*Item{cover()}},
+	}}
+	stock := &Item{ID: "item-stock", Name: `"Best" stock`, ConflictingItems: []ConflictingItem{{ID: "item-cover"}, {ID: "item-not-allowed"}}}
+	tree := &CandidateTree{
+		Item: &Item{ID: "item-weapon", Name: "Weapon", Type: "weapon", Slots: []*ItemSlot{
+			{ID: "slot-handguard", Name: "handguard", AllowedItems: []*Item{handguard}},
+			{ID: "slot-stock", Name: "stock", AllowedItems: []*Item{stock}},
+		}},
+		AllowedItemConflicts: map[string]map[string]bool{"item-stock": {"item-cover": true}},
+	}
+	tree.Item.CalculatePotentialValues()
+	tree.UpdateAllowedItems()
+	tree.UpdateAllowedItemSlots()
+	return tree
+}
+
+func TestCandidateTree_Export(t *testing.T) {
+	export := newExportTree().Export()
+
+	assert.Equal(t, "item-weapon", export.WeaponID)
+	assert.Equal(t, TreeSize{AllowedItems: 4, Slots: 4, Combinations: 10}, export.Size)
+	require.Len(t, export.Weapon.Slots, 2)
+
+	handguard := export.Weapon.Slots[0].AllowedItems[0]
+	require.Len(t, handguard.Slots, 2)
+	assert.Equal(t, "slot-handguard/slot-left", handguard.Slots[0].Path)
+	assert.Equal(t, "slot-handguard/slot-right", handguard.Slots[1].Path)
+	// conflicts are listed both ways round, and only with items in the tree
+	assert.Equal(t, []string{"item-stock"}, handguard.Slots[0].AllowedItems[0].ConflictingItems)
+	assert.Equal(t, []string{"item-stock"}, handguard.Slots[1].AllowedItems[0].ConflictingItems)
+	assert.Equal(t, []string{"item-cover"}, export.Weapon.Slots[1].AllowedItems[0].ConflictingItems)
+
+	buf := bytes.Buffer{}
+	require.NoError(t, newExportTree().WriteJSON(&buf))
+	decoded := TreeExport{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, export, decoded)
+}
+
+func TestCandidateTree_WriteDOT(t *testing.T) {
+	buf := bytes.Buffer{}
+	require.NoError(t, newExportTree().WriteDOT(&buf))
+	dot := buf.String()
+
+	assert.True(t, strings.HasPrefix(dot, `digraph "Weapon" {`))
+	assert.Contains(t, dot, `label="\"Best\" stock\nrecoil 0..0\nergo 0..0"`)
+	// one node per position of the rail cover, each joined to the stock it conflicts with
+	assert.Equal(t, 2, strings.Count(dot, `label="rail cover`))
+	assert.Equal(t, 2, strings.Count(dot, "color=red"))
+}
@@ -3,6 +3,7 @@ package candidate_tree
 import (
 	"fmt"
 	"github.com/rs/zerolog/log"
+	"tarkov-build-optimiser/internal/models"
 )
 
 type ConflictingItem struct {
@@ -13,19 +14,28 @@ type ConflictingItem struct {
 }
 
 type PotentialValues struct {
-	MinRecoil     int `json:"min_recoil"`
-	MaxRecoil     int `json:"max_recoil"`
-	AvgRecoil     int `json:"avg_recoil"`
-	MinErgonomics int `json:"min_ergonomics"`
-	MaxErgonomics int `json:"max_ergonomics"`
-	AvgErgonomics int `json:"avg_ergonomics"`
+	MinRecoil     models.Stat `json:"min_recoil"`
+	MaxRecoil     models.Stat `json:"max_recoil"`
+	AvgRecoil     models.Stat `json:"avg_recoil"`
+	MinErgonomics models.Stat `json:"min_ergonomics"`
+	MaxErgonomics models.Stat `json:"max_ergonomics"`
+	AvgErgonomics models.Stat `json:"avg_ergonomics"`
+	// weights in kg, leaving every slot empty that isn't required gives MinWeight
+	MinWeight float64 `json:"min_weight"`
+	MaxWeight float64 `json:"max_weight"`
+	// accuracy modifiers in percent, see Item.AccuracyModifier
+	MinAccuracy float64 `json:"min_accuracy"`
+	MaxAccuracy float64 `json:"max_accuracy"`
 }
 
 type Item struct {
 	ID                 string            `json:"item_id" bson:"id"`
 	Name               string            `json:"name" bson:"name"`
-	RecoilModifier     int               `json:"recoil_modifier" bson:"recoil_modifier"`
-	ErgonomicsModifier int               `json:"ergonomics_modifier" bson:"ergonomics_modifier"`
+	RecoilModifier     models.Stat       `json:"recoil_modifier" bson:"recoil_modifier"`
+	ErgonomicsModifier models.Stat       `json:"ergonomics_modifier" bson:"ergonomics_modifier"`
+	Weight             float64           `json:"weight" bson:"weight"`
+	AccuracyModifier   float64           `json:"accuracy_modifier" bson:"accuracy_modifier"`
+	CenterOfImpact     float64           `json:"center_of_impact" bson:"center_of_impact"`
 	Slots              []*ItemSlot       `json:"slots" bson:"slots"`
 	Type               string            `json:"type" bson:"type"`
 	ConflictingItems   []ConflictingItem `json:"conflicting_items" bson:"conflicting_items"`
@@ -35,6 +45,11 @@ type Item struct {
 	RootItem           *Item
 	Root               *CandidateTree
 	PotentialValues    PotentialValues `json:"potential_values"`
+	// PriceRub is the cheapest trader offer for the item at the tree's trader levels
+	PriceRub int `json:"price_rub"`
+	// ProvidesRequiredCategory is set when the item or one of its possible descendants is in one of the
+	// constraints' required categories, see CandidateTree.UpdateRequiredCategories
+	ProvidesRequiredCategory bool `json:"-"`
 }
 
 func ConstructItem(id string, name string, rootWeaponTree *CandidateTree) *Item {
@@ -69,6 +84,20 @@ func (item *Item) SetParentSlot(slot *ItemSlot) {
 	item.parentSlot = slot
 }
 
+// updateSlotPaths sets the paths of the item's slots and of every slot below them, parentPath being the path of the
+// slot holding the item, empty for the weapon
+func (item *Item) updateSlotPaths(parentPath string) {
+	for _, slot := range item.Slots {
+		slot.Path = slot.ID
+		if parentPath != "" {
+			slot.Path = parentPath + SlotPathSeparator + slot.ID
+		}
+		for _, allowed := range slot.AllowedItems {
+			allowed.updateSlotPaths(slot.Path)
+		}
+	}
+}
+
 func (item *Item) GetDescendantSlots() []*ItemSlot {
 	if item.Slots == nil {
 		return make([]*ItemSlot, 0)
@@ -100,6 +129,10 @@ func (item *Item) CalculatePotentialValues() {
 		MaxRecoil:     item.RecoilModifier,
 		MinErgonomics: item.ErgonomicsModifier,
 		MaxErgonomics: item.ErgonomicsModifier,
+		MinWeight:     item.Weight,
+		MaxWeight:     item.Weight,
+		MinAccuracy:   item.AccuracyModifier,
+		MaxAccuracy:   item.AccuracyModifier,
 	}
 
 	if item.Slots != nil {
@@ -110,8 +143,26 @@ func (item *Item) CalculatePotentialValues() {
 			item.PotentialValues.MaxRecoil += slot.PotentialValues.MaxRecoil
 			item.PotentialValues.MinErgonomics += slot.PotentialValues.MinErgonomics
 			item.PotentialValues.MaxErgonomics += slot.PotentialValues.MaxErgonomics
+			item.PotentialValues.MinWeight += slot.PotentialValues.MinWeight
+			item.PotentialValues.MaxWeight += slot.PotentialValues.MaxWeight
+			item.PotentialValues.MinAccuracy += slot.PotentialValues.MinAccuracy
+			item.PotentialValues.MaxAccuracy += slot.PotentialValues.MaxAccuracy
+		}
+	}
+}
+
+// updateRequiredCategories sets ProvidesRequiredCategory on the item and everything below it, returning the item's value
+func (item *Item) updateRequiredCategories(constraints models.EvaluationConstraints) bool {
+	provides := item.Type != "weapon" && constraints.IsRequiredCategory(item.CategoryID, item.CategoryName)
+	for _, slot := range item.Slots {
+		for _, allowed := range slot.AllowedItems {
+			if allowed.updateRequiredCategories(constraints) {
+				provides = true
+			}
 		}
 	}
+	item.ProvidesRequiredCategory = provides
+	return provides
 }
 
 func (item *Item) GetAncestorIds() []string {
@@ -135,6 +186,7 @@ func (item *Item) PopulateSlots() error {
 
 		s := slots[i]
 		slot := ConstructSlot(s.ID, s.Name, item.Root)
+		slot.Required = s.Required
 
 		item.AddChildSlot(slot)
 
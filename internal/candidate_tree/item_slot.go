@@ -4,11 +4,19 @@ import (
 	"errors"
 	"github.com/rs/zerolog/log"
 	"slices"
+	"tarkov-build-optimiser/internal/models"
 )
 
 type ItemSlot struct {
-	ID              string  `json:"id" bson:"id"`
-	Name            string  `json:"name" bson:"name"`
+	ID   string `json:"id" bson:"id"`
+	Name string `json:"name" bson:"name"`
+	// Path is the IDs of the slots from the weapon down to this one, joined by SlotPathSeparator. The same item, and
+	// so the same slot IDs, can be allowed in several slots, the path tells them apart. Set by
+	// CandidateTree.UpdateAllowedItemSlots.
+	Path string `json:"path"`
+	// Required slots must be filled for the item to be usable in game. Only the lightest builds honour it, every other
+	// build type only fills slots which improve it.
+	Required        bool    `json:"required"`
 	AllowedItems    []*Item `json:"-"`
 	parentItem      *Item
 	PotentialValues PotentialValues `json:"potential_values"`
@@ -98,6 +106,30 @@ func (slot *ItemSlot) SortAllowedItems(by string) {
 			} else if i.PotentialValues.MaxErgonomics > j.PotentialValues.MaxErgonomics {
 				return 1
 			}
+		case "ergonomics-max-desc":
+			if i.PotentialValues.MaxErgonomics > j.PotentialValues.MaxErgonomics {
+				return -1
+			} else if i.PotentialValues.MaxErgonomics < j.PotentialValues.MaxErgonomics {
+				return 1
+			}
+		case "accuracy-max":
+			if i.PotentialValues.MaxAccuracy < j.PotentialValues.MaxAccuracy {
+				return -1
+			} else if i.PotentialValues.MaxAccuracy > j.PotentialValues.MaxAccuracy {
+				return 1
+			}
+		case "accuracy-max-desc":
+			if i.PotentialValues.MaxAccuracy > j.PotentialValues.MaxAccuracy {
+				return -1
+			} else if i.PotentialValues.MaxAccuracy < j.PotentialValues.MaxAccuracy {
+				return 1
+			}
+		case "weight-min":
+			if i.PotentialValues.MinWeight < j.PotentialValues.MinWeight {
+				return -1
+			} else if i.PotentialValues.MinWeight > j.PotentialValues.MinWeight {
+				return 1
+			}
 		}
 		return 0
 	})
@@ -110,7 +142,7 @@ func (slot *ItemSlot) CalculatePotentialValues() {
 		return
 	}
 
-	for _, item := range slot.AllowedItems {
+	for i, item := range slot.AllowedItems {
 		item.CalculatePotentialValues()
 
 		if item.PotentialValues.MinRecoil < slot.PotentialValues.MinRecoil {
@@ -125,6 +157,19 @@ func (slot *ItemSlot) CalculatePotentialValues() {
 		if item.PotentialValues.MaxErgonomics > slot.PotentialValues.MaxErgonomics {
 			slot.PotentialValues.MaxErgonomics = item.PotentialValues.MaxErgonomics
 		}
+		// a required slot weighs at least its lightest item, other slots can be left empty
+		if slot.Required && (i == 0 || item.PotentialValues.MinWeight < slot.PotentialValues.MinWeight) {
+			slot.PotentialValues.MinWeight = item.PotentialValues.MinWeight
+		}
+		if item.PotentialValues.MaxWeight > slot.PotentialValues.MaxWeight {
+			slot.PotentialValues.MaxWeight = item.PotentialValues.MaxWeight
+		}
+		if item.PotentialValues.MinAccuracy < slot.PotentialValues.MinAccuracy {
+			slot.PotentialValues.MinAccuracy = item.PotentialValues.MinAccuracy
+		}
+		if item.PotentialValues.MaxAccuracy > slot.PotentialValues.MaxAccuracy {
+			slot.PotentialValues.MaxAccuracy = item.PotentialValues.MaxAccuracy
+		}
 	}
 }
 
@@ -160,9 +205,10 @@ func (slot *ItemSlot) pruneUselessAllowedItems() {
 	if len(slot.AllowedItems) == 0 {
 		return
 	}
+	original := slot.AllowedItems
 
 	conflictingItems := make([]*Item, 0)
-	bestNonConflictingValue := 0
+	bestNonConflictingValue := models.Stat(0)
 	var bestNonConflicting *Item
 
 	for _, item := range slot.AllowedItems {
@@ -215,6 +261,13 @@ func (slot *ItemSlot) pruneUselessAllowedItems() {
 		slot.AllowedItems = make([]*Item, 0)
 	}
 
+	// items which can supply a required category are kept whatever their value, the build may need them
+	for _, item := range original {
+		if item.ProvidesRequiredCategory && !slices.Contains(slot.AllowedItems, item) {
+			slot.AllowedItems = append(slot.AllowedItems, item)
+		}
+	}
+
 	// now we're done, ensure the best item is at the front of allowed items, incase we changed the ordering
 	slot.SortAllowedItems("recoil-min")
 }
@@ -235,6 +288,25 @@ func (slot *ItemSlot) GetAncestorItems() []*Item {
 	return append([]*Item{parentItem}, ancestorItems...)
 }
 
+// CheapestOffer returns the price of the cheapest of an item's trader offers available at the trader levels, and false
+// if none are
+func CheapestOffer(offers []models.TraderOffer, traderLevels []models.TraderLevel) (int, bool) {
+	price := 0
+	available := false
+	for _, traderLevel := range traderLevels {
+		for _, offer := range offers {
+			if traderLevel.Name == offer.Trader && traderLevel.Level >= offer.MinTraderLevel {
+				if !available || offer.PriceRub < price {
+					price = offer.PriceRub
+				}
+				available = true
+			}
+		}
+	}
+
+	return price, available
+}
+
 func (slot *ItemSlot) PopulateAllowedItems() error {
 	allowedItems, err := slot.RootWeaponTree.dataService.GetAllowedItemsBySlotID(slot.ID)
 	if err != nil {
@@ -245,25 +317,12 @@ func (slot *ItemSlot) PopulateAllowedItems() error {
 	for i := 0; i < len(allowedItems); i++ {
 		allowedItem := allowedItems[i]
 
-		traderOfferValid := false
 		offer, err := slot.RootWeaponTree.dataService.GetTraderOffer(allowedItem.ID)
 		if err != nil {
 			log.Error().Err(err).Msgf("Failed to get trader offer for item %s", allowedItem.ID)
 			return err
 		}
-		for _, traderConstraint := range slot.RootWeaponTree.Constraints.TraderLevels {
-			for _, t := range offer {
-				if traderConstraint.Name == t.Trader {
-					if traderConstraint.Level >= t.MinTraderLevel {
-						traderOfferValid = true
-						break
-					}
-				}
-			}
-			if traderOfferValid {
-				break
-			}
-		}
+		price, traderOfferValid := CheapestOffer(offer, slot.RootWeaponTree.Constraints.TraderLevels)
 
 		if !traderOfferValid {
 			//log.Info().Msgf("item %s does not meet trader level constraints - not adding", allowedItem.ID)
@@ -291,11 +350,19 @@ func (slot *ItemSlot) PopulateAllowedItems() error {
 			continue
 		}
 
+		if slot.RootWeaponTree.Constraints.ExcludesCategory(modProperties.CategoryID, modProperties.CategoryName) {
+			continue
+		}
+
 		item := ConstructItem(allowedItem.ID, allowedItem.Name, slot.RootWeaponTree)
 		item.RecoilModifier = modProperties.RecoilModifier
 		item.ErgonomicsModifier = modProperties.ErgonomicsModifier
+		item.Weight = modProperties.Weight
+		item.CenterOfImpact = modProperties.CenterOfImpact
+		item.AccuracyModifier = modProperties.AccuracyModifier + BarrelAccuracyModifier(slot.RootWeaponTree.Item.CenterOfImpact, modProperties.CenterOfImpact)
 		item.CategoryID = modProperties.CategoryID
 		item.CategoryName = modProperties.CategoryName
+		item.PriceRub = price
 		item.Type = "weapon_mod"
 		item.ConflictingItems = make([]ConflictingItem, 0)
 
@@ -2,6 +2,7 @@ package candidate_tree
 
 import (
 	"github.com/stretchr/testify/assert"
+	"tarkov-build-optimiser/internal/models"
 	"testing"
 )
 
@@ -72,3 +73,45 @@ func TestSlot_GetAncestorItems(t *testing.T) {
 	assert.Equal(t, ancestors[2], item1)
 	assert.Equal(t, len(ancestors), 3)
 }
+
+func TestSlot_PruneUselessAllowedItems_KeepsRequiredCategories(t *testing.T) {
+	best := &Item{ID: "best", PotentialValues: PotentialValues{MinRecoil: -10}}
+	worse := &Item{ID: "worse", PotentialValues: PotentialValues{MinRecoil: -2}, ProvidesRequiredCategory: true}
+	useless := &Item{ID: "useless", PotentialValues: PotentialValues{MinRecoil: 3}}
+	slot := &ItemSlot{ID: "slot", AllowedItems: []*Item{best, worse, useless}}
+
+	slot.pruneUselessAllowedItems()
+
+	assert.Equal(t, []*Item{best, worse}, slot.AllowedItems)
+}
+
+func TestCheapestOffer(t *testing.T) {
+	offers := []models.TraderOffer{
+		{Trader: "Prapor", MinTraderLevel: 1, PriceRub: 5000},
+		{Trader: "Mechanic", MinTraderLevel: 3, PriceRub: 3000},
+	}
+
+	price, ok := CheapestOffer(offers, []models.TraderLevel{{Name: "Prapor", Level: 1}, {Name: "Mechanic", Level: 2}})
+	assert.True(t, ok)
+	assert.Equal(t, 5000, price)
+
+	price, ok = CheapestOffer(offers, []models.TraderLevel{{Name: "Prapor", Level: 1}, {Name: "Mechanic", Level: 3}})
+	assert.True(t, ok)
+	assert.Equal(t, 3000, price)
+
+	_, ok = CheapestOffer(offers, []models.TraderLevel{{Name: "Mechanic", Level: 2}})
+	assert.False(t, ok)
+}
+
+func TestSlot_SortAllowedItems_BestFirst(t *testing.T) {
+	low := &Item{ID: "low", PotentialValues: PotentialValues{MaxErgonomics: models.NewStat(2), MinRecoil: models.NewStat(-1)}}
+	high := &Item{ID: "high", PotentialValues: PotentialValues{MaxErgonomics: models.NewStat(8), MinRecoil: models.NewStat(-5)}}
+	slot := &ItemSlot{ID: "slot", AllowedItems: []*Item{low, high}}
+
+	slot.SortAllowedItems(BestFirstSort("ergonomics"))
+	assert.Equal(t, []*Item{high, low}, slot.AllowedItems, "expected the most ergonomic item first")
+
+	slot.AllowedItems = []*Item{low, high}
+	slot.SortAllowedItems(BestFirstSort("recoil"))
+	assert.Equal(t, []*Item{high, low}, slot.AllowedItems, "expected the item with the least recoil first")
+}
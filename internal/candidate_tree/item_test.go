@@ -23,3 +23,16 @@ func TestItem_GetParentSlot_WithParent(t *testing.T) {
 	assert.NotNil(t, parentSlot)
 	assert.Equal(t, parentSlot, slot)
 }
+
+func TestItem_CalculatePotentialValues_Weight(t *testing.T) {
+	heavy := &Item{ID: "heavy", Weight: 0.5, Slots: []*ItemSlot{{ID: "nested", AllowedItems: []*Item{{ID: "scope", Weight: 0.25}}}}}
+	light := &Item{ID: "light", Weight: 0.1}
+	weapon := &Item{ID: "weapon", Weight: 3, Slots: []*ItemSlot{{ID: "slot", AllowedItems: []*Item{heavy, light}}}}
+
+	weapon.CalculatePotentialValues()
+
+	// the slot may be left empty, so only the weapon's own weight is certain
+	assert.InDelta(t, 3.0, weapon.PotentialValues.MinWeight, 1e-9)
+	assert.InDelta(t, 3.75, weapon.PotentialValues.MaxWeight, 1e-9)
+	assert.InDelta(t, 0.5, heavy.PotentialValues.MinWeight, 1e-9)
+}
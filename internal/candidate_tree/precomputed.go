@@ -9,8 +9,8 @@ import (
 type PrecomputedSubtreeInfo struct {
 	RootItemID     string
 	EvaluationType string
-	RecoilSum      int
-	ErgonomicsSum  int
+	RecoilSum      models.Stat
+	ErgonomicsSum  models.Stat
 	IsDefinitive   bool
 }
 
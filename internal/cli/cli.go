@@ -4,21 +4,31 @@ import (
 	"os"
 	"strings"
 	"tarkov-build-optimiser/internal/helpers"
+	"time"
 
 	"github.com/rs/zerolog"
 )
 
+// Flags are the importer's command line switches. The evaluator parses its own arguments, see ParseEvaluatorArgs.
 type Flags struct {
-	PurgeCache         bool
-	UseCache           bool
-	CacheOnly          bool
-	PurgeOptimumBuilds bool
-	Fresh              bool
-	TestRun            bool
-	UseDatabaseCache   bool
-	LogLevel           string
+	PurgeCache bool
+	UseCache   bool
+	CacheOnly  bool
+	LogLevel   string
 }
 
+// DefaultCacheSize is the number of entries the tiered cache holds in memory when --cache-size isn't provided
+const DefaultCacheSize = 200000
+
+// DefaultMaxAttempts is how many times a build is evaluated before it's left Failed when --max-attempts isn't provided
+const DefaultMaxAttempts = 3
+
+// DefaultRetryBackoff is the wait before a Failed build is first retried when --retry-backoff isn't provided
+const DefaultRetryBackoff = time.Minute
+
+// DefaultShutdownTimeout is how long the evaluator waits for in-flight builds when --shutdown-timeout isn't provided
+const DefaultShutdownTimeout = 30 * time.Second
+
 func GetFlags() Flags {
 	flags := Flags{}
 	if helpers.ContainsStr(os.Args, "--purge-cache") {
@@ -30,19 +40,6 @@ func GetFlags() Flags {
 	if helpers.ContainsStr(os.Args, "--cache-only") {
 		flags.CacheOnly = true
 	}
-	if helpers.ContainsStr(os.Args, "--purge-optimum-builds") {
-		flags.PurgeOptimumBuilds = true
-	}
-	if helpers.ContainsStr(os.Args, "--fresh") {
-		flags.Fresh = true
-	}
-	if helpers.ContainsStr(os.Args, "--test-run") {
-		// makes evaluator only use a few weapons for testing
-		flags.TestRun = true
-	}
-	if helpers.ContainsStr(os.Args, "--use-database-cache") {
-		flags.UseDatabaseCache = true
-	}
 
 	// Parse log level from --log-level flag
 	flags.LogLevel = parseLogLevel()
@@ -0,0 +1,373 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"tarkov-build-optimiser/internal/models"
+	"time"
+)
+
+// Evaluator subcommands
+const (
+	CommandEvaluate = "evaluate"
+	CommandStatus   = "status"
+	CommandRequeue  = "requeue"
+	CommandPurge    = "purge"
+	CommandSolve    = "solve"
+	CommandExport   = "export-tree"
+)
+
+const evaluatorUsage = `Usage: evaluator <command> [flags]
+
+Commands:
+  evaluate     Queue and evaluate optimum builds (default when no command is given)
+  status       Show build progress, or failed builds with --failed
+  requeue      Move builds back to Pending so they are evaluated again
+  purge        Delete optimum builds
+  solve        Find builds meeting gunsmith task requirements
+  export-tree  Write a weapon's candidate tree as Graphviz DOT or JSON
+
+Run 'evaluator <command> -h' for the flags of a command.
+`
+
+// ErrHelp is returned by ParseEvaluatorArgs when help was requested and has been written
+var ErrHelp = flag.ErrHelp
+
+// Selector narrows the weapons, build types and trader levels a command works on
+type Selector struct {
+	// weapon IDs or names, resolved against the database by the caller
+	Weapons     []string
+	WeaponsFile string
+	BuildType   string
+	// each entry is a full set of trader levels given as --trader-levels 4,4,2,3,1 in models.TraderNames order
+	TraderLevels [][]models.TraderLevel
+	// Profile is the name of the constraint profile builds are evaluated with, see models.ConstraintProfile
+	Profile string
+	// IgnoredSlots are slot names left empty on top of the profile's, see models.ConstraintProfile.WithIgnoredSlots
+	IgnoredSlots []string
+	// Shard limits the work to one deterministic slice of the (weapon, trader levels) space
+	Shard models.Shard
+}
+
+// EvaluatorCommand is the parsed evaluator command line
+type EvaluatorCommand struct {
+	Name     string
+	Selector Selector
+	LogLevel string
+
+	// evaluate
+	Fresh            bool
+	UseDatabaseCache bool
+	UseTieredCache   bool
+	CacheSize        int
+	CacheSnapshot    string
+	ShutdownTimeout  time.Duration
+	MaxAttempts      int
+	RetryBackoff     time.Duration
+	// Watch keeps the evaluator running once the queue is drained, evaluating builds requested through the API
+	Watch bool
+	// DryRun builds candidate trees and estimates their cost instead of evaluating them
+	DryRun bool
+	// Format is the dry-run output, one of the Format constants
+	Format string
+	// SortBy orders dry-run output, one of the Sort constants
+	SortBy string
+	// TraceDir is where the search of every build is traced to, empty to not trace
+	TraceDir string
+	// ClaimAnyBuildType and ClaimAnyProfile are set when the build type or profile was defaulted rather than given, so
+	// workers also claim builds of other types and profiles, such as those requested through the API
+	ClaimAnyBuildType bool
+	ClaimAnyProfile   bool
+
+	// status
+	ShowFailed bool
+	// ShardCount breaks status down by shard when work is split into this many shards
+	ShardCount int
+
+	// requeue
+	Statuses []string
+
+	// purge
+	PurgeCache bool
+
+	// solve
+	TasksFile string
+	// TaskIDs limits solving to these tasks of TasksFile
+	TaskIDs []string
+	// SolveTimeout bounds the search for each task, 0 for no limit
+	SolveTimeout time.Duration
+
+	// export-tree
+	// Output is the file the tree is written to, empty for stdout
+	Output string
+}
+
+// DefaultSolveTimeout is how long solve searches each task by default
+const DefaultSolveTimeout = 5 * time.Minute
+
+// Dry-run and solve output formats
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	// FormatDOT is only supported by export-tree
+	FormatDOT = "dot"
+)
+
+// Dry-run sort orders, every order but SortWeapon puts the largest first
+const (
+	SortCombinations = "combinations"
+	SortItems        = "items"
+	SortPredicted    = "predicted"
+	SortConflicts    = "conflicts"
+	SortWeapon       = "weapon"
+)
+
+// ParseEvaluatorArgs parses the evaluator's arguments (without the program name). Unknown commands and flags are
+// errors, and usage is written to output. ErrHelp is returned after writing help for -h.
+func ParseEvaluatorArgs(args []string, output io.Writer) (EvaluatorCommand, error) {
+	cmd := EvaluatorCommand{Name: CommandEvaluate}
+
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		cmd.Name = args[0]
+		args = args[1:]
+	} else if len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+		fmt.Fprint(output, evaluatorUsage)
+		return cmd, ErrHelp
+	}
+
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(output)
+	bindSelectorFlags(fs, &cmd.Selector)
+	fs.StringVar(&cmd.LogLevel, "log-level", "info", "log level: trace, debug, info, warn, error")
+
+	switch cmd.Name {
+	case CommandEvaluate:
+		fs.BoolVar(&cmd.Fresh, "fresh", false, "purge all optimum builds before evaluating")
+		fs.BoolVar(&cmd.UseDatabaseCache, "use-database-cache", false, "persist the conflict-free cache to the database")
+		fs.BoolVar(&cmd.UseTieredCache, "use-tiered-cache", false, "use a bounded in-memory LRU in front of the database cache")
+		fs.IntVar(&cmd.CacheSize, "cache-size", DefaultCacheSize, "entries held in memory by the tiered cache")
+		fs.StringVar(&cmd.CacheSnapshot, "cache-snapshot", "", "file the memory cache is loaded from at startup and saved to on exit")
+		fs.DurationVar(&cmd.ShutdownTimeout, "shutdown-timeout", DefaultShutdownTimeout, "time in-flight builds get to finish after SIGINT/SIGTERM")
+		fs.IntVar(&cmd.MaxAttempts, "max-attempts", DefaultMaxAttempts, "times a build is evaluated before it is left Failed")
+		fs.DurationVar(&cmd.RetryBackoff, "retry-backoff", DefaultRetryBackoff, "wait before the first retry of a failed build, doubled for each further attempt")
+		fs.BoolVar(&cmd.Watch, "watch", false, "keep running once the queue is drained and evaluate builds requested through the API")
+		fs.BoolVar(&cmd.DryRun, "dry-run", false, "build every candidate tree and estimate its search space without evaluating")
+		fs.StringVar(&cmd.Format, "format", FormatTable, "dry-run output: table or json")
+		fs.StringVar(&cmd.SortBy, "sort", SortCombinations, "dry-run order: combinations, items, predicted, conflicts or weapon")
+		fs.StringVar(&cmd.TraceDir, "trace-dir", "", "directory every build's search is traced to, read traces with the tracer command")
+	case CommandStatus:
+		fs.BoolVar(&cmd.ShowFailed, "failed", false, "list failed builds and why they failed")
+		fs.IntVar(&cmd.ShardCount, "shards", 0, "show progress for each of this many shards")
+	case CommandRequeue:
+		fs.Var((*stringList)(&cmd.Statuses), "status", "comma-separated statuses to requeue: Failed, Completed, Pending (default Failed)")
+	case CommandPurge:
+		fs.BoolVar(&cmd.PurgeCache, "cache", false, "also purge the conflict-free cache")
+	case CommandSolve:
+		fs.StringVar(&cmd.TasksFile, "tasks", "", "JSON file of gunsmith tasks")
+		fs.Var((*stringList)(&cmd.TaskIDs), "task", "task ID to solve, repeatable or comma-separated. Defaults to every task")
+		fs.DurationVar(&cmd.SolveTimeout, "timeout", DefaultSolveTimeout, "time spent searching each task, 0 for no limit")
+		fs.StringVar(&cmd.Format, "format", FormatTable, "output: table or json")
+	case CommandExport:
+		fs.StringVar(&cmd.Format, "format", FormatDOT, "output: dot or json")
+		fs.StringVar(&cmd.Output, "output", "", "file the tree is written to, defaults to stdout")
+	default:
+		fmt.Fprint(output, evaluatorUsage)
+		return cmd, fmt.Errorf("unknown command %q", cmd.Name)
+	}
+
+	fs.Usage = func() {
+		fmt.Fprintf(output, "Usage: evaluator %s [flags]\n\nFlags:\n", cmd.Name)
+		fs.PrintDefaults()
+	}
+
+	err := fs.Parse(args)
+	if err != nil {
+		return cmd, err
+	}
+	if fs.NArg() > 0 {
+		fs.Usage()
+		return cmd, fmt.Errorf("unexpected argument %q", fs.Arg(0))
+	}
+
+	return cmd, validateEvaluatorCommand(&cmd)
+}
+
+func validateEvaluatorCommand(cmd *EvaluatorCommand) error {
+	if cmd.Name == CommandEvaluate {
+		if cmd.CacheSize < 1 {
+			return errors.New("--cache-size must be at least 1")
+		}
+		if cmd.MaxAttempts < 1 {
+			return errors.New("--max-attempts must be at least 1")
+		}
+		if cmd.UseDatabaseCache && cmd.UseTieredCache {
+			return errors.New("--use-database-cache and --use-tiered-cache can't be combined")
+		}
+		if cmd.Selector.BuildType == "" {
+			cmd.Selector.BuildType = "recoil"
+			cmd.ClaimAnyBuildType = true
+		}
+		if cmd.Selector.Profile == "" {
+			cmd.Selector.Profile = models.DefaultProfileName
+			cmd.ClaimAnyProfile = true
+		}
+		switch cmd.Format {
+		case FormatTable, FormatJSON:
+		default:
+			return fmt.Errorf("unknown --format %q", cmd.Format)
+		}
+		switch cmd.SortBy {
+		case SortCombinations, SortItems, SortPredicted, SortConflicts, SortWeapon:
+		default:
+			return fmt.Errorf("unknown --sort %q", cmd.SortBy)
+		}
+	}
+	if cmd.Name == CommandSolve {
+		if cmd.TasksFile == "" {
+			return errors.New("--tasks is required")
+		}
+		if cmd.SolveTimeout < 0 {
+			return errors.New("--timeout can't be negative")
+		}
+		switch cmd.Format {
+		case FormatTable, FormatJSON:
+		default:
+			return fmt.Errorf("unknown --format %q", cmd.Format)
+		}
+	}
+	if cmd.Name == CommandExport {
+		if len(cmd.Selector.Weapons) != 1 {
+			return errors.New("export-tree needs exactly one --weapon")
+		}
+		if len(cmd.Selector.TraderLevels) > 1 {
+			return errors.New("export-tree takes at most one --trader-levels")
+		}
+		if cmd.Selector.BuildType == "" {
+			cmd.Selector.BuildType = "recoil"
+		}
+		if cmd.Selector.Profile == "" {
+			cmd.Selector.Profile = models.DefaultProfileName
+		}
+		switch cmd.Format {
+		case FormatDOT, FormatJSON:
+		default:
+			return fmt.Errorf("unknown --format %q", cmd.Format)
+		}
+	}
+	if cmd.Selector.BuildType != "" && !models.IsValidBuildType(cmd.Selector.BuildType) {
+		return fmt.Errorf("unknown --build-type %q", cmd.Selector.BuildType)
+	}
+	if cmd.ShardCount < 0 {
+		return errors.New("--shards can't be negative")
+	}
+	if cmd.Name == CommandRequeue && len(cmd.Statuses) == 0 {
+		cmd.Statuses = []string{models.EvaluationFailed.ToString()}
+	}
+	for _, status := range cmd.Statuses {
+		switch status {
+		case models.EvaluationFailed.ToString(), models.EvaluationCompleted.ToString(), models.EvaluationPending.ToString():
+		default:
+			return fmt.Errorf("can't requeue builds with status %q", status)
+		}
+	}
+	return nil
+}
+
+func bindSelectorFlags(fs *flag.FlagSet, selector *Selector) {
+	fs.Var((*stringList)(&selector.Weapons), "weapon", "weapon ID or name, repeatable or comma-separated")
+	fs.StringVar(&selector.WeaponsFile, "weapons-file", "", "file of weapon IDs or names, one per line")
+	fs.StringVar(&selector.BuildType, "build-type", "", "build type, e.g. recoil")
+	fs.Var((*traderLevelsList)(&selector.TraderLevels), "trader-levels", "trader levels as "+strings.Join(models.TraderNames, ",")+", e.g. 4,4,2,3,1. Repeatable")
+	fs.StringVar(&selector.Profile, "profile", "", "constraint profile, e.g. iron-sights. Defaults to "+models.DefaultProfileName+" for evaluate")
+	fs.Var((*stringList)(&selector.IgnoredSlots), "ignored-slots", "comma-separated slot names to leave empty on top of the profile's, e.g. Mount")
+	fs.Var((*shardFlag)(&selector.Shard), "shard", "only work on shard index/count of the builds, e.g. 0/4")
+}
+
+// shardFlag is a flag accepting a shard as index/count
+type shardFlag models.Shard
+
+func (f *shardFlag) String() string {
+	if f == nil || !models.Shard(*f).IsSet() {
+		return ""
+	}
+	return models.Shard(*f).String()
+}
+
+func (f *shardFlag) Set(value string) error {
+	shard, err := models.ParseShard(value)
+	if err != nil {
+		return err
+	}
+	*f = shardFlag(shard)
+	return nil
+}
+
+// stringList is a flag accepting comma-separated values, which may also be repeated
+type stringList []string
+
+func (l *stringList) String() string {
+	if l == nil {
+		return ""
+	}
+	return strings.Join(*l, ",")
+}
+
+func (l *stringList) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			*l = append(*l, v)
+		}
+	}
+	return nil
+}
+
+// traderLevelsList is a repeatable flag of trader levels in models.TraderNames order
+type traderLevelsList [][]models.TraderLevel
+
+func (l *traderLevelsList) String() string {
+	if l == nil {
+		return ""
+	}
+	sets := make([]string, 0, len(*l))
+	for _, levels := range *l {
+		values := make([]string, 0, len(levels))
+		for _, level := range levels {
+			values = append(values, strconv.Itoa(level.Level))
+		}
+		sets = append(sets, strings.Join(values, ","))
+	}
+	return strings.Join(sets, " ")
+}
+
+func (l *traderLevelsList) Set(value string) error {
+	levels, err := ParseTraderLevels(value)
+	if err != nil {
+		return err
+	}
+	*l = append(*l, levels)
+	return nil
+}
+
+// ParseTraderLevels parses levels such as 4,4,2,3,1 given in models.TraderNames order
+func ParseTraderLevels(value string) ([]models.TraderLevel, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) != len(models.TraderNames) {
+		return nil, fmt.Errorf("expected %d trader levels (%s), got %q", len(models.TraderNames), strings.Join(models.TraderNames, ","), value)
+	}
+
+	levels := make([]models.TraderLevel, 0, len(parts))
+	for i, part := range parts {
+		level, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || level < 1 || level > 4 {
+			return nil, fmt.Errorf("invalid level %q for trader %s", part, models.TraderNames[i])
+		}
+		levels = append(levels, models.TraderLevel{Name: models.TraderNames[i], Level: level})
+	}
+
+	return levels, nil
+}
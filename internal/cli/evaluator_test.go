@@ -0,0 +1,154 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"tarkov-build-optimiser/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEvaluatorArgs_Evaluate(t *testing.T) {
+	out := bytes.Buffer{}
+	cmd, err := ParseEvaluatorArgs([]string{
+		"evaluate",
+		"--weapon", "5447a9cd4bdc2dbd208b4567",
+		"--weapon=AK-74N,SA-58",
+		"--trader-levels", "4,4,2,3,1",
+		"--trader-levels=1,1,1,1,1",
+		"--profile", "iron-sights",
+		"--ignored-slots", "Pistol Grip,Foregrip",
+		"--use-tiered-cache",
+		"--shutdown-timeout", "1m",
+		"--shard", "1/4",
+	}, &out)
+	require.NoError(t, err)
+
+	assert.Equal(t, CommandEvaluate, cmd.Name)
+	assert.Equal(t, []string{"5447a9cd4bdc2dbd208b4567", "AK-74N", "SA-58"}, cmd.Selector.Weapons)
+	assert.Equal(t, "recoil", cmd.Selector.BuildType)
+	assert.Equal(t, "iron-sights", cmd.Selector.Profile)
+	assert.Equal(t, []string{"Pistol Grip", "Foregrip"}, cmd.Selector.IgnoredSlots)
+	assert.True(t, cmd.ClaimAnyBuildType, "expected the defaulted build type not to limit claiming")
+	assert.False(t, cmd.ClaimAnyProfile)
+	require.Len(t, cmd.Selector.TraderLevels, 2)
+	assert.Equal(t, []models.TraderLevel{
+		{Name: "Jaeger", Level: 4},
+		{Name: "Prapor", Level: 4},
+		{Name: "Peacekeeper", Level: 2},
+		{Name: "Mechanic", Level: 3},
+		{Name: "Skier", Level: 1},
+	}, cmd.Selector.TraderLevels[0])
+	assert.True(t, cmd.UseTieredCache)
+	assert.Equal(t, DefaultCacheSize, cmd.CacheSize)
+	assert.Equal(t, time.Minute, cmd.ShutdownTimeout)
+	assert.Equal(t, models.Shard{Index: 1, Count: 4}, cmd.Selector.Shard)
+}
+
+func TestParseEvaluatorArgs_DefaultsToEvaluate(t *testing.T) {
+	cmd, err := ParseEvaluatorArgs([]string{"--use-database-cache"}, &bytes.Buffer{})
+	require.NoError(t, err)
+
+	assert.Equal(t, CommandEvaluate, cmd.Name)
+	assert.True(t, cmd.UseDatabaseCache)
+	assert.Equal(t, models.DefaultProfileName, cmd.Selector.Profile)
+}
+
+func TestParseEvaluatorArgs_Requeue(t *testing.T) {
+	cmd, err := ParseEvaluatorArgs([]string{"requeue", "--weapon", "AK-74N"}, &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Failed"}, cmd.Statuses)
+	assert.Equal(t, "", cmd.Selector.BuildType)
+	assert.Equal(t, "", cmd.Selector.Profile, "expected requeue to match every profile by default")
+
+	cmd, err = ParseEvaluatorArgs([]string{"requeue", "--status", "Failed,Completed"}, &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Failed", "Completed"}, cmd.Statuses)
+
+	_, err = ParseEvaluatorArgs([]string{"requeue", "--status", "InProgress"}, &bytes.Buffer{})
+	assert.Error(t, err)
+}
+
+func TestParseEvaluatorArgs_Solve(t *testing.T) {
+	cmd, err := ParseEvaluatorArgs([]string{
+		"solve",
+		"--tasks", "tasks.json",
+		"--task", "gunsmith-1,gunsmith-2",
+		"--trader-levels", "1,1,1,1,1",
+		"--format", "json",
+	}, &bytes.Buffer{})
+	require.NoError(t, err)
+
+	assert.Equal(t, CommandSolve, cmd.Name)
+	assert.Equal(t, "tasks.json", cmd.TasksFile)
+	assert.Equal(t, []string{"gunsmith-1", "gunsmith-2"}, cmd.TaskIDs)
+	assert.Equal(t, DefaultSolveTimeout, cmd.SolveTimeout)
+	assert.Equal(t, FormatJSON, cmd.Format)
+	assert.Equal(t, "", cmd.Selector.Profile, "expected tasks to be solved without a profile by default")
+	require.Len(t, cmd.Selector.TraderLevels, 1)
+}
+
+func TestParseEvaluatorArgs_Errors(t *testing.T) {
+	tests := map[string][]string{
+		"unknown command":         {"run"},
+		"unknown flag":            {"evaluate", "--test-run"},
+		"flag of another command": {"status", "--fresh"},
+		"too few trader levels":   {"evaluate", "--trader-levels", "4,4,4"},
+		"trader level too high":   {"evaluate", "--trader-levels", "5,4,4,4,4"},
+		"conflicting caches":      {"evaluate", "--use-database-cache", "--use-tiered-cache"},
+		"positional argument":     {"status", "extra"},
+		"shard out of range":      {"evaluate", "--shard", "4/4"},
+		"unknown format":          {"evaluate", "--dry-run", "--format", "csv"},
+		"unknown sort":            {"evaluate", "--dry-run", "--sort", "name"},
+		"solve without tasks":     {"solve", "--task", "gunsmith-1"},
+		"negative solve timeout":  {"solve", "--tasks", "tasks.json", "--timeout", "-1s"},
+		"unknown build type":      {"evaluate", "--build-type", "recoi"},
+		"unknown export type":     {"export-tree", "--weapon", "ak-74", "--build-type", "speed"},
+		"unknown status type":     {"status", "--build-type", "Recoil"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseEvaluatorArgs(args, &bytes.Buffer{})
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestParseEvaluatorArgs_Help(t *testing.T) {
+	out := bytes.Buffer{}
+	_, err := ParseEvaluatorArgs([]string{"--help"}, &out)
+	assert.ErrorIs(t, err, ErrHelp)
+	assert.Contains(t, out.String(), "Commands:")
+
+	out.Reset()
+	_, err = ParseEvaluatorArgs([]string{"status", "-h"}, &out)
+	assert.ErrorIs(t, err, ErrHelp)
+	assert.Contains(t, out.String(), "-failed")
+}
+
+func TestParseEvaluatorArgs_ExportTree(t *testing.T) {
+	cmd, err := ParseEvaluatorArgs([]string{"export-tree", "--weapon", "AK-74N", "--format", "json", "--output", "tree.json"}, &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Equal(t, CommandExport, cmd.Name)
+	assert.Equal(t, FormatJSON, cmd.Format)
+	assert.Equal(t, "tree.json", cmd.Output)
+	assert.Equal(t, "recoil", cmd.Selector.BuildType)
+	assert.Equal(t, models.DefaultProfileName, cmd.Selector.Profile)
+
+	cmd, err = ParseEvaluatorArgs([]string{"export-tree", "--weapon", "AK-74N"}, &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Equal(t, FormatDOT, cmd.Format)
+
+	_, err = ParseEvaluatorArgs([]string{"export-tree"}, &bytes.Buffer{})
+	assert.Error(t, err, "expected a weapon to be required")
+
+	_, err = ParseEvaluatorArgs([]string{"export-tree", "--weapon", "AK-74N,SA-58"}, &bytes.Buffer{})
+	assert.Error(t, err)
+
+	_, err = ParseEvaluatorArgs([]string{"export-tree", "--weapon", "AK-74N", "--format", "table"}, &bytes.Buffer{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Tracer subcommands
+const (
+	CommandReplay  = "replay"
+	CommandSummary = "summary"
+	CommandFilter  = "filter"
+)
+
+const tracerUsage = `Usage: tracer <command> [flags] <trace file>
+
+Commands:
+  replay    Print every decision of the search, indented by depth
+  summary   Count the decisions, and the slots and items the search spent longest on
+  filter    Print the decisions on a slot or item
+
+Traces are written by 'evaluator evaluate --trace-dir <dir>'.
+Run 'tracer <command> -h' for the flags of a command.
+`
+
+// TracerCommand is the parsed tracer command line
+type TracerCommand struct {
+	Name string
+	File string
+	// SlotID and ItemID limit the events replayed or filtered, either may be empty
+	SlotID string
+	ItemID string
+	// Limit is the number of slots and items summarised, 0 for all
+	Limit int
+}
+
+// DefaultSummaryLimit is the number of slots and items summary lists when --limit isn't provided
+const DefaultSummaryLimit = 20
+
+// ParseTracerArgs parses the tracer's arguments (without the program name). ErrHelp is returned after writing help
+// for -h.
+func ParseTracerArgs(args []string, output io.Writer) (TracerCommand, error) {
+	cmd := TracerCommand{}
+
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		fmt.Fprint(output, tracerUsage)
+		if len(args) > 0 && (args[0] == "-h" || args[0] == "-help" || args[0] == "--help") {
+			return cmd, ErrHelp
+		}
+		return cmd, errors.New("a command is required")
+	}
+	cmd.Name = args[0]
+	args = args[1:]
+
+	fs := flag.NewFlagSet(cmd.Name, flag.ContinueOnError)
+	fs.SetOutput(output)
+
+	switch cmd.Name {
+	case CommandReplay, CommandFilter:
+		fs.StringVar(&cmd.SlotID, "slot", "", "only decisions on this slot ID")
+		fs.StringVar(&cmd.ItemID, "item", "", "only decisions on this item ID")
+	case CommandSummary:
+		fs.IntVar(&cmd.Limit, "limit", DefaultSummaryLimit, "slots and items listed, 0 for all")
+	default:
+		fmt.Fprint(output, tracerUsage)
+		return cmd, fmt.Errorf("unknown command %q", cmd.Name)
+	}
+
+	fs.Usage = func() {
+		fmt.Fprintf(output, "Usage: tracer %s [flags] <trace file>\n\nFlags:\n", cmd.Name)
+		fs.PrintDefaults()
+	}
+
+	err := fs.Parse(args)
+	if err != nil {
+		return cmd, err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return cmd, errors.New("a trace file is required")
+	}
+	if fs.NArg() > 1 {
+		fs.Usage()
+		return cmd, fmt.Errorf("unexpected argument %q", fs.Arg(1))
+	}
+	cmd.File = fs.Arg(0)
+
+	if cmd.Name == CommandFilter && cmd.SlotID == "" && cmd.ItemID == "" {
+		return cmd, errors.New("--slot or --item is required")
+	}
+	if cmd.Limit < 0 {
+		return cmd, errors.New("--limit can't be negative")
+	}
+
+	return cmd, nil
+}
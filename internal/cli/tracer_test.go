@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTracerArgs_Filter(t *testing.T) {
+	cmd, err := ParseTracerArgs([]string{"filter", "--slot", "slot-muzzle", "--item=item-brake", "build.trace"}, &bytes.Buffer{})
+	require.NoError(t, err)
+
+	assert.Equal(t, CommandFilter, cmd.Name)
+	assert.Equal(t, "slot-muzzle", cmd.SlotID)
+	assert.Equal(t, "item-brake", cmd.ItemID)
+	assert.Equal(t, "build.trace", cmd.File)
+
+	_, err = ParseTracerArgs([]string{"filter", "build.trace"}, &bytes.Buffer{})
+	assert.Error(t, err, "expected filter to require a slot or item")
+}
+
+func TestParseTracerArgs_Summary(t *testing.T) {
+	cmd, err := ParseTracerArgs([]string{"summary", "build.trace"}, &bytes.Buffer{})
+	require.NoError(t, err)
+	assert.Equal(t, CommandSummary, cmd.Name)
+	assert.Equal(t, DefaultSummaryLimit, cmd.Limit)
+
+	_, err = ParseTracerArgs([]string{"summary", "--limit", "-1", "build.trace"}, &bytes.Buffer{})
+	assert.Error(t, err)
+}
+
+func TestParseTracerArgs_Errors(t *testing.T) {
+	_, err := ParseTracerArgs([]string{}, &bytes.Buffer{})
+	assert.Error(t, err)
+
+	_, err = ParseTracerArgs([]string{"replay"}, &bytes.Buffer{})
+	assert.Error(t, err, "expected a trace file to be required")
+
+	_, err = ParseTracerArgs([]string{"replay", "a.trace", "b.trace"}, &bytes.Buffer{})
+	assert.Error(t, err)
+
+	_, err = ParseTracerArgs([]string{"unknown", "a.trace"}, &bytes.Buffer{})
+	assert.Error(t, err)
+
+	out := bytes.Buffer{}
+	_, err = ParseTracerArgs([]string{"-h"}, &out)
+	assert.ErrorIs(t, err, ErrHelp)
+	assert.Contains(t, out.String(), "replay")
+}
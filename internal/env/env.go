@@ -24,6 +24,12 @@ type Env struct {
 	EvaluatorPoolSizeFactor int
 	// EvaluatorFresh when true causes optimum builds to be purged before evaluation (same as --fresh)
 	EvaluatorFresh bool
+	// OptimiseMaxConcurrent is the number of live optimisations the API runs at once, 0 means one per CPU core
+	OptimiseMaxConcurrent int
+	// OptimiseMaxQueued is the number of live optimisations allowed to wait for a free slot
+	OptimiseMaxQueued int
+	// OptimiseTimeoutSeconds bounds each live optimisation
+	OptimiseTimeoutSeconds int
 }
 
 var (
@@ -33,6 +39,10 @@ var (
 )
 
 func getInt(key string, def int) int {
+	if os.Getenv(key) == "" {
+		return def
+	}
+
 	value, err := strconv.Atoi(os.Getenv(key))
 	if err != nil {
 		log.Warn().Err(err).Msgf("Failed to convert env var %s to integer", key)
@@ -67,6 +77,9 @@ func load() {
 		Environment:             os.Getenv("ENVIRONMENT"),
 		EvaluatorPoolSizeFactor: getInt("POOL_SIZE_MULTIPLIER", 2),
 		EvaluatorFresh:          getBoolTruthy("EVALUATOR_FRESH"),
+		OptimiseMaxConcurrent:   getInt("OPTIMISE_MAX_CONCURRENT", 0),
+		OptimiseMaxQueued:       getInt("OPTIMISE_MAX_QUEUED", 16),
+		OptimiseTimeoutSeconds:  getInt("OPTIMISE_TIMEOUT_SECONDS", 30),
 	}
 
 	log.Debug().
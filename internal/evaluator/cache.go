@@ -10,8 +10,8 @@ import (
 
 // CacheEntry represents a cached conflict-free item result
 type CacheEntry struct {
-	RecoilSum     int `json:"recoil_sum"`
-	ErgonomicsSum int `json:"ergonomics_sum"`
+	RecoilSum     models.Stat `json:"recoil_sum"`
+	ErgonomicsSum models.Stat `json:"ergonomics_sum"`
 }
 
 // Cache interface for conflict-free item caching
@@ -26,6 +26,22 @@ type Cache interface {
 	Clear(ctx context.Context) error
 }
 
+// CacheWrite is a single pending cache entry, used for batched writes
+type CacheWrite struct {
+	ItemID      string
+	FocusedStat string
+	Constraints models.EvaluationConstraints
+	Entry       *CacheEntry
+}
+
+// BatchCache is a Cache which can persist several entries at once
+type BatchCache interface {
+	Cache
+
+	// SetMany stores all of the given entries
+	SetMany(ctx context.Context, writes []CacheWrite) error
+}
+
 // MemoryCache implements Cache using in-memory sync.Map
 type MemoryCache struct {
 	cache *sync.Map
@@ -54,6 +70,14 @@ func (m *MemoryCache) Set(ctx context.Context, itemID string, focusedStat string
 	return nil
 }
 
+// SetMany stores all entries in memory cache
+func (m *MemoryCache) SetMany(ctx context.Context, writes []CacheWrite) error {
+	for _, w := range writes {
+		m.cache.Store(makeCacheKey(w.ItemID, w.FocusedStat, w.Constraints), w.Entry)
+	}
+	return nil
+}
+
 // Clear removes all entries from memory cache
 func (m *MemoryCache) Clear(ctx context.Context) error {
 	m.cache = &sync.Map{}
@@ -72,7 +96,7 @@ func NewDatabaseCache(db *sql.DB) *DatabaseCache {
 
 // Get retrieves from database cache
 func (d *DatabaseCache) Get(ctx context.Context, itemID string, focusedStat string, constraints models.EvaluationConstraints) (*CacheEntry, error) {
-	cachedEntry, err := models.GetConflictFreeCache(ctx, d.db, itemID, focusedStat, constraints.TraderLevels)
+	cachedEntry, err := models.GetConflictFreeCache(ctx, d.db, itemID, focusedStat, cacheProfile(constraints), constraints.TraderLevels)
 	if err != nil {
 		// Handle domain-specific cache miss (no rows found) vs actual database error
 		if err == sql.ErrNoRows {
@@ -92,9 +116,35 @@ func (d *DatabaseCache) Get(ctx context.Context, itemID string, focusedStat stri
 
 // Set stores in database cache
 func (d *DatabaseCache) Set(ctx context.Context, itemID string, focusedStat string, constraints models.EvaluationConstraints, entry *CacheEntry) error {
-	cacheEntry := &models.ConflictFreeCache{
+	return models.UpsertConflictFreeCache(d.db, toConflictFreeCache(itemID, focusedStat, constraints, entry))
+}
+
+// SetMany stores all entries in database cache using a single transaction
+func (d *DatabaseCache) SetMany(ctx context.Context, writes []CacheWrite) error {
+	entries := make([]*models.ConflictFreeCache, 0, len(writes))
+	for _, w := range writes {
+		entries = append(entries, toConflictFreeCache(w.ItemID, w.FocusedStat, w.Constraints, w.Entry))
+	}
+
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = models.UpsertManyConflictFreeCache(tx, entries)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func toConflictFreeCache(itemID string, focusedStat string, constraints models.EvaluationConstraints, entry *CacheEntry) *models.ConflictFreeCache {
+	return &models.ConflictFreeCache{
 		ItemID:           itemID,
 		FocusedStat:      focusedStat,
+		Profile:          cacheProfile(constraints),
 		JaegerLevel:      getTraderLevel(constraints.TraderLevels, "Jaeger"),
 		PraporLevel:      getTraderLevel(constraints.TraderLevels, "Prapor"),
 		PeacekeeperLevel: getTraderLevel(constraints.TraderLevels, "Peacekeeper"),
@@ -103,7 +153,6 @@ func (d *DatabaseCache) Set(ctx context.Context, itemID string, focusedStat stri
 		RecoilSum:        entry.RecoilSum,
 		ErgonomicsSum:    entry.ErgonomicsSum,
 	}
-	return models.UpsertConflictFreeCache(d.db, cacheEntry)
 }
 
 // Clear removes all entries from database cache
@@ -119,10 +168,19 @@ func makeCacheKey(itemID string, focusedStat string, constraints models.Evaluati
 
 // serializeConstraints creates a stable string representation of constraints
 func serializeConstraints(constraints models.EvaluationConstraints) string {
-	// Create a deterministic string from trader levels
+	// Create a deterministic string from the profile and trader levels
 	levels := make([]string, len(constraints.TraderLevels))
 	for i, level := range constraints.TraderLevels {
 		levels[i] = fmt.Sprintf("%s:%d", level.Name, level.Level)
 	}
-	return fmt.Sprintf("%v", levels)
+	return fmt.Sprintf("%s|%v", cacheProfile(constraints), levels)
+}
+
+// cacheProfile is the profile entries are cached under. The ignored slots and items of a profile change which
+// children are reachable, so entries from one profile are never valid for another.
+func cacheProfile(constraints models.EvaluationConstraints) string {
+	if constraints.Profile == "" {
+		return models.DefaultProfileName
+	}
+	return constraints.Profile
 }
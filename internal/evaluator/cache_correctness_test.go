@@ -51,7 +51,7 @@ func TestCacheCorrectnessIntegration(t *testing.T) {
 	t.Log("Running evaluation WITHOUT cache...")
 	buildNoCache := FindBestBuild(weapon, "recoil", map[string]bool{}, nil)
 	require.NotNil(t, buildNoCache, "Expected non-nil build without cache")
-	t.Logf("No cache: RecoilSum=%d, ErgonomicsSum=%d, Items=%d",
+	t.Logf("No cache: RecoilSum=%s, ErgonomicsSum=%s, Items=%d",
 		buildNoCache.RecoilSum, buildNoCache.ErgonomicsSum, len(buildNoCache.OptimalItems))
 
 	// Run WITH cache
@@ -59,7 +59,7 @@ func TestCacheCorrectnessIntegration(t *testing.T) {
 	cache := NewMemoryCache()
 	buildWithCache := FindBestBuild(weapon, "recoil", map[string]bool{}, cache)
 	require.NotNil(t, buildWithCache, "Expected non-nil build with cache")
-	t.Logf("With cache: RecoilSum=%d, ErgonomicsSum=%d, Items=%d, Hits=%d, Misses=%d",
+	t.Logf("With cache: RecoilSum=%s, ErgonomicsSum=%s, Items=%d, Hits=%d, Misses=%d",
 		buildWithCache.RecoilSum, buildWithCache.ErgonomicsSum, len(buildWithCache.OptimalItems),
 		buildWithCache.CacheHits, buildWithCache.CacheMisses)
 
@@ -0,0 +1,65 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// SaveSnapshot writes every entry in the memory cache to path as JSON so a later run can start warm.
+// The file is written to a temporary path first so an interrupted save never leaves a truncated snapshot.
+func (m *MemoryCache) SaveSnapshot(path string) (int, error) {
+	entries := make(map[string]*CacheEntry)
+	m.cache.Range(func(key, value any) bool {
+		entries[key.(string)] = value.(*CacheEntry)
+		return true
+	})
+
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return 0, err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if err != nil {
+		tmp.Close()
+		return 0, err
+	}
+	err = tmp.Close()
+	if err != nil {
+		return 0, err
+	}
+
+	return len(entries), os.Rename(tmp.Name(), path)
+}
+
+// LoadSnapshot adds the entries saved by SaveSnapshot to the memory cache, returning how many were loaded.
+// A missing snapshot file is not an error.
+func (m *MemoryCache) LoadSnapshot(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	entries := make(map[string]*CacheEntry)
+	err = json.Unmarshal(data, &entries)
+	if err != nil {
+		return 0, err
+	}
+
+	for key, entry := range entries {
+		m.cache.Store(key, entry)
+	}
+
+	return len(entries), nil
+}
@@ -0,0 +1,40 @@
+package evaluator
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryCache_SnapshotRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	constraints := createMockConstraints()
+	path := filepath.Join(t.TempDir(), "cache.json")
+
+	original := NewMemoryCache()
+	require.NoError(t, original.Set(ctx, "a", "recoil", constraints, &CacheEntry{RecoilSum: -12, ErgonomicsSum: 4}))
+	require.NoError(t, original.Set(ctx, "b", "recoil", constraints, &CacheEntry{RecoilSum: -3}))
+
+	saved, err := original.SaveSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, saved)
+
+	restored := NewMemoryCache()
+	loaded, err := restored.LoadSnapshot(path)
+	require.NoError(t, err)
+	assert.Equal(t, 2, loaded)
+
+	entry, err := restored.Get(ctx, "a", "recoil", constraints)
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.Equal(t, CacheEntry{RecoilSum: -12, ErgonomicsSum: 4}, *entry)
+}
+
+func TestMemoryCache_LoadMissingSnapshot(t *testing.T) {
+	loaded, err := NewMemoryCache().LoadSnapshot(filepath.Join(t.TempDir(), "missing.json"))
+	require.NoError(t, err)
+	assert.Equal(t, 0, loaded)
+}
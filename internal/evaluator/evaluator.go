@@ -8,6 +8,7 @@ import (
 	"tarkov-build-optimiser/internal/candidate_tree"
 	"tarkov-build-optimiser/internal/helpers"
 	"tarkov-build-optimiser/internal/models"
+	"tarkov-build-optimiser/internal/tracer"
 
 	"github.com/rs/zerolog/log"
 )
@@ -32,11 +33,13 @@ type ItemEvaluation struct {
 	ID                 string                    `json:"id"`
 	Name               string                    `json:"name"`
 	Slots              []*SlotEvaluation         `json:"slots"`
-	RecoilModifier     int                       `json:"recoil_modifier"`
-	ErgonomicsModifier int                       `json:"ergonomics_modifier"`
+	RecoilModifier     models.Stat               `json:"recoil_modifier"`
+	ErgonomicsModifier models.Stat               `json:"ergonomics_modifier"`
+	Weight             float64                   `json:"weight"`
+	AccuracyModifier   float64                   `json:"accuracy_modifier"`
 	Conflicts          []ItemEvaluationConflicts `json:"conflicts"`
-	RecoilSum          int                       `json:"recoil_sum"`
-	ErgonomicsSum      int                       `json:"ergonomics_sum"`
+	RecoilSum          models.Stat               `json:"recoil_sum"`
+	ErgonomicsSum      models.Stat               `json:"ergonomics_sum"`
 }
 
 type SlotEvaluation struct {
@@ -63,6 +66,8 @@ func (s *SlotEvaluation) ToSlotEvaluationResult(evaluationType string) models.Sl
 		Name:               s.Item.Name,
 		RecoilModifier:     s.Item.RecoilModifier,
 		ErgonomicsModifier: s.Item.ErgonomicsModifier,
+		Weight:             s.Item.Weight,
+		AccuracyModifier:   s.Item.AccuracyModifier,
 		RecoilSum:          s.Item.RecoilSum,
 		ErgonomicsSum:      s.Item.ErgonomicsSum,
 		Slots:              make([]models.SlotEvaluationResult, 0),
@@ -115,8 +120,10 @@ type EvaluatedWeapon struct {
 	EvaluationType string                    `json:"evaluation_type"`
 	Slots          []*SlotEvaluation         `json:"slots"`
 	Conflicts      []ItemEvaluationConflicts `json:"conflicts"`
-	RecoilSum      int                       `json:"recoil_sum"`
-	ErgonomicsSum  int                       `json:"ergonomics_sum"`
+	RecoilSum      models.Stat               `json:"recoil_sum"`
+	ErgonomicsSum  models.Stat               `json:"ergonomics_sum"`
+	WeightSum      float64                   `json:"weight_sum"`
+	AccuracySum    float64                   `json:"accuracy_sum"`
 }
 
 func (ew *EvaluatedWeapon) GetSlotById(slotID string) *SlotEvaluation {
@@ -138,6 +145,8 @@ func (weapon *EvaluatedWeapon) ToItemEvaluationResult() models.ItemEvaluationRes
 		EvaluationType: weapon.EvaluationType,
 		RecoilSum:      weapon.RecoilSum,
 		ErgonomicsSum:  weapon.ErgonomicsSum,
+		WeightSum:      weapon.WeightSum,
+		AccuracySum:    weapon.AccuracySum,
 		Slots:          make([]models.SlotEvaluationResult, 0, len(weapon.Slots)),
 	}
 
@@ -153,19 +162,28 @@ func (weapon *EvaluatedWeapon) ToItemEvaluationResult() models.ItemEvaluationRes
 }
 
 type OptimalItem struct {
-	Name         string
-	ID           string
-	SlotID       string
+	Name   string
+	ID     string
+	SlotID string
+	// SlotPath is the ItemSlot.Path of the slot the item was chosen for. Unlike SlotID it's unique within a build, an
+	// item used twice has the same slots below both of its copies.
+	SlotPath     string
 	HasConflicts bool
 }
 
 // Build represents a complete weapon configuration.
 type Build struct {
-	WeaponTree     *candidate_tree.CandidateTree
-	OptimalItems   []OptimalItem
-	RecoilSum      int `json:"recoil_sum"`
-	ErgonomicsSum  int `json:"ergonomics_sum"`
+	WeaponTree    *candidate_tree.CandidateTree
+	OptimalItems  []OptimalItem
+	RecoilSum     models.Stat `json:"recoil_sum"`
+	ErgonomicsSum models.Stat `json:"ergonomics_sum"`
+	// WeightSum is the weight of the weapon with its mods, in kg
+	WeightSum float64 `json:"weight_sum"`
+	// AccuracySum is the sum of the mods' accuracy modifiers in percent, higher is more accurate
+	AccuracySum    float64 `json:"accuracy_sum"`
 	EvaluationType string
+	// PurchaseCost is the price in roubles of the build's items which weren't already owned, only set by FindReuseBuildContext
+	PurchaseCost   int `json:"purchase_cost"`
 	ExcludedItems  []string
 	HasConflicts   bool
 	CacheHits      int64 `json:"cache_hits"`
@@ -178,48 +196,52 @@ func (b *Build) ToEvaluatedWeapon() (EvaluatedWeapon, error) {
 		ID:             b.WeaponTree.Item.ID,
 		Name:           b.WeaponTree.Item.Name,
 		EvaluationType: b.EvaluationType,
-		Slots:          make([]*SlotEvaluation, 0, len(b.WeaponTree.Item.Slots)),
 		Conflicts:      make([]ItemEvaluationConflicts, 0),
 		RecoilSum:      b.RecoilSum,
 		ErgonomicsSum:  b.ErgonomicsSum,
+		WeightSum:      b.WeightSum,
+		AccuracySum:    b.AccuracySum,
 	}
 
-	for _, slot := range b.WeaponTree.Item.Slots {
-		slot := &SlotEvaluation{
-			ID:      slot.ID,
-			Name:    slot.Name,
-			IsEmpty: true,
-		}
-		result.Slots = append(result.Slots, slot)
+	// ensure the slots' paths are up to date with the current state of the weapon tree
+	b.WeaponTree.UpdateAllowedItemSlots()
+
+	chosen := make(map[string]OptimalItem, len(b.OptimalItems))
+	for _, item := range b.OptimalItems {
+		chosen[item.SlotPath] = item
 	}
 
-	// ensure the item & slot maps are up to date with the current state of the weapon tree
-	b.WeaponTree.UpdateAllowedItems()
-	b.WeaponTree.UpdateAllowedItemSlots()
-	remainingItems := make([]OptimalItem, len(b.OptimalItems))
-	copy(remainingItems, b.OptimalItems)
-
-	// keep looping through remainingItems until all have been added to the evaluated weapon
-	// or until no furter progress can be made.
-	misses := 0
-	for len(remainingItems) > 0 {
-		misses = 0
-		for i := 0; i < len(remainingItems); i++ {
-			destinationSlot := result.GetSlotById(remainingItems[i].SlotID)
-			// item comtaining this slot likely hasn't been added to the evaluated weapon yet
-			if destinationSlot == nil {
-				misses++
-				continue
+	// walk the tree filling each slot with the item chosen for its path, taking the item from that slot so a copy of
+	// an item used elsewhere gets its own slots
+	placed := 0
+	var fill func(slots []*candidate_tree.ItemSlot) []*SlotEvaluation
+	fill = func(slots []*candidate_tree.ItemSlot) []*SlotEvaluation {
+		evaluations := make([]*SlotEvaluation, 0, len(slots))
+		for _, slot := range slots {
+			evaluation := &SlotEvaluation{
+				ID:      slot.ID,
+				Name:    slot.Name,
+				IsEmpty: true,
 			}
+			evaluations = append(evaluations, evaluation)
 
-			source := b.WeaponTree.GetAllowedItem(remainingItems[i].ID)
+			item, ok := chosen[slot.Path]
+			if !ok {
+				continue
+			}
+			source := allowedItemInSlot(slot, item.ID)
+			if source == nil {
+				continue
+			}
+			placed++
 
 			evaluated := &ItemEvaluation{
 				ID:                 source.ID,
 				Name:               source.Name,
 				RecoilModifier:     source.RecoilModifier,
 				ErgonomicsModifier: source.ErgonomicsModifier,
-				Slots:              make([]*SlotEvaluation, len(source.Slots)),
+				Weight:             source.Weight,
+				AccuracyModifier:   source.AccuracyModifier,
 				Conflicts:          make([]ItemEvaluationConflicts, 0),
 			}
 
@@ -233,35 +255,43 @@ func (b *Build) ToEvaluatedWeapon() (EvaluatedWeapon, error) {
 				result.Conflicts = append(result.Conflicts, conflict)
 			}
 
-			for j := 0; j < len(source.Slots); j++ {
-				evaluated.Slots[j] = &SlotEvaluation{
-					ID:      source.Slots[j].ID,
-					Name:    source.Slots[j].Name,
-					IsEmpty: true,
-				}
-			}
-
-			destinationSlot.Item = evaluated
-			destinationSlot.IsEmpty = false
-			newItems := make([]OptimalItem, 0)
-			for _, item := range remainingItems {
-				if item.ID != evaluated.ID {
-					newItems = append(newItems, item)
-				}
-			}
-			remainingItems = newItems
+			evaluated.Slots = fill(source.Slots)
+			evaluation.Item = evaluated
+			evaluation.IsEmpty = false
 		}
+		return evaluations
+	}
+	result.Slots = fill(b.WeaponTree.Item.Slots)
 
-		if len(remainingItems) > 0 && misses == len(remainingItems) {
-			log.Error().Msgf("Failed to convert optimal items to evaluated weapon for %s", b.WeaponTree.Item.Name)
-			return EvaluatedWeapon{}, errors.New("failed to convert optimal items to evaluated weapon")
-		}
+	// every chosen item has a slot of its own, anything left over wasn't chosen from this tree
+	if placed != len(b.OptimalItems) {
+		log.Error().Msgf("Failed to convert optimal items to evaluated weapon for %s", b.WeaponTree.Item.Name)
+		return EvaluatedWeapon{}, errors.New("failed to convert optimal items to evaluated weapon")
 	}
 	return result, nil
 }
 
+// ErrNoBuild is returned when no build satisfies the weapon's constraints, e.g. no allowed item is in a required category
+var ErrNoBuild = errors.New("no build satisfies the constraints")
+
+// FindBestBuild returns the best build for the weapon, or nil if no build satisfies its constraints
 func FindBestBuild(weapon *candidate_tree.CandidateTree, focusedStat string,
 	excludedItems map[string]bool, cache Cache) *Build {
+	build, _ := FindBestBuildContext(context.Background(), weapon, focusedStat, excludedItems, cache)
+	return build
+}
+
+// FindBestBuildContext is FindBestBuild with cancellation. The search stops as soon as ctx is done and ctx's error
+// is returned instead of the partial build. Nothing found after cancellation is written to cache.
+func FindBestBuildContext(ctx context.Context, weapon *candidate_tree.CandidateTree, focusedStat string,
+	excludedItems map[string]bool, cache Cache) (*Build, error) {
+	return FindBestBuildTraced(ctx, weapon, focusedStat, excludedItems, cache, nil)
+}
+
+// FindBestBuildTraced is FindBestBuildContext recording the search's decisions to trace, which isn't closed. Nothing
+// is recorded when trace is nil.
+func FindBestBuildTraced(ctx context.Context, weapon *candidate_tree.CandidateTree, focusedStat string,
+	excludedItems map[string]bool, cache Cache, trace *tracer.Writer) (*Build, error) {
 
 	log.Debug().Msgf("Finding best build for %s", weapon.Item.Name)
 
@@ -273,6 +303,13 @@ func FindBestBuild(weapon *candidate_tree.CandidateTree, focusedStat string,
 
 	weapon.UpdateAllowedItemSlots()
 	weapon.UpdateAllowedItems()
+	weapon.UpdateRequiredCategories()
+
+	// cached children are the best children regardless of the rest of the build, which doesn't hold once a build
+	// must use a category that the children might supply, or the best children might be too heavy
+	if len(weapon.Constraints.RequiredCategories) > 0 || weapon.Constraints.MaxWeight > 0 {
+		cache = nil
+	}
 
 	//stock := slotNameMap["Stock"]
 	//stockBuild := processSlots(weapon, []*candidate_tree.ItemSlot{stock}, []OptimalItem{}, focusedStat, 0, 0, excludedItems, nil, map[string]*Build{})
@@ -313,7 +350,13 @@ func FindBestBuild(weapon *candidate_tree.CandidateTree, focusedStat string,
 	slotDescendantItemIDs := precomputeSlotDescendantItemIDs(weapon)
 
 	var cacheHits, cacheMisses, itemsEvaluated int64
-	build := processSlots(weapon, weapon.Item.Slots, []OptimalItem{}, focusedStat, 0, 0, excludedItems, nil, slotDescendantItemIDs, &cacheHits, &cacheMisses, &itemsEvaluated, cache)
+	build := processSlots(ctx, weapon, weapon.Item.Slots, []OptimalItem{}, focusedStat, 0, 0, weapon.Item.Weight, weapon.Item.AccuracyModifier, 0, excludedItems, nil, slotDescendantItemIDs, &cacheHits, &cacheMisses, &itemsEvaluated, cache, nil, trace)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if build == nil {
+		return nil, ErrNoBuild
+	}
 
 	build.WeaponTree = weapon
 	build.CacheHits = cacheHits
@@ -325,7 +368,15 @@ func FindBestBuild(weapon *candidate_tree.CandidateTree, focusedStat string,
 		log.Debug().Msgf("Conflict-free cache: %d hits, %d misses (%.1f%% hit rate)", cacheHits, cacheMisses, hitRate)
 	}
 
-	return build
+	return build, nil
+}
+
+func chosenItemIDs(chosenItems []OptimalItem) []string {
+	ids := make([]string, 0, len(chosenItems))
+	for _, chosen := range chosenItems {
+		ids = append(ids, chosen.ID)
+	}
+	return ids
 }
 
 func conflictsWith(item *candidate_tree.Item, chosen OptimalItem) bool {
@@ -337,6 +388,13 @@ func conflictsWith(item *candidate_tree.Item, chosen OptimalItem) bool {
 	return false
 }
 
+// weightTolerance is the difference in kg below which weights are treated as equal, summing the same mods in a
+// different order can give slightly different totals
+const weightTolerance = 1e-6
+
+// accuracyTolerance is weightTolerance for accuracy modifiers, in percent
+const accuracyTolerance = 1e-6
+
 func doesImproveStats(candidate *Build, best *Build, focusedStat string) bool {
 	if focusedStat == "recoil" {
 		if candidate.RecoilSum < best.RecoilSum {
@@ -350,14 +408,50 @@ func doesImproveStats(candidate *Build, best *Build, focusedStat string) bool {
 		} else if candidate.ErgonomicsSum == best.ErgonomicsSum {
 			return candidate.RecoilSum < best.RecoilSum
 		}
+	} else if focusedStat == "weight" {
+		if candidate.WeightSum < best.WeightSum-weightTolerance {
+			return true
+		} else if candidate.WeightSum <= best.WeightSum+weightTolerance {
+			return candidate.RecoilSum < best.RecoilSum
+		}
+	} else if focusedStat == "accuracy" {
+		if candidate.AccuracySum > best.AccuracySum+accuracyTolerance {
+			return true
+		} else if candidate.AccuracySum >= best.AccuracySum-accuracyTolerance {
+			return candidate.RecoilSum < best.RecoilSum
+		}
 	}
 
 	return false
 }
 
+// allowedItemInSlot returns the slot's copy of an allowed item, nil if the item isn't allowed in the slot
+func allowedItemInSlot(slot *candidate_tree.ItemSlot, itemID string) *candidate_tree.Item {
+	for _, allowed := range slot.AllowedItems {
+		if allowed.ID == itemID {
+			return allowed
+		}
+	}
+	return nil
+}
+
+// focusedStatValue is the build's focused stat in its units, as recorded in traces
+func focusedStatValue(build *Build, focusedStat string) float64 {
+	switch focusedStat {
+	case "ergonomics":
+		return build.ErgonomicsSum.Points()
+	case "weight":
+		return build.WeightSum
+	case "accuracy":
+		return build.AccuracySum
+	default:
+		return build.RecoilSum.Points()
+	}
+}
+
 // computeRecoilLowerBound returns the minimal possible final recoil sum achievable by
 // filling the given slots from the current recoil sum, using each slot's MinRecoil potential.
-func computeRecoilLowerBound(currentRecoil int, slots []*candidate_tree.ItemSlot) int {
+func computeRecoilLowerBound(currentRecoil models.Stat, slots []*candidate_tree.ItemSlot) models.Stat {
 	bound := currentRecoil
 	for _, s := range slots {
 		if s == nil {
@@ -370,7 +464,7 @@ func computeRecoilLowerBound(currentRecoil int, slots []*candidate_tree.ItemSlot
 
 // computeErgoUpperBound returns the maximal possible final ergonomics sum achievable by
 // filling the given slots from the current ergonomics sum, using each slot's MaxErgonomics potential.
-func computeErgoUpperBound(currentErgo int, slots []*candidate_tree.ItemSlot) int {
+func computeErgoUpperBound(currentErgo models.Stat, slots []*candidate_tree.ItemSlot) models.Stat {
 	bound := currentErgo
 	for _, s := range slots {
 		if s == nil {
@@ -381,6 +475,32 @@ func computeErgoUpperBound(currentErgo int, slots []*candidate_tree.ItemSlot) in
 	return bound
 }
 
+// computeWeightLowerBound returns the minimal possible final weight achievable by filling the given slots from the
+// current weight, using each slot's MinWeight potential.
+func computeWeightLowerBound(currentWeight float64, slots []*candidate_tree.ItemSlot) float64 {
+	bound := currentWeight
+	for _, s := range slots {
+		if s == nil {
+			continue
+		}
+		bound += s.PotentialValues.MinWeight
+	}
+	return bound
+}
+
+// computeAccuracyUpperBound returns the maximal possible final accuracy modifier achievable by filling the given
+// slots from the current accuracy, using each slot's MaxAccuracy potential.
+func computeAccuracyUpperBound(currentAccuracy float64, slots []*candidate_tree.ItemSlot) float64 {
+	bound := currentAccuracy
+	for _, s := range slots {
+		if s == nil {
+			continue
+		}
+		bound += s.PotentialValues.MaxAccuracy
+	}
+	return bound
+}
+
 // precomputeSlotDescendantItemIDs returns, for every slot in the candidate tree, the set of
 // descendant allowed item IDs reachable from that slot. This is used to reduce the memo key
 // to only exclusions that matter for the current subproblem (current slot + remaining slots).
@@ -412,12 +532,16 @@ func precomputeSlotDescendantItemIDs(root *candidate_tree.CandidateTree) map[str
 }
 
 func processSlots(
+	ctx context.Context,
 	root *candidate_tree.CandidateTree,
 	slotsToProcess []*candidate_tree.ItemSlot,
 	chosenItems []OptimalItem,
 	focusedStat string,
-	recoilStatSum int,
-	ergoStatSum int,
+	recoilStatSum models.Stat,
+	ergoStatSum models.Stat,
+	weightSum float64,
+	accuracySum float64,
+	purchaseCost int,
 	excludedItems map[string]bool,
 	visitedSlots map[string]bool,
 	slotDescendantItemIDs map[string]map[string]bool,
@@ -425,11 +549,30 @@ func processSlots(
 	cacheMisses *int64,
 	itemsEvaluated *int64,
 	cache Cache,
+	reuse *reuseObjective,
+	trace *tracer.Writer,
 ) *Build {
+	// abandon the search, callers ignore nil builds
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	// mods only ever add weight, so nothing below an overweight build can be valid
+	if root.Constraints.MaxWeight > 0 && weightSum > root.Constraints.MaxWeight {
+		return nil
+	}
+
 	clonedSlots := append([]*candidate_tree.ItemSlot{}, slotsToProcess...)
 
 	// Base case: No more slots to process
 	if len(clonedSlots) == 0 {
+		if !root.MeetsRequiredCategories(chosenItemIDs(chosenItems)) {
+			return nil
+		}
+		if reuse != nil && !reuse.canMeetLimit(focusedStat, recoilStatSum, ergoStatSum, weightSum, accuracySum, nil) {
+			return nil
+		}
+
 		exclusions := make([]string, 0)
 		for excludedID, isExcluded := range excludedItems {
 			if isExcluded {
@@ -441,38 +584,57 @@ func processSlots(
 			OptimalItems:   append([]OptimalItem{}, chosenItems...), // Make a copy
 			RecoilSum:      recoilStatSum,
 			ErgonomicsSum:  ergoStatSum,
+			WeightSum:      weightSum,
+			AccuracySum:    accuracySum,
 			EvaluationType: focusedStat,
 			ExcludedItems:  exclusions,
+			PurchaseCost:   purchaseCost,
 		}
 	}
 
 	currentSlot := clonedSlots[0]
 	remainingSlots := clonedSlots[1:]
 
-	if visitedSlots[currentSlot.ID] {
-		return processSlots(root, remainingSlots, chosenItems, focusedStat, recoilStatSum, ergoStatSum, excludedItems, visitedSlots, slotDescendantItemIDs, cacheHits, cacheMisses, itemsEvaluated, cache)
+	if visitedSlots[currentSlot.Path] {
+		return processSlots(ctx, root, remainingSlots, chosenItems, focusedStat, recoilStatSum, ergoStatSum, weightSum, accuracySum, purchaseCost, excludedItems, visitedSlots, slotDescendantItemIDs, cacheHits, cacheMisses, itemsEvaluated, cache, reuse, trace)
 	}
 
 	if visitedSlots == nil {
 		visitedSlots = make(map[string]bool)
 	}
-	visitedSlots[currentSlot.ID] = true
+	visitedSlots[currentSlot.Path] = true
 	defer func() {
-		delete(visitedSlots, currentSlot.ID)
+		delete(visitedSlots, currentSlot.Path)
 	}()
 
+	if trace != nil {
+		trace.Record(tracer.EnterSlot, len(chosenItems), currentSlot.ID, "", 0)
+	}
+
 	var best *Build = nil
 
 	for _, item := range currentSlot.AllowedItems {
+		if ctx.Err() != nil {
+			return nil
+		}
+
 		// Track items evaluated
 		atomic.AddInt64(itemsEvaluated, 1)
 
 		if focusedStat == "recoil" {
-			if item.PotentialValues.MinRecoil >= 0 {
+			if item.PotentialValues.MinRecoil >= 0 && !item.ProvidesRequiredCategory {
 				continue
 			}
 		} else if focusedStat == "ergonomics" {
-			if item.PotentialValues.MaxErgonomics <= 0 {
+			if item.PotentialValues.MaxErgonomics <= 0 && !item.ProvidesRequiredCategory {
+				continue
+			}
+		} else if focusedStat == "weight" {
+			if item.PotentialValues.MinWeight > 0 && !item.ProvidesRequiredCategory && !currentSlot.Required {
+				continue
+			}
+		} else if focusedStat == "accuracy" {
+			if item.PotentialValues.MaxAccuracy <= 0 && !item.ProvidesRequiredCategory {
 				continue
 			}
 		}
@@ -502,24 +664,28 @@ func processSlots(
 		// For conflict-free items with children, ensure we have a cached children contribution
 		// This allows pruning based on known optimal children values
 		if isConflictFree && cache != nil && len(item.Slots) > 0 {
-			cachedEntry, _ := cache.Get(context.Background(), item.ID, focusedStat, root.Constraints)
+			cachedEntry, _ := cache.Get(ctx, item.ID, focusedStat, root.Constraints)
 			if cachedEntry == nil {
 				// Evaluate JUST this item's child slots to get clean children contribution
 				// This is safe because conflict-free items don't affect excluded items
 				newRecoilForCache := recoilStatSum + item.RecoilModifier
 				newErgoForCache := ergoStatSum + item.ErgonomicsModifier
+				newWeightForCache := weightSum + item.Weight
+				newAccuracyForCache := accuracySum + item.AccuracyModifier
 				newExcludedForCache := helpers.CloneMap(excludedItems)
 				newChosenForCache := append(chosenItems, OptimalItem{
-					Name:   item.Name,
-					ID:     item.ID,
-					SlotID: currentSlot.ID,
+					Name:     item.Name,
+					ID:       item.ID,
+					SlotID:   currentSlot.ID,
+					SlotPath: currentSlot.Path,
 				})
-				childrenResult := processSlots(root, item.Slots, newChosenForCache, focusedStat, newRecoilForCache, newErgoForCache, newExcludedForCache, visitedSlots, slotDescendantItemIDs, cacheHits, cacheMisses, itemsEvaluated, cache)
-				if childrenResult != nil {
+				childrenResult := processSlots(ctx, root, item.Slots, newChosenForCache, focusedStat, newRecoilForCache, newErgoForCache, newWeightForCache, newAccuracyForCache, purchaseCost, newExcludedForCache, visitedSlots, slotDescendantItemIDs, cacheHits, cacheMisses, itemsEvaluated, cache, reuse, trace)
+				// a cancelled search may not have found the best children, so isn't cached
+				if childrenResult != nil && ctx.Err() == nil {
 					// Store children contribution (subtract ancestors + item)
 					childrenRecoil := childrenResult.RecoilSum - newRecoilForCache
 					childrenErgo := childrenResult.ErgonomicsSum - newErgoForCache
-					_ = cache.Set(context.Background(), item.ID, focusedStat, root.Constraints, &CacheEntry{
+					_ = cache.Set(ctx, item.ID, focusedStat, root.Constraints, &CacheEntry{
 						RecoilSum:     childrenRecoil,
 						ErgonomicsSum: childrenErgo,
 					})
@@ -529,9 +695,12 @@ func processSlots(
 
 		// Try conflict-free cache lookup for pruning
 		if isConflictFree && cache != nil {
-			cachedEntry, err := cache.Get(context.Background(), item.ID, focusedStat, root.Constraints)
+			cachedEntry, err := cache.Get(ctx, item.ID, focusedStat, root.Constraints)
 			if err == nil && cachedEntry != nil {
 				atomic.AddInt64(cacheHits, 1)
+				if trace != nil {
+					trace.Record(tracer.CacheHit, len(chosenItems), currentSlot.ID, item.ID, 0)
+				}
 
 				// Use cached stats for pruning - if we know the result won't be better, skip evaluation
 				// cachedEntry contains only children's contribution (not item or ancestors)
@@ -542,6 +711,9 @@ func processSlots(
 						potentialRecoil := recoilStatSum + item.RecoilModifier + cachedEntry.RecoilSum + siblingsLowerBound
 						if potentialRecoil >= best.RecoilSum {
 							// Can't beat best even with optimal siblings
+							if trace != nil {
+								trace.Record(tracer.CacheSkip, len(chosenItems), currentSlot.ID, item.ID, potentialRecoil.Points())
+							}
 							continue
 						}
 					} else if focusedStat == "ergonomics" {
@@ -549,6 +721,9 @@ func processSlots(
 						potentialErgo := ergoStatSum + item.ErgonomicsModifier + cachedEntry.ErgonomicsSum + siblingsUpperBound
 						if potentialErgo <= best.ErgonomicsSum {
 							// Can't beat best even with optimal siblings
+							if trace != nil {
+								trace.Record(tracer.CacheSkip, len(chosenItems), currentSlot.ID, item.ID, potentialErgo.Points())
+							}
 							continue
 						}
 					}
@@ -559,13 +734,20 @@ func processSlots(
 		}
 
 		newChosen := append(chosenItems, OptimalItem{
-			Name:   item.Name,
-			ID:     item.ID,
-			SlotID: currentSlot.ID,
+			Name:     item.Name,
+			ID:       item.ID,
+			SlotID:   currentSlot.ID,
+			SlotPath: currentSlot.Path,
 		})
 
 		newRecoil := recoilStatSum + item.RecoilModifier
 		newErgo := ergoStatSum + item.ErgonomicsModifier
+		newWeight := weightSum + item.Weight
+		newAccuracy := accuracySum + item.AccuracyModifier
+		newCost := purchaseCost
+		if reuse != nil && !reuse.owned[item.ID] {
+			newCost += item.PriceRub
+		}
 
 		newSlotsToProcess := append([]*candidate_tree.ItemSlot{}, item.Slots...)
 		newSlotsToProcess = append(newSlotsToProcess, remainingSlots...)
@@ -575,27 +757,68 @@ func processSlots(
 			newExcluded[c.ID] = true
 		}
 
-		if best != nil {
+		if reuse != nil {
+			if best != nil && newCost > best.PurchaseCost {
+				if trace != nil {
+					trace.Record(tracer.PruneCost, len(chosenItems), currentSlot.ID, item.ID, float64(newCost))
+				}
+				continue
+			}
+			if !reuse.canMeetLimit(focusedStat, newRecoil, newErgo, newWeight, newAccuracy, newSlotsToProcess) {
+				if trace != nil {
+					trace.Record(tracer.PruneLimit, len(chosenItems), currentSlot.ID, item.ID, 0)
+				}
+				continue
+			}
+		}
+
+		// a cheaper reuse build wins whatever its stats, so it's only pruned on stats against one costing the same
+		if best != nil && (reuse == nil || newCost == best.PurchaseCost) {
 			if focusedStat == "recoil" {
 				lowerBound := computeRecoilLowerBound(newRecoil, newSlotsToProcess)
 				if lowerBound > best.RecoilSum {
+					if trace != nil {
+						trace.Record(tracer.PruneBound, len(chosenItems), currentSlot.ID, item.ID, lowerBound.Points())
+					}
 					continue
 				}
 			} else if focusedStat == "ergonomics" {
 				upperBound := computeErgoUpperBound(newErgo, newSlotsToProcess)
 				if upperBound < best.ErgonomicsSum {
+					if trace != nil {
+						trace.Record(tracer.PruneBound, len(chosenItems), currentSlot.ID, item.ID, upperBound.Points())
+					}
+					continue
+				}
+			} else if focusedStat == "weight" {
+				lowerBound := computeWeightLowerBound(newWeight, newSlotsToProcess)
+				if lowerBound > best.WeightSum {
+					if trace != nil {
+						trace.Record(tracer.PruneBound, len(chosenItems), currentSlot.ID, item.ID, lowerBound)
+					}
+					continue
+				}
+			} else if focusedStat == "accuracy" {
+				upperBound := computeAccuracyUpperBound(newAccuracy, newSlotsToProcess)
+				if upperBound < best.AccuracySum {
+					if trace != nil {
+						trace.Record(tracer.PruneBound, len(chosenItems), currentSlot.ID, item.ID, upperBound)
+					}
 					continue
 				}
 			}
 		}
 
-		candidate := processSlots(root, newSlotsToProcess, newChosen, focusedStat, newRecoil, newErgo, newExcluded, visitedSlots, slotDescendantItemIDs, cacheHits, cacheMisses, itemsEvaluated, cache)
+		if trace != nil {
+			trace.Record(tracer.TryItem, len(chosenItems), currentSlot.ID, item.ID, 0)
+		}
+		candidate := processSlots(ctx, root, newSlotsToProcess, newChosen, focusedStat, newRecoil, newErgo, newWeight, newAccuracy, newCost, newExcluded, visitedSlots, slotDescendantItemIDs, cacheHits, cacheMisses, itemsEvaluated, cache, reuse, trace)
 
 		// Cache conflict-free leaf items (items without children) - only at leaf positions
 		// Items with children are cached earlier in the dedicated caching block
 		if isConflictFree && candidate != nil && cache != nil && len(item.Slots) == 0 && len(remainingSlots) == 0 {
 			// Leaf item: children contribution is 0
-			_ = cache.Set(context.Background(), item.ID, focusedStat, root.Constraints, &CacheEntry{
+			_ = cache.Set(ctx, item.ID, focusedStat, root.Constraints, &CacheEntry{
 				RecoilSum:     0,
 				ErgonomicsSum: 0,
 			})
@@ -605,12 +828,15 @@ func processSlots(
 			if best == nil {
 				// it could be better than possibly nothing, so store it as the best for now.
 				best = candidate
-			} else if doesImproveStats(candidate, best, focusedStat) {
+			} else if isBetterBuild(candidate, best, focusedStat, reuse) {
 				// it's better than the best we've seen so far
 				best = candidate
 
 				// do not break; later items may unlock better global builds due to conflicts
 			}
+			if trace != nil && best == candidate {
+				trace.Record(tracer.Incumbent, len(chosenItems), currentSlot.ID, item.ID, focusedStatValue(candidate, focusedStat))
+			}
 		}
 	}
 
@@ -618,26 +844,64 @@ func processSlots(
 	// opens up a better build can be slotted in elsewhere which would conflict with any build created using any item
 	// in this slot.
 	// Option to leave this slot empty; apply pruning before exploring
-	if best != nil {
+	// The lightest builds fill required slots, unless nothing can be fitted in them
+	if focusedStat == "weight" && currentSlot.Required && len(currentSlot.AllowedItems) > 0 {
+		return best
+	}
+	if reuse != nil && !reuse.canMeetLimit(focusedStat, recoilStatSum, ergoStatSum, weightSum, accuracySum, remainingSlots) {
+		if trace != nil {
+			trace.Record(tracer.PruneLimit, len(chosenItems), currentSlot.ID, "", 0)
+		}
+		return best
+	}
+	if best != nil && (reuse == nil || purchaseCost == best.PurchaseCost) {
 		switch focusedStat {
 		case "recoil":
 			lowerBound := computeRecoilLowerBound(recoilStatSum, remainingSlots)
 			if lowerBound > best.RecoilSum {
 				// cannot beat best even if remaining slots are ideal
+				if trace != nil {
+					trace.Record(tracer.PruneBound, len(chosenItems), currentSlot.ID, "", lowerBound.Points())
+				}
 				return best
 			}
 		case "ergonomics":
 			upperBound := computeErgoUpperBound(ergoStatSum, remainingSlots)
 			if upperBound < best.ErgonomicsSum {
+				if trace != nil {
+					trace.Record(tracer.PruneBound, len(chosenItems), currentSlot.ID, "", upperBound.Points())
+				}
+				return best
+			}
+		case "weight":
+			lowerBound := computeWeightLowerBound(weightSum, remainingSlots)
+			if lowerBound > best.WeightSum {
+				if trace != nil {
+					trace.Record(tracer.PruneBound, len(chosenItems), currentSlot.ID, "", lowerBound)
+				}
+				return best
+			}
+		case "accuracy":
+			upperBound := computeAccuracyUpperBound(accuracySum, remainingSlots)
+			if upperBound < best.AccuracySum {
+				if trace != nil {
+					trace.Record(tracer.PruneBound, len(chosenItems), currentSlot.ID, "", upperBound)
+				}
 				return best
 			}
 		}
 	}
-	candidateSkip := processSlots(root, remainingSlots, chosenItems, focusedStat, recoilStatSum, ergoStatSum, helpers.CloneMap(excludedItems), visitedSlots, slotDescendantItemIDs, cacheHits, cacheMisses, itemsEvaluated, cache)
+	if trace != nil {
+		trace.Record(tracer.TryItem, len(chosenItems), currentSlot.ID, "", 0)
+	}
+	candidateSkip := processSlots(ctx, root, remainingSlots, chosenItems, focusedStat, recoilStatSum, ergoStatSum, weightSum, accuracySum, purchaseCost, helpers.CloneMap(excludedItems), visitedSlots, slotDescendantItemIDs, cacheHits, cacheMisses, itemsEvaluated, cache, reuse, trace)
 
 	if candidateSkip != nil {
-		if best == nil || doesImproveStats(candidateSkip, best, focusedStat) {
+		if best == nil || isBetterBuild(candidateSkip, best, focusedStat, reuse) {
 			best = candidateSkip
+			if trace != nil {
+				trace.Record(tracer.Incumbent, len(chosenItems), currentSlot.ID, "", focusedStatValue(candidateSkip, focusedStat))
+			}
 		}
 	}
 
@@ -1,6 +1,7 @@
 package evaluator
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"tarkov-build-optimiser/internal/candidate_tree"
@@ -40,7 +41,7 @@ func buildSyntheticTree(topSlotCount int, itemsPerTopSlot int, childItemsPerSlot
 			thirdLevelSlot.AllowedItems = append(thirdLevelSlot.AllowedItems, &candidate_tree.Item{
 				Name:               fmt.Sprintf("third-item-%d-%d", i, j),
 				ID:                 fmt.Sprintf("item-third-%d-%d", i, j),
-				RecoilModifier:     -1 - (j % 2),
+				RecoilModifier:     models.Stat(-1 - (j % 2)),
 				ErgonomicsModifier: 0,
 				ConflictingItems:   []candidate_tree.ConflictingItem{},
 				Slots:              []*candidate_tree.ItemSlot{},
@@ -50,7 +51,7 @@ func buildSyntheticTree(topSlotCount int, itemsPerTopSlot int, childItemsPerSlot
 		commonChild.AllowedItems = append(commonChild.AllowedItems, &candidate_tree.Item{
 			Name:               fmt.Sprintf("child-item-%d", i),
 			ID:                 fmt.Sprintf("item-child-%d", i),
-			RecoilModifier:     -1 - (i % 3), // ensure negative potential to avoid skip
+			RecoilModifier:     models.Stat(-1 - (i % 3)), // ensure negative potential to avoid skip
 			ErgonomicsModifier: 0,
 			ConflictingItems:   []candidate_tree.ConflictingItem{},
 			Slots:              []*candidate_tree.ItemSlot{thirdLevelSlot},
@@ -69,7 +70,7 @@ func buildSyntheticTree(topSlotCount int, itemsPerTopSlot int, childItemsPerSlot
 			slot.AllowedItems = append(slot.AllowedItems, &candidate_tree.Item{
 				Name:               fmt.Sprintf("top-%d-item-%d", s, i),
 				ID:                 fmt.Sprintf("item-top-%d-%d", s, i),
-				RecoilModifier:     -1 - (i % 2), // negative to explore
+				RecoilModifier:     models.Stat(-1 - (i % 2)), // negative to explore
 				ErgonomicsModifier: 0,
 				ConflictingItems:   []candidate_tree.ConflictingItem{},
 				Slots:              []*candidate_tree.ItemSlot{commonChild}, // shared subtree → repeated subproblems
@@ -121,7 +122,7 @@ func BenchmarkProcessSlots_CachePerformance(b *testing.B) {
 		for i := 0; i < b.N; i++ {
 			var cacheHits, cacheMisses, itemsEvaluated int64
 			cache := NewMemoryCache() // Fresh cache every iteration
-			sink = processSlots(weapon, slots, chosen, "recoil", 0, 0, excluded, visited, desc, &cacheHits, &cacheMisses, &itemsEvaluated, cache)
+			sink = processSlots(context.Background(), weapon, slots, chosen, "recoil", 0, 0, 0, 0, 0, excluded, visited, desc, &cacheHits, &cacheMisses, &itemsEvaluated, cache, nil, nil)
 		}
 	})
 
@@ -130,14 +131,14 @@ func BenchmarkProcessSlots_CachePerformance(b *testing.B) {
 		// Pre-seed the cache with results from a full evaluation
 		warmCache := NewMemoryCache()
 		var warmHits, warmMisses, warmItemsEvaluated int64
-		_ = processSlots(weapon, slots, chosen, "recoil", 0, 0, excluded, visited, desc, &warmHits, &warmMisses, &warmItemsEvaluated, warmCache)
+		_ = processSlots(context.Background(), weapon, slots, chosen, "recoil", 0, 0, 0, 0, 0, excluded, visited, desc, &warmHits, &warmMisses, &warmItemsEvaluated, warmCache, nil, nil)
 
 		b.ReportAllocs()
 		b.ResetTimer()
 		for i := 0; i < b.N; i++ {
 			var cacheHits, cacheMisses, itemsEvaluated int64
 			// Reuse the same pre-seeded cache
-			sink = processSlots(weapon, slots, chosen, "recoil", 0, 0, excluded, visited, desc, &cacheHits, &cacheMisses, &itemsEvaluated, warmCache)
+			sink = processSlots(context.Background(), weapon, slots, chosen, "recoil", 0, 0, 0, 0, 0, excluded, visited, desc, &cacheHits, &cacheMisses, &itemsEvaluated, warmCache, nil, nil)
 		}
 	})
 }
@@ -158,14 +159,14 @@ func TestCachePerformance(t *testing.T) {
 	coldStart := time.Now()
 	var coldHits, coldMisses, coldItemsEvaluated int64
 	coldCache := NewMemoryCache() // Fresh empty cache
-	coldResult := processSlots(weapon, slots, chosen, "recoil", 0, 0, excluded, visited, desc, &coldHits, &coldMisses, &coldItemsEvaluated, coldCache)
+	coldResult := processSlots(context.Background(), weapon, slots, chosen, "recoil", 0, 0, 0, 0, 0, excluded, visited, desc, &coldHits, &coldMisses, &coldItemsEvaluated, coldCache, nil, nil)
 	coldDuration := time.Since(coldStart)
 
 	// Test 2: Warm cache - pre-populate then measure same evaluation
 	warmCache := NewMemoryCache()
 	// Pre-populate the cache with the SAME evaluation
 	var preHits, preMisses, preItemsEvaluated int64
-	_ = processSlots(weapon, slots, chosen, "recoil", 0, 0, excluded, visited, desc, &preHits, &preMisses, &preItemsEvaluated, warmCache)
+	_ = processSlots(context.Background(), weapon, slots, chosen, "recoil", 0, 0, 0, 0, 0, excluded, visited, desc, &preHits, &preMisses, &preItemsEvaluated, warmCache, nil, nil)
 
 	// Debug: Check cache size after pre-population
 	cacheSize := 0
@@ -179,13 +180,13 @@ func TestCachePerformance(t *testing.T) {
 	// Use separate variables to avoid resetting the counters
 	warmStart := time.Now()
 	var warmHits, warmMisses, warmItemsEvaluated int64
-	warmResult := processSlots(weapon, slots, chosen, "recoil", 0, 0, excluded, visited, desc, &warmHits, &warmMisses, &warmItemsEvaluated, warmCache)
+	warmResult := processSlots(context.Background(), weapon, slots, chosen, "recoil", 0, 0, 0, 0, 0, excluded, visited, desc, &warmHits, &warmMisses, &warmItemsEvaluated, warmCache, nil, nil)
 	warmDuration := time.Since(warmStart)
 
 	// Third run - should be near-identical to second run (cache fully populated)
 	thirdStart := time.Now()
 	var thirdHits, thirdMisses, thirdItemsEvaluated int64
-	_ = processSlots(weapon, slots, chosen, "recoil", 0, 0, excluded, visited, desc, &thirdHits, &thirdMisses, &thirdItemsEvaluated, warmCache)
+	_ = processSlots(context.Background(), weapon, slots, chosen, "recoil", 0, 0, 0, 0, 0, excluded, visited, desc, &thirdHits, &thirdMisses, &thirdItemsEvaluated, warmCache, nil, nil)
 	thirdDuration := time.Since(thirdStart)
 
 	t.Logf("Cold cache: %v, %d hits, %d misses, %d items evaluated", coldDuration, coldHits, coldMisses, coldItemsEvaluated)
@@ -1,12 +1,16 @@
 package evaluator
 
 import (
+	"bytes"
+	"context"
 	"sync"
 	"tarkov-build-optimiser/internal/candidate_tree"
 	"tarkov-build-optimiser/internal/models"
+	"tarkov-build-optimiser/internal/tracer"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func createMockConstraints() models.EvaluationConstraints {
@@ -275,21 +279,21 @@ func TestFindBestBuild(t *testing.T) {
 
 	// stock -> buffer tube
 	assert.Equal(t, "item-buffer-tube", evaluation.Slots[1].Item.ID)
-	assert.Equal(t, -5, evaluation.Slots[1].Item.RecoilModifier)
-	assert.Equal(t, -5, evaluation.Slots[1].Item.ErgonomicsModifier)
+	assert.EqualValues(t, -5, evaluation.Slots[1].Item.RecoilModifier)
+	assert.EqualValues(t, -5, evaluation.Slots[1].Item.ErgonomicsModifier)
 	assert.Len(t, evaluation.Slots[1].Item.Slots, 1)
 
 	// stock  -> buffer tube -> stock
 	assert.NotNil(t, evaluation.Slots[1].Item.Slots[0].Item)
 	assert.Equal(t, "item-good-stock", evaluation.Slots[1].Item.Slots[0].Item.ID)
-	assert.Equal(t, -22, evaluation.Slots[1].Item.Slots[0].Item.RecoilModifier)
-	assert.Equal(t, 10, evaluation.Slots[1].Item.Slots[0].Item.ErgonomicsModifier)
+	assert.EqualValues(t, -22, evaluation.Slots[1].Item.Slots[0].Item.RecoilModifier)
+	assert.EqualValues(t, 10, evaluation.Slots[1].Item.Slots[0].Item.ErgonomicsModifier)
 
 	// receiver
 	assert.NotNil(t, evaluation.Slots[2].Item)
 	assert.Equal(t, "item-base-receiver", evaluation.Slots[2].Item.ID)
-	assert.Equal(t, -1, evaluation.Slots[2].Item.RecoilModifier)
-	assert.Equal(t, 0, evaluation.Slots[2].Item.ErgonomicsModifier)
+	assert.EqualValues(t, -1, evaluation.Slots[2].Item.RecoilModifier)
+	assert.EqualValues(t, 0, evaluation.Slots[2].Item.ErgonomicsModifier)
 
 	assert.Len(t, evaluation.Slots[2].Item.Slots, 3)
 
@@ -299,8 +303,8 @@ func TestFindBestBuild(t *testing.T) {
 	// receiver foregrip
 	assert.NotNil(t, evaluation.Slots[2].Item.Slots[1].Item)
 	assert.Equal(t, "item-bad-foregrip", evaluation.Slots[2].Item.Slots[1].Item.ID)
-	assert.Equal(t, -1, evaluation.Slots[2].Item.Slots[1].Item.RecoilModifier)
-	assert.Equal(t, 1, evaluation.Slots[2].Item.Slots[1].Item.ErgonomicsModifier)
+	assert.EqualValues(t, -1, evaluation.Slots[2].Item.Slots[1].Item.RecoilModifier)
+	assert.EqualValues(t, 1, evaluation.Slots[2].Item.Slots[1].Item.ErgonomicsModifier)
 
 	// receiver mount
 	//assert.Nil(t, evaluation.Slots[2].Item.Slots[2].Item)
@@ -309,8 +313,8 @@ func TestFindBestBuild(t *testing.T) {
 	assert.NotNil(t, evaluation.Slots[0].Item)
 	assert.Equal(t, "item-bad-grip", evaluation.Slots[0].Item.ID)
 
-	assert.Equal(t, -5, evaluation.Slots[0].Item.RecoilModifier)
-	assert.Equal(t, 1, evaluation.Slots[0].Item.ErgonomicsModifier)
+	assert.EqualValues(t, -5, evaluation.Slots[0].Item.RecoilModifier)
+	assert.EqualValues(t, 1, evaluation.Slots[0].Item.ErgonomicsModifier)
 	assert.Len(t, evaluation.Slots[0].Item.Slots, 0)
 }
 
@@ -370,6 +374,51 @@ func TestFindBestBuild_ErgonomicsFocus_SelectsHighestErgo(t *testing.T) {
 	}
 }
 
+func TestFindBestBuildContext_Cancelled(t *testing.T) {
+	child := &candidate_tree.Item{
+		Name:             "muzzle",
+		ID:               "item-muzzle",
+		RecoilModifier:   -5,
+		ConflictingItems: []candidate_tree.ConflictingItem{},
+		Slots:            []*candidate_tree.ItemSlot{},
+	}
+	barrel := &candidate_tree.Item{
+		Name:             "barrel",
+		ID:               "item-barrel",
+		RecoilModifier:   -1,
+		ConflictingItems: []candidate_tree.ConflictingItem{},
+		Slots: []*candidate_tree.ItemSlot{
+			{Name: "muzzle", ID: "slot-muzzle", AllowedItems: []*candidate_tree.Item{child}},
+		},
+	}
+	rootItem := &candidate_tree.Item{
+		Name:             "Weapon",
+		ID:               "item-weapon",
+		ConflictingItems: []candidate_tree.ConflictingItem{},
+		Slots: []*candidate_tree.ItemSlot{
+			{Name: "barrel", ID: "slot-barrel", AllowedItems: []*candidate_tree.Item{barrel}},
+		},
+	}
+	weapon := &candidate_tree.CandidateTree{Item: rootItem}
+	weapon.Item.CalculatePotentialValues()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	cache := NewMemoryCache()
+	best, err := FindBestBuildContext(ctx, weapon, "recoil", map[string]bool{}, cache)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, best)
+
+	entry, err := cache.Get(context.Background(), "item-barrel", "recoil", weapon.Constraints)
+	assert.NoError(t, err)
+	assert.Nil(t, entry, "expected a cancelled search not to be cached")
+
+	best, err = FindBestBuildContext(context.Background(), weapon, "recoil", map[string]bool{}, cache)
+	require.NoError(t, err)
+	assert.EqualValues(t, -6, best.RecoilSum)
+}
+
 func TestFindBestBuild_RecoilFocus_TieBreaksOnErgonomics(t *testing.T) {
 	// One slot with two items having equal recoil but different ergonomics
 	slot := &candidate_tree.ItemSlot{
@@ -471,6 +520,152 @@ func TestFindBestBuild_RespectsExcludedItems(t *testing.T) {
 	}
 }
 
+func TestFindBestBuild_RequiredCategories(t *testing.T) {
+	stockSlot := &candidate_tree.ItemSlot{
+		Name: "slot-stock",
+		ID:   "slot-stock",
+		AllowedItems: []*candidate_tree.Item{
+			{Name: "stock", ID: "item-stock", CategoryName: "Stock", RecoilModifier: -10},
+		},
+	}
+	gripSlot := &candidate_tree.ItemSlot{
+		Name: "slot-grip",
+		ID:   "slot-grip",
+		AllowedItems: []*candidate_tree.Item{
+			// makes recoil worse, so is only used when a foregrip is required
+			{Name: "grip", ID: "item-grip", CategoryName: "Foregrip", RecoilModifier: 2},
+		},
+	}
+	newWeapon := func(required []string) *candidate_tree.CandidateTree {
+		weapon := &candidate_tree.CandidateTree{
+			Item:        &candidate_tree.Item{Name: "Weapon", ID: "item-weapon", Type: "weapon", Slots: []*candidate_tree.ItemSlot{stockSlot, gripSlot}},
+			Constraints: models.EvaluationConstraints{RequiredCategories: required},
+		}
+		weapon.Item.CalculatePotentialValues()
+		return weapon
+	}
+
+	best := FindBestBuild(newWeapon(nil), "recoil", map[string]bool{}, NewMemoryCache())
+	assert.EqualValues(t, -10, best.RecoilSum)
+	assert.Len(t, best.OptimalItems, 1)
+
+	best = FindBestBuild(newWeapon([]string{"Foregrip"}), "recoil", map[string]bool{}, NewMemoryCache())
+	assert.EqualValues(t, -8, best.RecoilSum)
+	assert.Len(t, best.OptimalItems, 2)
+
+	_, err := FindBestBuildContext(context.Background(), newWeapon([]string{"Silencer"}), "recoil", map[string]bool{}, NewMemoryCache())
+	assert.ErrorIs(t, err, ErrNoBuild)
+}
+
+func TestFindBestBuild_FractionalModifiers(t *testing.T) {
+	// truncated to whole points the adapter and its brake sum to -4 and lose to the -5 brake, at full precision they
+	// sum to -5.1 and win
+	adapterSlot := &candidate_tree.ItemSlot{
+		Name:         "slot-adapter-muzzle",
+		ID:           "slot-adapter-muzzle",
+		AllowedItems: []*candidate_tree.Item{{Name: "small brake", ID: "item-small-brake", RecoilModifier: models.NewStat(-2.6)}},
+	}
+	muzzleSlot := &candidate_tree.ItemSlot{
+		Name: "slot-muzzle",
+		ID:   "slot-muzzle",
+		AllowedItems: []*candidate_tree.Item{
+			{Name: "brake", ID: "item-brake", RecoilModifier: models.NewStat(-5)},
+			{Name: "adapter", ID: "item-adapter", RecoilModifier: models.NewStat(-2.5), Slots: []*candidate_tree.ItemSlot{adapterSlot}},
+		},
+	}
+	weapon := &candidate_tree.CandidateTree{
+		Item: &candidate_tree.Item{Name: "Weapon", ID: "item-weapon", Type: "weapon", Slots: []*candidate_tree.ItemSlot{muzzleSlot}},
+	}
+	weapon.Item.CalculatePotentialValues()
+
+	best := FindBestBuild(weapon, "recoil", map[string]bool{}, NewMemoryCache())
+
+	require.Len(t, best.OptimalItems, 2)
+	assert.Equal(t, "item-adapter", best.OptimalItems[0].ID)
+	assert.Equal(t, "item-small-brake", best.OptimalItems[1].ID)
+	assert.Equal(t, models.NewStat(-5.1), best.RecoilSum)
+	assert.Equal(t, -5.1, best.RecoilSum.Points())
+}
+
+func TestFindBestBuild_Accuracy(t *testing.T) {
+	barrelSlot := &candidate_tree.ItemSlot{
+		Name: "slot-barrel",
+		ID:   "slot-barrel",
+		AllowedItems: []*candidate_tree.Item{
+			{Name: "short barrel", ID: "item-short-barrel", RecoilModifier: -2, AccuracyModifier: -10},
+			{Name: "long barrel", ID: "item-long-barrel", RecoilModifier: 0, AccuracyModifier: 15},
+		},
+	}
+	muzzleSlot := &candidate_tree.ItemSlot{
+		Name: "slot-muzzle",
+		ID:   "slot-muzzle",
+		AllowedItems: []*candidate_tree.Item{
+			{Name: "brake", ID: "item-brake", RecoilModifier: -12, AccuracyModifier: -3},
+			{Name: "suppressor", ID: "item-suppressor", RecoilModifier: -8, AccuracyModifier: 0},
+		},
+	}
+	weapon := &candidate_tree.CandidateTree{
+		Item: &candidate_tree.Item{Name: "Weapon", ID: "item-weapon", Type: "weapon", Slots: []*candidate_tree.ItemSlot{barrelSlot, muzzleSlot}},
+	}
+	weapon.Item.CalculatePotentialValues()
+
+	best := FindBestBuild(weapon, "accuracy", map[string]bool{}, NewMemoryCache())
+
+	// like ergonomics builds, mods which can't improve accuracy are left off
+	require.Len(t, best.OptimalItems, 1)
+	assert.Equal(t, "item-long-barrel", best.OptimalItems[0].ID)
+	assert.InDelta(t, 15.0, best.AccuracySum, 1e-9)
+}
+
+func TestFindBestBuild_Weight(t *testing.T) {
+	newWeapon := func(constraints models.EvaluationConstraints) *candidate_tree.CandidateTree {
+		stockSlot := &candidate_tree.ItemSlot{
+			Name: "slot-stock",
+			ID:   "slot-stock",
+			AllowedItems: []*candidate_tree.Item{
+				{Name: "heavy stock", ID: "item-heavy-stock", CategoryName: "Stock", RecoilModifier: -10, Weight: 0.8},
+				{Name: "light stock", ID: "item-light-stock", CategoryName: "Stock", RecoilModifier: -6, Weight: 0.3},
+			},
+		}
+		weapon := &candidate_tree.CandidateTree{
+			Item:        &candidate_tree.Item{Name: "Weapon", ID: "item-weapon", Type: "weapon", Weight: 3, Slots: []*candidate_tree.ItemSlot{stockSlot}},
+			Constraints: constraints,
+		}
+		weapon.Item.CalculatePotentialValues()
+		return weapon
+	}
+
+	best := FindBestBuild(newWeapon(models.EvaluationConstraints{}), "recoil", map[string]bool{}, NewMemoryCache())
+	assert.Equal(t, "item-heavy-stock", best.OptimalItems[0].ID)
+	assert.InDelta(t, 3.8, best.WeightSum, 1e-9)
+
+	// the heavy stock takes the weapon over the limit
+	best = FindBestBuild(newWeapon(models.EvaluationConstraints{MaxWeight: 3.5}), "recoil", map[string]bool{}, NewMemoryCache())
+	require.Len(t, best.OptimalItems, 1)
+	assert.Equal(t, "item-light-stock", best.OptimalItems[0].ID)
+
+	// the lightest build leaves the stock off, unless the slot or its category is required
+	best = FindBestBuild(newWeapon(models.EvaluationConstraints{}), "weight", map[string]bool{}, NewMemoryCache())
+	assert.Empty(t, best.OptimalItems)
+	assert.InDelta(t, 3.0, best.WeightSum, 1e-9)
+
+	required := newWeapon(models.EvaluationConstraints{})
+	required.Item.Slots[0].Required = true
+	required.Item.CalculatePotentialValues()
+	assert.InDelta(t, 3.3, required.Item.PotentialValues.MinWeight, 1e-9)
+	best = FindBestBuild(required, "weight", map[string]bool{}, NewMemoryCache())
+	require.Len(t, best.OptimalItems, 1)
+	assert.Equal(t, "item-light-stock", best.OptimalItems[0].ID)
+	assert.InDelta(t, 3.3, best.WeightSum, 1e-9)
+
+	best = FindBestBuild(newWeapon(models.EvaluationConstraints{RequiredCategories: []string{"Stock"}}), "weight", map[string]bool{}, NewMemoryCache())
+	require.Len(t, best.OptimalItems, 1)
+	assert.Equal(t, "item-light-stock", best.OptimalItems[0].ID)
+
+	_, err := FindBestBuildContext(context.Background(), newWeapon(models.EvaluationConstraints{MaxWeight: 2}), "recoil", map[string]bool{}, NewMemoryCache())
+	assert.ErrorIs(t, err, ErrNoBuild)
+}
+
 func TestFindBestBuild_SkipsSlotIfBetterGlobal(t *testing.T) {
 	// Two top-level slots. Slot S1 has an item that conflicts with a very good item in S2.
 	// Best global choice is to leave S1 empty so S2 can pick the very good item.
@@ -738,3 +933,112 @@ func TestFindBestBuild_ErgonomicsFocus_TieBreaksOnRecoil(t *testing.T) {
 		t.Fatalf("expected ergonomics tie-break to choose lower recoil, got %+v", eval.Slots[0].Item)
 	}
 }
+
+func TestFindBestBuildTraced(t *testing.T) {
+	buf := bytes.Buffer{}
+	trace := tracer.NewWriter(&buf)
+	build, err := FindBestBuildTraced(context.Background(), newReuseWeapon(), "recoil", map[string]bool{}, NewMemoryCache(), trace)
+	require.NoError(t, err)
+	require.NoError(t, trace.Close())
+
+	untraced := FindBestBuild(newReuseWeapon(), "recoil", map[string]bool{}, NewMemoryCache())
+	assert.Equal(t, untraced.RecoilSum, build.RecoilSum)
+	assert.ElementsMatch(t, itemIDs(untraced), itemIDs(build))
+
+	events, err := tracer.ReadAll(&buf)
+	require.NoError(t, err)
+	summary := tracer.Summarise(events)
+	assert.Positive(t, summary.Counts[tracer.EnterSlot])
+	assert.Positive(t, summary.Counts[tracer.TryItem])
+
+	// the last incumbent of the first slot is the build found
+	incumbents := tracer.Filter(events, "slot-stock", "")
+	var last tracer.Event
+	for _, event := range incumbents {
+		if event.Kind == tracer.Incumbent {
+			last = event
+		}
+	}
+	assert.Equal(t, 0, last.Depth)
+	assert.Equal(t, build.RecoilSum.Points(), last.Value)
+}
+
+// newDuplicateMountWeapon has the same mount allowed on both rails of the handguard, each copy with the same scope
+// slot below it, as candidate trees have a copy of an item for every slot it's allowed in
+func newDuplicateMountWeapon() *candidate_tree.CandidateTree {
+	mount := func() *candidate_tree.Item {
+		return &candidate_tree.Item{Name: "mount", ID: "item-mount", RecoilModifier: models.NewStat(-1), Slots: []*candidate_tree.ItemSlot{
+			{Name: "slot-mount-top", ID: "slot-mount-top", AllowedItems: []*candidate_tree.Item{
+				{Name: "grip", ID: "item-grip", RecoilModifier: models.NewStat(-2)},
+			}},
+		}}
+	}
+	handguard := &candidate_tree.Item{Name: "handguard", ID: "item-handguard", RecoilModifier: models.NewStat(-3), Slots: []*candidate_tree.ItemSlot{
+		{Name: "slot-rail-left", ID: "slot-rail-left", AllowedItems: []*candidate_tree.Item{mount()}},
+		{Name: "slot-rail-right", ID: "slot-rail-right", AllowedItems: []*candidate_tree.Item{mount()}},
+	}}
+	weapon := &candidate_tree.CandidateTree{
+		Item: &candidate_tree.Item{Name: "Weapon", ID: "item-weapon", Type: "weapon", Slots: []*candidate_tree.ItemSlot{
+			{Name: "slot-handguard", ID: "slot-handguard", AllowedItems: []*candidate_tree.Item{handguard}},
+		}},
+	}
+	weapon.Item.CalculatePotentialValues()
+	return weapon
+}
+
+func TestFindBestBuild_SameItemInSeveralSlots(t *testing.T) {
+	build := FindBestBuild(newDuplicateMountWeapon(), "recoil", map[string]bool{}, NewMemoryCache())
+	require.NotNil(t, build)
+
+	// both mounts and the grips on both of them
+	assert.Equal(t, models.NewStat(-9), build.RecoilSum)
+	paths := make([]string, 0, len(build.OptimalItems))
+	for _, item := range build.OptimalItems {
+		paths = append(paths, item.SlotPath)
+	}
+	assert.ElementsMatch(t, []string{
+		"slot-handguard",
+		"slot-handguard/slot-rail-left",
+		"slot-handguard/slot-rail-left/slot-mount-top",
+		"slot-handguard/slot-rail-right",
+		"slot-handguard/slot-rail-right/slot-mount-top",
+	}, paths)
+}
+
+func TestToEvaluatedWeapon_SameItemInSeveralSlots(t *testing.T) {
+	build := FindBestBuild(newDuplicateMountWeapon(), "recoil", map[string]bool{}, NewMemoryCache())
+	require.NotNil(t, build)
+
+	eval, err := build.ToEvaluatedWeapon()
+	require.NoError(t, err)
+
+	require.Len(t, eval.Slots, 1)
+	handguard := eval.Slots[0].Item
+	require.NotNil(t, handguard)
+	require.Len(t, handguard.Slots, 2)
+	for _, rail := range handguard.Slots {
+		require.NotNil(t, rail.Item, "expected a mount on %s", rail.ID)
+		assert.Equal(t, "item-mount", rail.Item.ID)
+		require.Len(t, rail.Item.Slots, 1)
+		require.NotNil(t, rail.Item.Slots[0].Item, "expected a grip on the mount on %s", rail.ID)
+		assert.Equal(t, "item-grip", rail.Item.Slots[0].Item.ID)
+	}
+
+	// the items chosen for the right rail belong there, not on the left rail's mount
+	build.OptimalItems = []OptimalItem{
+		{ID: "item-handguard", SlotID: "slot-handguard", SlotPath: "slot-handguard"},
+		{ID: "item-mount", SlotID: "slot-rail-left", SlotPath: "slot-handguard/slot-rail-left"},
+		{ID: "item-mount", SlotID: "slot-rail-right", SlotPath: "slot-handguard/slot-rail-right"},
+		{ID: "item-grip", SlotID: "slot-mount-top", SlotPath: "slot-handguard/slot-rail-right/slot-mount-top"},
+	}
+	eval, err = build.ToEvaluatedWeapon()
+	require.NoError(t, err)
+	rails := eval.Slots[0].Item.Slots
+	assert.True(t, rails[0].Item.Slots[0].IsEmpty)
+	assert.False(t, rails[1].Item.Slots[0].IsEmpty)
+
+	// an item whose slot isn't in the tree can't be placed
+	build.OptimalItems = append(build.OptimalItems, OptimalItem{ID: "item-grip", SlotID: "slot-mount-top", SlotPath: "slot-missing/slot-mount-top"})
+	_, err = build.ToEvaluatedWeapon()
+	assert.Error(t, err)
+}
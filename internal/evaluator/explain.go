@@ -0,0 +1,247 @@
+package evaluator
+
+import (
+	"slices"
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/models"
+)
+
+// maxAlternatives is the number of rejected items explained per slot, the best by the build type's stat
+const maxAlternatives = 3
+
+// focusScore is how good stats are for the focused stat, higher is better
+func focusScore(focusedStat string, recoil models.Stat, ergonomics models.Stat, weight float64, accuracy float64) float64 {
+	switch focusedStat {
+	case "ergonomics":
+		return ergonomics.Points()
+	case "weight":
+		return -weight
+	case "accuracy":
+		return accuracy
+	default:
+		return -recoil.Points()
+	}
+}
+
+// bestCaseScore is the focused stat's score of an item fitted with the best possible items below it
+func bestCaseScore(focusedStat string, item *candidate_tree.Item) float64 {
+	return focusScore(focusedStat, item.PotentialValues.MinRecoil, item.PotentialValues.MaxErgonomics,
+		item.PotentialValues.MinWeight, item.PotentialValues.MaxAccuracy)
+}
+
+type rankedRejection struct {
+	item  models.RejectedItem
+	score float64
+}
+
+// explainer holds the build's chosen items while walking its slots
+type explainer struct {
+	build *Build
+	data  candidate_tree.TreeDataProvider
+	// chosen maps a slot path to the item chosen for it
+	chosen map[string]OptimalItem
+}
+
+// subtreeIDs adds the IDs of the chosen item and the items chosen below it to ids, returning the subtree's stat sums
+func (e *explainer) subtreeIDs(item *candidate_tree.Item, ids map[string]bool) (models.Stat, models.Stat, float64, float64) {
+	ids[item.ID] = true
+
+	recoil, ergonomics, weight, accuracy := item.RecoilModifier, item.ErgonomicsModifier, item.Weight, item.AccuracyModifier
+	for _, slot := range item.Slots {
+		chosen, ok := e.chosen[slot.Path]
+		if !ok {
+			continue
+		}
+		child := allowedItemInSlot(slot, chosen.ID)
+		if child == nil {
+			continue
+		}
+		r, er, w, a := e.subtreeIDs(child, ids)
+		recoil, ergonomics, weight, accuracy = recoil+r, ergonomics+er, weight+w, accuracy+a
+	}
+	return recoil, ergonomics, weight, accuracy
+}
+
+// conflictingChosen returns the chosen item, outside of the slot being explained, which conflicts with an item
+func (e *explainer) conflictingChosen(itemID string, conflicts []string, outside map[string]bool) string {
+	for _, id := range conflicts {
+		if outside[id] {
+			return id
+		}
+	}
+	for _, item := range e.build.OptimalItems {
+		if !outside[item.ID] {
+			continue
+		}
+		chosen := e.build.WeaponTree.GetAllowedItem(item.ID)
+		if chosen == nil {
+			continue
+		}
+		for _, conflict := range chosen.ConflictingItems {
+			if conflict.ID == itemID {
+				return item.ID
+			}
+		}
+	}
+	return ""
+}
+
+// explainSlot ranks the items which weren't chosen for a slot, those in the candidate tree and those the constraints'
+// trader levels or pruning left out of it
+func (e *explainer) explainSlot(slot *candidate_tree.ItemSlot) (models.SlotExplanation, error) {
+	focusedStat := e.build.EvaluationType
+	constraints := e.build.WeaponTree.Constraints
+	explanation := models.SlotExplanation{SlotID: slot.ID, SlotName: slot.Name, Alternatives: make([]models.RejectedItem, 0)}
+
+	// the chosen item and everything below it would be swapped out, so can't conflict with an alternative
+	replaced := make(map[string]bool)
+	chosenScore := 0.0
+	if chosen, ok := e.chosen[slot.Path]; ok {
+		explanation.ItemID = chosen.ID
+		if item := allowedItemInSlot(slot, chosen.ID); item != nil {
+			recoil, ergonomics, weight, accuracy := e.subtreeIDs(item, replaced)
+			chosenScore = focusScore(focusedStat, recoil, ergonomics, weight, accuracy)
+		}
+	}
+	outside := make(map[string]bool)
+	for _, item := range e.build.OptimalItems {
+		if !replaced[item.ID] {
+			outside[item.ID] = true
+		}
+	}
+
+	rejections := make([]rankedRejection, 0)
+	inTree := make(map[string]bool)
+	for _, item := range slot.AllowedItems {
+		inTree[item.ID] = true
+		if item.ID == explanation.ItemID {
+			continue
+		}
+
+		rejected := models.RejectedItem{ID: item.ID, Name: item.Name}
+		conflicts := make([]string, 0, len(item.ConflictingItems))
+		for _, conflict := range item.ConflictingItems {
+			conflicts = append(conflicts, conflict.ID)
+		}
+		score := bestCaseScore(focusedStat, item)
+		if conflictsWith := e.conflictingChosen(item.ID, conflicts, outside); conflictsWith != "" {
+			rejected.Reason = models.RejectedConflict
+			rejected.ConflictsWith = conflictsWith
+		} else if score < chosenScore {
+			rejected.Reason = models.RejectedPruned
+		} else {
+			rejected.Reason = models.RejectedWorseStats
+		}
+		rejections = append(rejections, rankedRejection{item: rejected, score: score})
+	}
+
+	allowedItems, err := e.data.GetAllowedItemsBySlotID(slot.ID)
+	if err != nil {
+		return explanation, err
+	}
+	for _, allowedItem := range allowedItems {
+		if inTree[allowedItem.ID] || allowedItem.ID == explanation.ItemID {
+			continue
+		}
+
+		// as when populating candidate trees, an item which can't be loaded isn't a weapon mod
+		mod, err := e.data.GetWeaponModById(allowedItem.ID)
+		if err != nil || mod == nil {
+			continue
+		}
+		// left out by the user, not the optimiser
+		if slices.Contains(constraints.IgnoredItemIDs, mod.ID) || constraints.ExcludesCategory(mod.CategoryID, mod.CategoryName) {
+			continue
+		}
+
+		offers, err := e.data.GetTraderOffer(mod.ID)
+		if err != nil {
+			return explanation, err
+		}
+
+		rejected := models.RejectedItem{ID: mod.ID, Name: allowedItem.Name}
+		accuracy := mod.AccuracyModifier + candidate_tree.BarrelAccuracyModifier(e.build.WeaponTree.Item.CenterOfImpact, mod.CenterOfImpact)
+		score := focusScore(focusedStat, mod.RecoilModifier, mod.ErgonomicsModifier, mod.Weight, accuracy)
+		if _, available := candidate_tree.CheapestOffer(offers, constraints.TraderLevels); !available {
+			rejected.Reason = models.RejectedUnavailable
+			for _, offer := range offers {
+				if rejected.Trader == "" || offer.MinTraderLevel < rejected.TraderLevel {
+					rejected.Trader = offer.Trader
+					rejected.TraderLevel = offer.MinTraderLevel
+				}
+			}
+		} else if conflictsWith := e.conflictingChosen(mod.ID, mod.ConflictingItems, outside); conflictsWith != "" {
+			rejected.Reason = models.RejectedConflict
+			rejected.ConflictsWith = conflictsWith
+		} else {
+			// pruned from the candidate tree as no better than another item for the slot
+			rejected.Reason = models.RejectedPruned
+		}
+		rejections = append(rejections, rankedRejection{item: rejected, score: score})
+	}
+
+	slices.SortStableFunc(rejections, func(a, b rankedRejection) int {
+		if a.score > b.score {
+			return -1
+		}
+		if a.score < b.score {
+			return 1
+		}
+		return 0
+	})
+	for i := 0; i < len(rejections) && i < maxAlternatives; i++ {
+		explanation.Alternatives = append(explanation.Alternatives, rejections[i].item)
+	}
+
+	return explanation, nil
+}
+
+// explainSlots explains the slots and the slots of the items chosen for them, depth first
+func (e *explainer) explainSlots(slots []*candidate_tree.ItemSlot, explanations []models.SlotExplanation) ([]models.SlotExplanation, error) {
+	for _, slot := range slots {
+		// ignored slots are never filled, there's nothing to explain
+		if slices.Contains(e.build.WeaponTree.Constraints.IgnoredSlotNames, slot.Name) {
+			continue
+		}
+
+		explanation, err := e.explainSlot(slot)
+		if err != nil {
+			return nil, err
+		}
+		explanations = append(explanations, explanation)
+
+		if explanation.ItemID == "" {
+			continue
+		}
+		item := allowedItemInSlot(slot, explanation.ItemID)
+		if item == nil {
+			continue
+		}
+		explanations, err = e.explainSlots(item.Slots, explanations)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return explanations, nil
+}
+
+// Explain records, for every slot of the build, the best items which weren't chosen for it and why each lost. Items
+// are compared with the chosen item and everything fitted below it, at their best: an item which can't beat it even
+// then was pruned, one which could but wasn't chosen did worse with the rest of the build. data is used to find the
+// items missing from the build's candidate tree, those unavailable at its trader levels or pruned from it.
+func (b *Build) Explain(data candidate_tree.TreeDataProvider) (*models.BuildExplanation, error) {
+	b.WeaponTree.UpdateAllowedItems()
+	b.WeaponTree.UpdateAllowedItemSlots()
+
+	e := &explainer{build: b, data: data, chosen: make(map[string]OptimalItem, len(b.OptimalItems))}
+	for _, item := range b.OptimalItems {
+		e.chosen[item.SlotPath] = item
+	}
+
+	slots, err := e.explainSlots(b.WeaponTree.Item.Slots, make([]models.SlotExplanation, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.BuildExplanation{BuildType: b.EvaluationType, Slots: slots}, nil
+}
@@ -0,0 +1,113 @@
+package evaluator
+
+import (
+	"fmt"
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// explainData has the muzzle devices missing from the candidate tree, one Mechanic only sells at level 4 and one the
+// constraints ignore
+type explainData struct{}
+
+func (d *explainData) GetWeaponById(id string) (*models.Weapon, error) {
+	return nil, fmt.Errorf("No results for %s", id)
+}
+
+func (d *explainData) GetSlotsByItemID(string) ([]models.Slot, error) {
+	return []models.Slot{}, nil
+}
+
+func (d *explainData) GetWeaponModById(id string) (*models.WeaponMod, error) {
+	switch id {
+	case "item-big-brake":
+		return &models.WeaponMod{ID: id, Name: "big brake", RecoilModifier: models.NewStat(-9)}, nil
+	case "item-ignored-brake":
+		return &models.WeaponMod{ID: id, Name: "ignored brake", RecoilModifier: models.NewStat(-20)}, nil
+	}
+	return nil, fmt.Errorf("weapon mod with id %s not found", id)
+}
+
+func (d *explainData) GetAllowedItemsBySlotID(id string) ([]*models.AllowedItem, error) {
+	if id == "slot-muzzle" {
+		return []*models.AllowedItem{{ID: "item-brake", Name: "brake"}, {ID: "item-big-brake", Name: "big brake"}, {ID: "item-ignored-brake", Name: "ignored brake"}}, nil
+	}
+	return []*models.AllowedItem{}, nil
+}
+
+func (d *explainData) GetTraderOffer(id string) ([]models.TraderOffer, error) {
+	if id == "item-big-brake" {
+		return []models.TraderOffer{{Trader: "Mechanic", MinTraderLevel: 4, PriceRub: 30000}}, nil
+	}
+	return []models.TraderOffer{{Trader: "Mechanic", MinTraderLevel: 1, PriceRub: 1000}}, nil
+}
+
+func (d *explainData) IsWeapon(string) (bool, error) {
+	return false, nil
+}
+
+func TestBuild_Explain(t *testing.T) {
+	adapterSlot := &candidate_tree.ItemSlot{
+		Name: "slot-adapter-muzzle",
+		ID:   "slot-adapter-muzzle",
+		AllowedItems: []*candidate_tree.Item{
+			{Name: "suppressor", ID: "item-suppressor", RecoilModifier: models.NewStat(-6), ConflictingItems: []candidate_tree.ConflictingItem{{ID: "item-stock-a"}, {ID: "item-stock-c"}}},
+		},
+	}
+	stockSlot := &candidate_tree.ItemSlot{
+		Name: "slot-stock",
+		ID:   "slot-stock",
+		AllowedItems: []*candidate_tree.Item{
+			{Name: "stock a", ID: "item-stock-a", RecoilModifier: models.NewStat(-10), ConflictingItems: []candidate_tree.ConflictingItem{{ID: "item-suppressor"}}},
+			{Name: "stock b", ID: "item-stock-b", RecoilModifier: models.NewStat(-6)},
+			{Name: "stock c", ID: "item-stock-c", RecoilModifier: models.NewStat(-11), ConflictingItems: []candidate_tree.ConflictingItem{{ID: "item-brake"}, {ID: "item-suppressor"}}},
+		},
+	}
+	muzzleSlot := &candidate_tree.ItemSlot{
+		Name: "slot-muzzle",
+		ID:   "slot-muzzle",
+		AllowedItems: []*candidate_tree.Item{
+			{Name: "brake", ID: "item-brake", RecoilModifier: models.NewStat(-5), ConflictingItems: []candidate_tree.ConflictingItem{{ID: "item-stock-c"}}},
+			{Name: "compensator", ID: "item-compensator", RecoilModifier: models.NewStat(-3)},
+			{Name: "adapter", ID: "item-adapter", RecoilModifier: models.NewStat(-1), Slots: []*candidate_tree.ItemSlot{adapterSlot}},
+		},
+	}
+	weapon := &candidate_tree.CandidateTree{
+		Item: &candidate_tree.Item{Name: "Weapon", ID: "item-weapon", Type: "weapon", Slots: []*candidate_tree.ItemSlot{stockSlot, muzzleSlot}},
+		Constraints: models.EvaluationConstraints{
+			TraderLevels:   []models.TraderLevel{{Name: "Mechanic", Level: 2}},
+			IgnoredItemIDs: []string{"item-ignored-brake"},
+		},
+	}
+	weapon.Item.CalculatePotentialValues()
+
+	build := FindBestBuild(weapon, "recoil", map[string]bool{}, NewMemoryCache())
+	require.NotNil(t, build)
+	require.Equal(t, models.NewStat(-15), build.RecoilSum)
+
+	explanation, err := build.Explain(&explainData{})
+	require.NoError(t, err)
+
+	assert.Equal(t, "recoil", explanation.BuildType)
+	require.Len(t, explanation.Slots, 2)
+
+	stock := explanation.Slots[0]
+	assert.Equal(t, "item-stock-a", stock.ItemID)
+	assert.Equal(t, []models.RejectedItem{
+		{ID: "item-stock-c", Name: "stock c", Reason: models.RejectedConflict, ConflictsWith: "item-brake"},
+		{ID: "item-stock-b", Name: "stock b", Reason: models.RejectedPruned},
+	}, stock.Alternatives)
+
+	muzzle := explanation.Slots[1]
+	assert.Equal(t, "item-brake", muzzle.ItemID)
+	assert.Equal(t, []models.RejectedItem{
+		{ID: "item-big-brake", Name: "big brake", Reason: models.RejectedUnavailable, Trader: "Mechanic", TraderLevel: 4},
+		// at best the adapter beats the brake, but its suppressor conflicts with the stock
+		{ID: "item-adapter", Name: "adapter", Reason: models.RejectedWorseStats},
+		{ID: "item-compensator", Name: "compensator", Reason: models.RejectedPruned},
+	}, muzzle.Alternatives)
+}
@@ -105,7 +105,7 @@ func TestFindBestBuildDatabaseCacheIntegration(t *testing.T) {
 
 			assert.NotNil(t, build.OptimalItems, "Build should have optimal items")
 			assert.Greater(t, len(build.OptimalItems), 0, "Build should have at least one optimal item")
-			assert.LessOrEqual(t, build.RecoilSum, 0, "Build should have optimized recoil (low recoil sum)")
+			assert.LessOrEqual(t, build.RecoilSum, models.Stat(0), "Build should have optimized recoil (low recoil sum)")
 			// Convert to evaluated weapon and verify structure
 			evaledWeapon, err := build.ToEvaluatedWeapon()
 			require.NoError(t, err, "Failed to convert build to evaluated weapon")
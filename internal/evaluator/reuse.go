@@ -0,0 +1,86 @@
+package evaluator
+
+import (
+	"context"
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/helpers"
+	"tarkov-build-optimiser/internal/models"
+
+	"github.com/rs/zerolog/log"
+)
+
+// reuseObjective makes processSlots look for the cheapest build to buy, given items already owned, rather than the
+// build with the best stats
+type reuseObjective struct {
+	owned map[string]bool
+	// limit is the worst acceptable build, only its focused stat is compared
+	limit Build
+}
+
+// canMeetLimit reports whether filling slots from the given sums could give a build whose focused stat is within the
+// limit, using the same potential values as the bounds used to prune the search
+func (r *reuseObjective) canMeetLimit(focusedStat string, recoil models.Stat, ergonomics models.Stat, weight float64, accuracy float64, slots []*candidate_tree.ItemSlot) bool {
+	switch focusedStat {
+	case "recoil":
+		return computeRecoilLowerBound(recoil, slots) <= r.limit.RecoilSum
+	case "ergonomics":
+		return computeErgoUpperBound(ergonomics, slots) >= r.limit.ErgonomicsSum
+	case "weight":
+		return computeWeightLowerBound(weight, slots) <= r.limit.WeightSum+weightTolerance
+	case "accuracy":
+		return computeAccuracyUpperBound(accuracy, slots) >= r.limit.AccuracySum-accuracyTolerance
+	}
+	return true
+}
+
+// isBetterBuild is doesImproveStats, except that reuse builds are compared by purchase cost first
+func isBetterBuild(candidate *Build, best *Build, focusedStat string, reuse *reuseObjective) bool {
+	if reuse != nil && candidate.PurchaseCost != best.PurchaseCost {
+		return candidate.PurchaseCost < best.PurchaseCost
+	}
+	return doesImproveStats(candidate, best, focusedStat)
+}
+
+// FindReuseBuildContext returns the build which is cheapest to buy given the items already owned, among the builds
+// whose focused stat is at most slack worse than the best build's. Slack is in the stat's units, recoil or
+// ergonomics points, kg or accuracy percent, zero only allows builds as good as the best. Ties on price go to the
+// build with the better stats. Items are priced at the weapon tree's trader levels.
+func FindReuseBuildContext(ctx context.Context, weapon *candidate_tree.CandidateTree, focusedStat string,
+	owned map[string]bool, slack float64, excludedItems map[string]bool) (*Build, error) {
+
+	best, err := FindBestBuildContext(ctx, weapon, focusedStat, helpers.CloneMap(excludedItems), NewMemoryCache())
+	if err != nil {
+		return nil, err
+	}
+
+	limit := *best
+	switch focusedStat {
+	case "recoil":
+		limit.RecoilSum += models.NewStat(slack)
+	case "ergonomics":
+		limit.ErgonomicsSum -= models.NewStat(slack)
+	case "weight":
+		limit.WeightSum += slack
+	case "accuracy":
+		limit.AccuracySum -= slack
+	}
+	reuse := &reuseObjective{owned: owned, limit: limit}
+
+	log.Debug().Msgf("Finding reuse build for %s with %d owned items", weapon.Item.Name, len(owned))
+
+	// the cache holds the best children by stats, which needn't be the cheapest
+	slotDescendantItemIDs := precomputeSlotDescendantItemIDs(weapon)
+	var cacheHits, cacheMisses, itemsEvaluated int64
+	build := processSlots(ctx, weapon, weapon.Item.Slots, []OptimalItem{}, focusedStat, 0, 0, weapon.Item.Weight, weapon.Item.AccuracyModifier, 0, excludedItems, nil, slotDescendantItemIDs, &cacheHits, &cacheMisses, &itemsEvaluated, nil, reuse, nil)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if build == nil {
+		return nil, ErrNoBuild
+	}
+
+	build.WeaponTree = weapon
+	build.ItemsEvaluated = best.ItemsEvaluated + itemsEvaluated
+
+	return build, nil
+}
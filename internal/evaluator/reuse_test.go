@@ -0,0 +1,76 @@
+package evaluator
+
+import (
+	"context"
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newReuseWeapon() *candidate_tree.CandidateTree {
+	stockSlot := &candidate_tree.ItemSlot{
+		Name: "slot-stock",
+		ID:   "slot-stock",
+		AllowedItems: []*candidate_tree.Item{
+			{Name: "comfy stock", ID: "item-comfy-stock", RecoilModifier: models.NewStat(-10), ErgonomicsModifier: models.NewStat(5), PriceRub: 9000},
+			{Name: "plain stock", ID: "item-plain-stock", RecoilModifier: models.NewStat(-10), PriceRub: 3000},
+		},
+	}
+	muzzleSlot := &candidate_tree.ItemSlot{
+		Name: "slot-muzzle",
+		ID:   "slot-muzzle",
+		AllowedItems: []*candidate_tree.Item{
+			{Name: "brake", ID: "item-brake", RecoilModifier: models.NewStat(-5), PriceRub: 10000},
+			{Name: "compensator", ID: "item-compensator", RecoilModifier: models.NewStat(-4), PriceRub: 1000},
+		},
+	}
+	weapon := &candidate_tree.CandidateTree{
+		Item: &candidate_tree.Item{Name: "Weapon", ID: "item-weapon", Type: "weapon", Slots: []*candidate_tree.ItemSlot{stockSlot, muzzleSlot}},
+	}
+	weapon.Item.CalculatePotentialValues()
+	return weapon
+}
+
+func itemIDs(build *Build) []string {
+	return chosenItemIDs(build.OptimalItems)
+}
+
+func TestFindReuseBuild_CheapestOfTheBest(t *testing.T) {
+	best := FindBestBuild(newReuseWeapon(), "recoil", map[string]bool{}, NewMemoryCache())
+	assert.ElementsMatch(t, []string{"item-comfy-stock", "item-brake"}, itemIDs(best))
+
+	// both stocks give the best recoil, the plain one is cheaper
+	build, err := FindReuseBuildContext(context.Background(), newReuseWeapon(), "recoil", map[string]bool{}, 0, map[string]bool{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"item-plain-stock", "item-brake"}, itemIDs(build))
+	assert.Equal(t, 13000, build.PurchaseCost)
+	assert.Equal(t, models.NewStat(-15), build.RecoilSum)
+}
+
+func TestFindReuseBuild_OwnedItemsAreFree(t *testing.T) {
+	owned := map[string]bool{"item-comfy-stock": true}
+
+	build, err := FindReuseBuildContext(context.Background(), newReuseWeapon(), "recoil", owned, 0, map[string]bool{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"item-comfy-stock", "item-brake"}, itemIDs(build))
+	assert.Equal(t, 10000, build.PurchaseCost)
+}
+
+func TestFindReuseBuild_Slack(t *testing.T) {
+	// a point of recoil allows the much cheaper compensator
+	build, err := FindReuseBuildContext(context.Background(), newReuseWeapon(), "recoil", map[string]bool{}, 1, map[string]bool{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"item-plain-stock", "item-compensator"}, itemIDs(build))
+	assert.Equal(t, 4000, build.PurchaseCost)
+	assert.Equal(t, models.NewStat(-14), build.RecoilSum)
+
+	// with everything owned the best build is free, so slack doesn't make it worse
+	owned := map[string]bool{"item-comfy-stock": true, "item-plain-stock": true, "item-brake": true, "item-compensator": true}
+	build, err = FindReuseBuildContext(context.Background(), newReuseWeapon(), "recoil", owned, 1, map[string]bool{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"item-comfy-stock", "item-brake"}, itemIDs(build))
+	assert.Equal(t, 0, build.PurchaseCost)
+}
@@ -0,0 +1,245 @@
+package evaluator
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"sync/atomic"
+	"tarkov-build-optimiser/internal/models"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultTieredCacheBatchSize     = 500
+	defaultTieredCacheFlushInterval = 2 * time.Second
+)
+
+// CacheStats holds counters describing how a TieredCache has been used
+type CacheStats struct {
+	Hits          int64 `json:"hits"`
+	BackingHits   int64 `json:"backing_hits"`
+	Misses        int64 `json:"misses"`
+	Evictions     int64 `json:"evictions"`
+	Writes        int64 `json:"writes"`
+	FailedFlushes int64 `json:"failed_flushes"`
+}
+
+type lruEntry struct {
+	key   string
+	entry *CacheEntry
+}
+
+// TieredCache implements Cache using a size-bounded in-memory LRU in front of a slower
+// backing cache (usually a DatabaseCache). Lookups which miss the LRU fall through to the
+// backing cache, and writes are persisted to it asynchronously in batches.
+//
+// Close must be called once the cache is no longer in use so pending writes are flushed. Searches which outlive
+// Close, such as those abandoned when shutting down, can still call Set but nothing more is cached.
+type TieredCache struct {
+	backing  BatchCache
+	capacity int
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List
+
+	writes        chan CacheWrite
+	batchSize     int
+	flushInterval time.Duration
+	flushed       chan struct{}
+	// closeMu stops Close closing writes while a Set is sending to it
+	closeMu sync.RWMutex
+	closed  bool
+
+	hits          atomic.Int64
+	backingHits   atomic.Int64
+	misses        atomic.Int64
+	evictions     atomic.Int64
+	writeCount    atomic.Int64
+	failedFlushes atomic.Int64
+}
+
+// NewTieredCache creates a TieredCache holding at most capacity entries in memory and starts
+// the background writer which persists entries to backing.
+func NewTieredCache(backing BatchCache, capacity int) *TieredCache {
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	c := &TieredCache{
+		backing:       backing,
+		capacity:      capacity,
+		entries:       make(map[string]*list.Element),
+		order:         list.New(),
+		writes:        make(chan CacheWrite, defaultTieredCacheBatchSize*4),
+		batchSize:     defaultTieredCacheBatchSize,
+		flushInterval: defaultTieredCacheFlushInterval,
+		flushed:       make(chan struct{}),
+	}
+
+	go c.writeBehind()
+
+	return c
+}
+
+// Get retrieves from the in-memory LRU, falling back to the backing cache
+func (c *TieredCache) Get(ctx context.Context, itemID string, focusedStat string, constraints models.EvaluationConstraints) (*CacheEntry, error) {
+	key := makeCacheKey(itemID, focusedStat, constraints)
+	if entry, ok := c.getLocal(key); ok {
+		c.hits.Add(1)
+		return entry, nil
+	}
+
+	entry, err := c.backing.Get(ctx, itemID, focusedStat, constraints)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		c.misses.Add(1)
+		return nil, nil
+	}
+
+	c.backingHits.Add(1)
+	c.setLocal(key, entry)
+	return entry, nil
+}
+
+// Set stores in the in-memory LRU and queues the entry to be written to the backing cache. Does nothing once the
+// cache is closed.
+func (c *TieredCache) Set(ctx context.Context, itemID string, focusedStat string, constraints models.EvaluationConstraints, entry *CacheEntry) error {
+	c.closeMu.RLock()
+	defer c.closeMu.RUnlock()
+	if c.closed {
+		return nil
+	}
+
+	c.setLocal(makeCacheKey(itemID, focusedStat, constraints), entry)
+	c.writeCount.Add(1)
+
+	select {
+	case c.writes <- CacheWrite{ItemID: itemID, FocusedStat: focusedStat, Constraints: constraints, Entry: entry}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Clear removes all entries from both the in-memory LRU and the backing cache
+func (c *TieredCache) Clear(ctx context.Context) error {
+	c.mu.Lock()
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+	c.mu.Unlock()
+
+	return c.backing.Clear(ctx)
+}
+
+// Stats returns a snapshot of the cache counters
+func (c *TieredCache) Stats() CacheStats {
+	return CacheStats{
+		Hits:          c.hits.Load(),
+		BackingHits:   c.backingHits.Load(),
+		Misses:        c.misses.Load(),
+		Evictions:     c.evictions.Load(),
+		Writes:        c.writeCount.Load(),
+		FailedFlushes: c.failedFlushes.Load(),
+	}
+}
+
+// Len returns the number of entries currently held in memory
+func (c *TieredCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Close stops accepting writes and blocks until all queued writes have been flushed to the
+// backing cache, or until ctx is done.
+func (c *TieredCache) Close(ctx context.Context) error {
+	c.closeMu.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.writes)
+	}
+	c.closeMu.Unlock()
+
+	select {
+	case <-c.flushed:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (c *TieredCache) getLocal(key string) (*CacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*lruEntry).entry, true
+}
+
+func (c *TieredCache) setLocal(key string, entry *CacheEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*lruEntry).entry = entry
+		c.order.MoveToFront(el)
+		return
+	}
+
+	c.entries[key] = c.order.PushFront(&lruEntry{key: key, entry: entry})
+
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+		c.evictions.Add(1)
+	}
+}
+
+// writeBehind batches queued writes and persists them to the backing cache until the
+// writes channel is closed.
+func (c *TieredCache) writeBehind() {
+	defer close(c.flushed)
+
+	ticker := time.NewTicker(c.flushInterval)
+	defer ticker.Stop()
+
+	batch := make([]CacheWrite, 0, c.batchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		err := c.backing.SetMany(context.Background(), batch)
+		if err != nil {
+			// the entries are still usable from memory, so a failed flush only costs a recomputation later
+			c.failedFlushes.Add(1)
+			log.Error().Err(err).Msgf("Failed to flush %d cache entries", len(batch))
+		}
+		batch = make([]CacheWrite, 0, c.batchSize)
+	}
+
+	for {
+		select {
+		case w, ok := <-c.writes:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, w)
+			if len(batch) >= c.batchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
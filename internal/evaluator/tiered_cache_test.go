@@ -0,0 +1,130 @@
+package evaluator
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingCache is a BatchCache backed by a MemoryCache which records batched writes
+type recordingCache struct {
+	*MemoryCache
+	mu      sync.Mutex
+	batches [][]CacheWrite
+}
+
+func newRecordingCache() *recordingCache {
+	return &recordingCache{MemoryCache: NewMemoryCache()}
+}
+
+func (r *recordingCache) SetMany(ctx context.Context, writes []CacheWrite) error {
+	r.mu.Lock()
+	r.batches = append(r.batches, append([]CacheWrite{}, writes...))
+	r.mu.Unlock()
+	return r.MemoryCache.SetMany(ctx, writes)
+}
+
+func (r *recordingCache) writtenCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	count := 0
+	for _, b := range r.batches {
+		count += len(b)
+	}
+	return count
+}
+
+func closeCache(t *testing.T, c *TieredCache) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, c.Close(ctx))
+}
+
+func TestTieredCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	ctx := context.Background()
+	constraints := createMockConstraints()
+	c := NewTieredCache(newRecordingCache(), 2)
+	t.Cleanup(func() { closeCache(t, c) })
+
+	require.NoError(t, c.Set(ctx, "a", "recoil", constraints, &CacheEntry{RecoilSum: -1}))
+	require.NoError(t, c.Set(ctx, "b", "recoil", constraints, &CacheEntry{RecoilSum: -2}))
+
+	// touch "a" so "b" becomes the least recently used entry
+	_, ok := c.getLocal(makeCacheKey("a", "recoil", constraints))
+	require.True(t, ok)
+
+	require.NoError(t, c.Set(ctx, "c", "recoil", constraints, &CacheEntry{RecoilSum: -3}))
+
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, int64(1), c.Stats().Evictions)
+
+	_, ok = c.getLocal(makeCacheKey("b", "recoil", constraints))
+	assert.False(t, ok, "expected b to be evicted")
+	_, ok = c.getLocal(makeCacheKey("a", "recoil", constraints))
+	assert.True(t, ok, "expected a to be retained")
+}
+
+func TestTieredCache_FallsBackToBackingCache(t *testing.T) {
+	ctx := context.Background()
+	constraints := createMockConstraints()
+	backing := newRecordingCache()
+	require.NoError(t, backing.Set(ctx, "persisted", "recoil", constraints, &CacheEntry{RecoilSum: -7, ErgonomicsSum: 3}))
+
+	c := NewTieredCache(backing, 10)
+	t.Cleanup(func() { closeCache(t, c) })
+
+	entry, err := c.Get(ctx, "persisted", "recoil", constraints)
+	require.NoError(t, err)
+	require.NotNil(t, entry)
+	assert.EqualValues(t, -7, entry.RecoilSum)
+
+	// second lookup is served from memory
+	_, err = c.Get(ctx, "persisted", "recoil", constraints)
+	require.NoError(t, err)
+
+	missing, err := c.Get(ctx, "missing", "recoil", constraints)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	stats := c.Stats()
+	assert.Equal(t, int64(1), stats.Hits)
+	assert.Equal(t, int64(1), stats.BackingHits)
+	assert.Equal(t, int64(1), stats.Misses)
+}
+
+func TestTieredCache_CloseFlushesPendingWrites(t *testing.T) {
+	ctx := context.Background()
+	constraints := createMockConstraints()
+	backing := newRecordingCache()
+	c := NewTieredCache(backing, 1)
+
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, c.Set(ctx, id, "recoil", constraints, &CacheEntry{RecoilSum: -1}))
+	}
+	closeCache(t, c)
+
+	assert.Equal(t, 3, backing.writtenCount())
+	for _, id := range []string{"a", "b", "c"} {
+		entry, err := backing.Get(ctx, id, "recoil", constraints)
+		require.NoError(t, err)
+		assert.NotNil(t, entry, "expected %s to be flushed to the backing cache", id)
+	}
+}
+
+func TestTieredCache_SetAfterCloseIsIgnored(t *testing.T) {
+	ctx := context.Background()
+	constraints := createMockConstraints()
+	backing := newRecordingCache()
+	c := NewTieredCache(backing, 1)
+	closeCache(t, c)
+
+	require.NotPanics(t, func() {
+		require.NoError(t, c.Set(ctx, "a", "recoil", constraints, &CacheEntry{RecoilSum: -1}))
+	})
+	assert.Equal(t, 0, backing.writtenCount())
+	assert.Equal(t, 0, c.Len())
+}
@@ -0,0 +1,347 @@
+package gunsmith
+
+import (
+	"context"
+	"fmt"
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/helpers"
+	"tarkov-build-optimiser/internal/models"
+)
+
+// Solution statuses
+const (
+	// StatusFeasible builds meet every requirement. The build is the best for the objective unless Optimal is false.
+	StatusFeasible = "feasible"
+	// StatusInfeasible tasks have no build meeting every requirement, Reason says why
+	StatusInfeasible = "infeasible"
+	// StatusUnknown searches were stopped before finding a build or proving there isn't one
+	StatusUnknown = "unknown"
+)
+
+// stat reads one of an item's stats and bounds what a slot can add to it
+type stat struct {
+	value func(item *candidate_tree.Item) float64
+	// least and most the slot can add, leaving it empty included
+	slotMin func(slot *candidate_tree.ItemSlot) float64
+	slotMax func(slot *candidate_tree.ItemSlot) float64
+}
+
+// tolerance is the amount a stat sum may be past a requirement's bound and still meet it, summing fractional
+// modifiers as floats can leave a build which meets the bound exactly slightly past it
+const tolerance = 1e-9
+
+// stats are the stats requirements can be placed on, by name
+var stats = map[string]stat{
+	"recoil": {
+		value:   func(item *candidate_tree.Item) float64 { return item.RecoilModifier.Points() },
+		slotMin: func(slot *candidate_tree.ItemSlot) float64 { return slot.PotentialValues.MinRecoil.Points() },
+		slotMax: func(slot *candidate_tree.ItemSlot) float64 { return slot.PotentialValues.MaxRecoil.Points() },
+	},
+	"ergonomics": {
+		value:   func(item *candidate_tree.Item) float64 { return item.ErgonomicsModifier.Points() },
+		slotMin: func(slot *candidate_tree.ItemSlot) float64 { return slot.PotentialValues.MinErgonomics.Points() },
+		slotMax: func(slot *candidate_tree.ItemSlot) float64 { return slot.PotentialValues.MaxErgonomics.Points() },
+	},
+	// in kg, an empty slot weighs nothing so slotMin is the lightest choice including leaving it empty
+	"weight": {
+		value:   func(item *candidate_tree.Item) float64 { return item.Weight },
+		slotMin: func(slot *candidate_tree.ItemSlot) float64 { return 0 },
+		slotMax: func(slot *candidate_tree.ItemSlot) float64 { return slot.PotentialValues.MaxWeight },
+	},
+}
+
+// Solution is the outcome of solving a task
+type Solution struct {
+	TaskID     string `json:"task_id"`
+	TaskName   string `json:"task_name"`
+	WeaponID   string `json:"weapon_id"`
+	WeaponName string `json:"weapon_name"`
+	// TraderLevels the weapon's mods were bought at
+	TraderLevels []models.TraderLevel `json:"trader_levels"`
+	// Status is one of the Status constants
+	Status string `json:"status"`
+	// Optimal is false when the search was stopped after finding a build but before proving it the best
+	Optimal bool `json:"optimal"`
+	// Reason explains why no build meets the task
+	Reason   string             `json:"reason,omitempty"`
+	Items    []SolutionItem     `json:"items"`
+	Stats    map[string]float64 `json:"stats"`
+	PriceRub int                `json:"price_rub"`
+	// Nodes is the number of partial builds visited by the search
+	Nodes int64 `json:"nodes"`
+}
+
+type SolutionItem struct {
+	ID       string `json:"item_id"`
+	Name     string `json:"name"`
+	SlotID   string `json:"slot_id"`
+	SlotName string `json:"slot_name"`
+	PriceRub int    `json:"price_rub"`
+}
+
+type pick struct {
+	item *candidate_tree.Item
+	slot *candidate_tree.ItemSlot
+}
+
+type build struct {
+	picks []pick
+	// sums of each stat, in statNames order
+	sums  []float64
+	price int
+}
+
+type solver struct {
+	ctx       context.Context
+	task      Task
+	weapon    *candidate_tree.CandidateTree
+	names     []string
+	slotItems map[string]map[string]bool
+	best      *build
+	nodes     int64
+}
+
+// Solve searches the weapon's candidate tree for the build which meets every requirement of the task and is best for
+// its objective. The tree should be unpruned, see candidate_tree.CreateFullWeaponCandidateTree. When there is no such
+// build the solution is StatusInfeasible, with the bound or required item ruling it out or, failing those, after the
+// whole tree was searched. If ctx is done first the best build found so far is returned, not Optimal.
+func Solve(ctx context.Context, weapon *candidate_tree.CandidateTree, task Task) *Solution {
+	weapon.UpdateAllowedItems()
+	weapon.UpdateAllowedItemSlots()
+
+	s := &solver{
+		ctx:       ctx,
+		task:      task,
+		weapon:    weapon,
+		names:     statNames(),
+		slotItems: slotDescendantItemIDs(weapon),
+	}
+	solution := &Solution{
+		TaskID:       task.ID,
+		TaskName:     task.Name,
+		WeaponID:     weapon.Item.ID,
+		WeaponName:   weapon.Item.Name,
+		TraderLevels: weapon.Constraints.TraderLevels,
+		Items:        make([]SolutionItem, 0),
+		Stats:        make(map[string]float64),
+	}
+
+	reason := s.ruleOut()
+	if reason != "" {
+		solution.Status = StatusInfeasible
+		solution.Reason = reason
+		return solution
+	}
+
+	sums := make([]float64, len(s.names))
+	for i, name := range s.names {
+		sums[i] = stats[name].value(weapon.Item)
+	}
+	s.search(weapon.Item.Slots, nil, sums, 0, map[string]bool{})
+	solution.Nodes = s.nodes
+
+	stopped := ctx.Err() != nil
+	if s.best == nil {
+		if stopped {
+			solution.Status = StatusUnknown
+			solution.Reason = fmt.Sprintf("search stopped after %d partial builds: %v", s.nodes, ctx.Err())
+			return solution
+		}
+		solution.Status = StatusInfeasible
+		solution.Reason = fmt.Sprintf("no combination of the %d allowed items meets every requirement", weapon.Size().AllowedItems)
+		return solution
+	}
+
+	solution.Status = StatusFeasible
+	solution.Optimal = !stopped
+	solution.PriceRub = s.best.price
+	for i, name := range s.names {
+		solution.Stats[name] = s.best.sums[i]
+	}
+	for _, p := range s.best.picks {
+		solution.Items = append(solution.Items, SolutionItem{
+			ID:       p.item.ID,
+			Name:     p.item.Name,
+			SlotID:   p.slot.ID,
+			SlotName: p.slot.Name,
+			PriceRub: p.item.PriceRub,
+		})
+	}
+	return solution
+}
+
+// ruleOut returns why the task can't be met before searching, from required items which can't be fitted or
+// requirements which are out of reach even if every slot held the best item for that stat alone
+func (s *solver) ruleOut() string {
+	for _, id := range s.task.RequiredItemIDs {
+		if s.weapon.GetAllowedItem(id) == nil {
+			return fmt.Sprintf("required item %s can't be fitted to %s within the constraints", id, s.weapon.Item.Name)
+		}
+	}
+
+	for _, r := range s.task.Requirements {
+		st := stats[r.Stat]
+		low, high := st.value(s.weapon.Item), st.value(s.weapon.Item)
+		for _, slot := range s.weapon.Item.Slots {
+			low += st.slotMin(slot)
+			high += st.slotMax(slot)
+		}
+		if r.Max != nil && low > *r.Max+tolerance {
+			return fmt.Sprintf("%s can't go below %g, task needs %s", r.Stat, low, r)
+		}
+		if r.Min != nil && high < *r.Min-tolerance {
+			return fmt.Sprintf("%s can't go above %g, task needs %s", r.Stat, high, r)
+		}
+	}
+
+	return ""
+}
+
+// search tries every item, and leaving the slot empty, in each of slots in turn, recording the best build meeting
+// the task in s.best
+func (s *solver) search(slots []*candidate_tree.ItemSlot, picks []pick, sums []float64, price int, excluded map[string]bool) {
+	if s.ctx.Err() != nil {
+		return
+	}
+	s.nodes++
+
+	if !s.canMeet(slots, picks, sums, price) {
+		return
+	}
+
+	if len(slots) == 0 {
+		s.best = &build{picks: append([]pick{}, picks...), sums: append([]float64{}, sums...), price: price}
+		return
+	}
+
+	slot := slots[0]
+	remaining := slots[1:]
+
+	for _, item := range slot.AllowedItems {
+		if excluded[item.ID] || conflictsWithPicks(item, picks) {
+			continue
+		}
+
+		newExcluded := excluded
+		if len(item.ConflictingItems) > 0 {
+			newExcluded = helpers.CloneMap(excluded)
+			for _, c := range item.ConflictingItems {
+				newExcluded[c.ID] = true
+			}
+		}
+
+		newSums := make([]float64, len(sums))
+		for i, name := range s.names {
+			newSums[i] = sums[i] + stats[name].value(item)
+		}
+
+		newSlots := append([]*candidate_tree.ItemSlot{}, item.Slots...)
+		newSlots = append(newSlots, remaining...)
+
+		newPicks := append(picks[:len(picks):len(picks)], pick{item: item, slot: slot})
+		s.search(newSlots, newPicks, newSums, price+item.PriceRub, newExcluded)
+	}
+
+	// leave the slot empty
+	s.search(remaining, picks, sums, price, excluded)
+}
+
+// canMeet reports whether filling slots could still complete a build which meets the task and beats s.best
+func (s *solver) canMeet(slots []*candidate_tree.ItemSlot, picks []pick, sums []float64, price int) bool {
+	for _, r := range s.task.Requirements {
+		low, high := s.bounds(r.Stat, slots, sums)
+		if r.Max != nil && low > *r.Max+tolerance {
+			return false
+		}
+		if r.Min != nil && high < *r.Min-tolerance {
+			return false
+		}
+	}
+
+	for _, id := range s.task.RequiredItemIDs {
+		if !picked(picks, id) && !s.reachable(id, slots) {
+			return false
+		}
+	}
+
+	if s.best == nil {
+		return true
+	}
+
+	switch s.task.Objective {
+	case ObjectiveRecoil:
+		low, _ := s.bounds("recoil", slots, sums)
+		return low < s.best.sums[s.index("recoil")]
+	case ObjectiveErgonomics:
+		_, high := s.bounds("ergonomics", slots, sums)
+		return high > s.best.sums[s.index("ergonomics")]
+	case ObjectiveWeight:
+		low, _ := s.bounds("weight", slots, sums)
+		return low < s.best.sums[s.index("weight")]
+	default:
+		// empty slots are free, so the price can only go up from here
+		return price < s.best.price
+	}
+}
+
+// bounds returns the lowest and highest the stat can end up after filling slots
+func (s *solver) bounds(name string, slots []*candidate_tree.ItemSlot, sums []float64) (float64, float64) {
+	st := stats[name]
+	low, high := sums[s.index(name)], sums[s.index(name)]
+	for _, slot := range slots {
+		low += st.slotMin(slot)
+		high += st.slotMax(slot)
+	}
+	return low, high
+}
+
+func (s *solver) index(name string) int {
+	for i, n := range s.names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// reachable reports whether the item can still be fitted in one of slots or below them
+func (s *solver) reachable(itemID string, slots []*candidate_tree.ItemSlot) bool {
+	for _, slot := range slots {
+		if s.slotItems[slot.ID][itemID] {
+			return true
+		}
+	}
+	return false
+}
+
+func picked(picks []pick, itemID string) bool {
+	for _, p := range picks {
+		if p.item.ID == itemID {
+			return true
+		}
+	}
+	return false
+}
+
+func conflictsWithPicks(item *candidate_tree.Item, picks []pick) bool {
+	for _, conflict := range item.ConflictingItems {
+		if picked(picks, conflict.ID) {
+			return true
+		}
+	}
+	return false
+}
+
+// slotDescendantItemIDs returns the IDs of every item which can be fitted in or below each slot of the tree
+func slotDescendantItemIDs(weapon *candidate_tree.CandidateTree) map[string]map[string]bool {
+	slotItems := make(map[string]map[string]bool)
+	for _, slot := range weapon.Item.GetDescendantSlots() {
+		if _, ok := slotItems[slot.ID]; !ok {
+			slotItems[slot.ID] = make(map[string]bool)
+		}
+		for _, item := range slot.GetDescendantAllowedItems() {
+			slotItems[slot.ID][item.ID] = true
+		}
+	}
+	return slotItems
+}
@@ -0,0 +1,145 @@
+package gunsmith
+
+import (
+	"context"
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func points(v float64) models.Stat {
+	return models.NewStat(v)
+}
+
+// newTestWeapon has a stock slot, and a handguard slot whose handguards take a foregrip
+func newTestWeapon() *candidate_tree.CandidateTree {
+	grip := &candidate_tree.Item{ID: "grip", Name: "grip", RecoilModifier: points(-3), ErgonomicsModifier: points(5), PriceRub: 3000, Weight: 0.1}
+	handguard := &candidate_tree.Item{
+		ID: "handguard", Name: "handguard", RecoilModifier: points(0), ErgonomicsModifier: points(8), PriceRub: 5000, Weight: 0.3,
+		Slots: []*candidate_tree.ItemSlot{{ID: "slot-foregrip", Name: "Foregrip", AllowedItems: []*candidate_tree.Item{grip}}},
+	}
+	cheapStock := &candidate_tree.Item{ID: "cheap-stock", Name: "cheap stock", RecoilModifier: points(-5), ErgonomicsModifier: points(2), PriceRub: 1000, Weight: 0.4}
+	heavyStock := &candidate_tree.Item{
+		ID: "heavy-stock", Name: "heavy stock", RecoilModifier: points(-12), ErgonomicsModifier: points(-4), PriceRub: 9000, Weight: 0.9,
+		ConflictingItems: []candidate_tree.ConflictingItem{{ID: "grip"}},
+	}
+
+	weapon := &candidate_tree.CandidateTree{Item: &candidate_tree.Item{
+		ID: "weapon", Name: "weapon", Type: "weapon", ErgonomicsModifier: points(40), Weight: 3,
+		Slots: []*candidate_tree.ItemSlot{
+			{ID: "slot-stock", Name: "Stock", AllowedItems: []*candidate_tree.Item{heavyStock, cheapStock}},
+			{ID: "slot-handguard", Name: "Handguard", AllowedItems: []*candidate_tree.Item{handguard}},
+		},
+	}}
+	weapon.Item.CalculatePotentialValues()
+	return weapon
+}
+
+func itemIDs(solution *Solution) []string {
+	ids := make([]string, 0, len(solution.Items))
+	for _, item := range solution.Items {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+func TestSolve_CheapestBuildMeetingRequirements(t *testing.T) {
+	task := Task{
+		ID:           "task",
+		WeaponID:     "weapon",
+		Requirements: []StatRequirement{{Stat: "ergonomics", Min: floatPtr(50)}, {Stat: "recoil", Max: floatPtr(-5)}},
+		Objective:    ObjectivePrice,
+	}
+
+	solution := Solve(context.Background(), newTestWeapon(), task)
+
+	require.Equal(t, StatusFeasible, solution.Status, solution.Reason)
+	assert.True(t, solution.Optimal)
+	// the cheap stock and the handguard alone reach the ergonomics, the grip isn't needed
+	assert.ElementsMatch(t, []string{"cheap-stock", "handguard"}, itemIDs(solution))
+	assert.Equal(t, 6000, solution.PriceRub)
+	assert.Equal(t, 50.0, solution.Stats["ergonomics"])
+	assert.Equal(t, -5.0, solution.Stats["recoil"])
+}
+
+func TestSolve_RecoilObjectiveRespectsConflictsAndRequiredItems(t *testing.T) {
+	task := Task{ID: "task", WeaponID: "weapon", Objective: ObjectiveRecoil}
+	solution := Solve(context.Background(), newTestWeapon(), task)
+	require.Equal(t, StatusFeasible, solution.Status)
+	// the heavy stock beats the cheap stock and grip together
+	assert.ElementsMatch(t, []string{"heavy-stock", "handguard"}, itemIDs(solution))
+
+	task.RequiredItemIDs = []string{"grip"}
+	solution = Solve(context.Background(), newTestWeapon(), task)
+	require.Equal(t, StatusFeasible, solution.Status)
+	assert.ElementsMatch(t, []string{"cheap-stock", "handguard", "grip"}, itemIDs(solution))
+	assert.Equal(t, -8.0, solution.Stats["recoil"])
+}
+
+func TestSolve_Weight(t *testing.T) {
+	// every build with the heavy stock or the handguard is over the limit
+	task := Task{ID: "task", WeaponID: "weapon", Requirements: []StatRequirement{{Stat: "weight", Max: floatPtr(3.6)}}, Objective: ObjectiveRecoil}
+	solution := Solve(context.Background(), newTestWeapon(), task)
+	require.Equal(t, StatusFeasible, solution.Status, solution.Reason)
+	assert.ElementsMatch(t, []string{"cheap-stock"}, itemIDs(solution))
+	assert.InDelta(t, 3.4, solution.Stats["weight"], 1e-9)
+
+	task = Task{ID: "task", WeaponID: "weapon", Requirements: []StatRequirement{{Stat: "recoil", Max: floatPtr(-10)}}, Objective: ObjectiveWeight}
+	solution = Solve(context.Background(), newTestWeapon(), task)
+	require.Equal(t, StatusFeasible, solution.Status, solution.Reason)
+	// only the heavy stock reaches the recoil, and the handguard would only add weight
+	assert.ElementsMatch(t, []string{"heavy-stock"}, itemIDs(solution))
+	assert.InDelta(t, 3.9, solution.Stats["weight"], 1e-9)
+
+	task.Requirements = []StatRequirement{{Stat: "weight", Max: floatPtr(2.5)}}
+	solution = Solve(context.Background(), newTestWeapon(), task)
+	assert.Equal(t, StatusInfeasible, solution.Status)
+	assert.Equal(t, "weight can't go below 3, task needs weight <= 2.5", solution.Reason)
+}
+
+func TestSolve_Infeasible(t *testing.T) {
+	tests := map[string]struct {
+		task   Task
+		reason string
+	}{
+		"out of reach": {
+			task:   Task{ID: "task", Requirements: []StatRequirement{{Stat: "ergonomics", Min: floatPtr(60)}}},
+			reason: "ergonomics can't go above 55, task needs ergonomics >= 60",
+		},
+		"required item missing": {
+			task:   Task{ID: "task", RequiredItemIDs: []string{"scope"}},
+			reason: "required item scope can't be fitted to weapon within the constraints",
+		},
+		"requirements conflict": {
+			// only the heavy stock reaches the recoil, and it conflicts with the grip
+			task:   Task{ID: "task", Requirements: []StatRequirement{{Stat: "recoil", Max: floatPtr(-12)}}, RequiredItemIDs: []string{"grip"}},
+			reason: "no combination of the 4 allowed items meets every requirement",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			tt.task.Objective = ObjectivePrice
+			solution := Solve(context.Background(), newTestWeapon(), tt.task)
+			assert.Equal(t, StatusInfeasible, solution.Status)
+			assert.Equal(t, tt.reason, solution.Reason)
+			assert.Empty(t, solution.Items)
+		})
+	}
+}
+
+func TestSolve_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	solution := Solve(ctx, newTestWeapon(), Task{ID: "task", Objective: ObjectivePrice})
+	assert.Equal(t, StatusUnknown, solution.Status)
+	assert.False(t, solution.Optimal)
+}
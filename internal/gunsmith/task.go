@@ -0,0 +1,151 @@
+package gunsmith
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Objectives a task's valid builds are ranked by
+const (
+	ObjectivePrice      = "price"
+	ObjectiveRecoil     = "recoil"
+	ObjectiveErgonomics = "ergonomics"
+	ObjectiveWeight     = "weight"
+)
+
+// Task is a gunsmith task, a weapon which has to be built to meet several requirements at once
+type Task struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	WeaponID string `json:"weapon_id"`
+	// Requirements are thresholds on the build's stats, all of which must be met
+	Requirements []StatRequirement `json:"requirements"`
+	// RequiredItemIDs must all be fitted to the build
+	RequiredItemIDs []string `json:"required_item_ids"`
+	// Objective ranks the valid builds, one of the Objective constants. Defaults to ObjectivePrice.
+	Objective string `json:"objective"`
+}
+
+// StatRequirement bounds one of the build's stats, summed over the weapon and its mods like the evaluator's sums
+type StatRequirement struct {
+	Stat string   `json:"stat"`
+	Min  *float64 `json:"min,omitempty"`
+	Max  *float64 `json:"max,omitempty"`
+}
+
+func (r StatRequirement) String() string {
+	switch {
+	case r.Min != nil && r.Max != nil:
+		return fmt.Sprintf("%g <= %s <= %g", *r.Min, r.Stat, *r.Max)
+	case r.Min != nil:
+		return fmt.Sprintf("%s >= %g", r.Stat, *r.Min)
+	case r.Max != nil:
+		return fmt.Sprintf("%s <= %g", r.Stat, *r.Max)
+	}
+	return r.Stat
+}
+
+// LoadTasks reads tasks from a JSON file holding an array of tasks, so tasks can be defined without tarkov.dev
+func LoadTasks(path string) ([]Task, error) {
+	file, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks := make([]Task, 0)
+	err = json.Unmarshal(file, &tasks)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tasks file %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool, len(tasks))
+	for i := range tasks {
+		err := tasks[i].validate()
+		if err != nil {
+			return nil, fmt.Errorf("task %d in %s: %w", i, path, err)
+		}
+		if seen[tasks[i].ID] {
+			return nil, fmt.Errorf("task %q is defined more than once in %s", tasks[i].ID, path)
+		}
+		seen[tasks[i].ID] = true
+	}
+
+	return tasks, nil
+}
+
+// SelectTasks returns the tasks with the given IDs, or every task when no IDs are given
+func SelectTasks(tasks []Task, ids []string) ([]Task, error) {
+	if len(ids) == 0 {
+		return tasks, nil
+	}
+
+	byID := make(map[string]Task, len(tasks))
+	for _, task := range tasks {
+		byID[task.ID] = task
+	}
+
+	selected := make([]Task, 0, len(ids))
+	for _, id := range ids {
+		task, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("no task with id %q", id)
+		}
+		selected = append(selected, task)
+	}
+	return selected, nil
+}
+
+// validate checks the task can be solved, defaulting its objective
+func (t *Task) validate() error {
+	if t.ID == "" {
+		return errors.New("id is required")
+	}
+	if t.WeaponID == "" {
+		return fmt.Errorf("task %q: weapon_id is required", t.ID)
+	}
+
+	if t.Objective == "" {
+		t.Objective = ObjectivePrice
+	}
+	switch t.Objective {
+	case ObjectivePrice, ObjectiveRecoil, ObjectiveErgonomics, ObjectiveWeight:
+	default:
+		return fmt.Errorf("task %q: unknown objective %q", t.ID, t.Objective)
+	}
+
+	for _, r := range t.Requirements {
+		if sizeStats[r.Stat] {
+			return fmt.Errorf("task %q: %s requirements can't be solved, tarkov.dev doesn't give how far each mod extends "+
+				"the weapon so a build's size isn't known", t.ID, r.Stat)
+		}
+		if _, ok := stats[r.Stat]; !ok {
+			return fmt.Errorf("task %q: unsupported stat %q, expected one of %s", t.ID, r.Stat, strings.Join(statNames(), ", "))
+		}
+		if r.Min == nil && r.Max == nil {
+			return fmt.Errorf("task %q: requirement on %s needs a min or a max", t.ID, r.Stat)
+		}
+		if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+			return fmt.Errorf("task %q: requirement %s can never be met", t.ID, r)
+		}
+	}
+
+	return nil
+}
+
+// sizeStats are the names gunsmith tasks bound a build's size by. A mod's inventory size isn't how far it extends the
+// weapon, and extensions in the same direction don't add up, so without the game's per-mod extensions these are
+// rejected rather than solved with a made up size.
+var sizeStats = map[string]bool{"size": true, "width": true, "height": true}
+
+func statNames() []string {
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
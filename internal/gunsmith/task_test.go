@@ -0,0 +1,73 @@
+package gunsmith
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTasksFile(t *testing.T, contents string) string {
+	path := filepath.Join(t.TempDir(), "tasks.json")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o644))
+	return path
+}
+
+func TestLoadTasks(t *testing.T) {
+	path := writeTasksFile(t, `[
+		{
+			"id": "gunsmith-1",
+			"name": "Gunsmith - Part 1",
+			"weapon_id": "weapon",
+			"requirements": [{"stat": "ergonomics", "min": 40}, {"stat": "recoil", "max": -10}],
+			"required_item_ids": ["grip"]
+		},
+		{"id": "gunsmith-2", "weapon_id": "weapon", "objective": "recoil"}
+	]`)
+
+	tasks, err := LoadTasks(path)
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, ObjectivePrice, tasks[0].Objective, "expected the objective to default to price")
+	assert.Equal(t, "ergonomics >= 40", tasks[0].Requirements[0].String())
+	assert.Equal(t, "recoil <= -10", tasks[0].Requirements[1].String())
+	assert.Equal(t, []string{"grip"}, tasks[0].RequiredItemIDs)
+
+	selected, err := SelectTasks(tasks, []string{"gunsmith-2"})
+	require.NoError(t, err)
+	require.Len(t, selected, 1)
+	assert.Equal(t, ObjectiveRecoil, selected[0].Objective)
+
+	_, err = SelectTasks(tasks, []string{"gunsmith-3"})
+	assert.Error(t, err)
+}
+
+func TestLoadTasks_Errors(t *testing.T) {
+	tests := map[string]string{
+		"not json":          `{`,
+		"missing id":        `[{"weapon_id": "weapon"}]`,
+		"missing weapon":    `[{"id": "a"}]`,
+		"duplicate id":      `[{"id": "a", "weapon_id": "weapon"}, {"id": "a", "weapon_id": "weapon"}]`,
+		"unknown objective": `[{"id": "a", "weapon_id": "weapon", "objective": "looks"}]`,
+		"unsupported stat":  `[{"id": "a", "weapon_id": "weapon", "requirements": [{"stat": "velocity", "max": 5}]}]`,
+		"no bound":          `[{"id": "a", "weapon_id": "weapon", "requirements": [{"stat": "recoil"}]}]`,
+		"empty range":       `[{"id": "a", "weapon_id": "weapon", "requirements": [{"stat": "recoil", "min": 5, "max": 1}]}]`,
+	}
+
+	for name, contents := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := LoadTasks(writeTasksFile(t, contents))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadTasks_SizeRequirement(t *testing.T) {
+	for _, stat := range []string{"size", "width", "height"} {
+		_, err := LoadTasks(writeTasksFile(t, `[{"id": "a", "weapon_id": "weapon", "requirements": [{"stat": "`+stat+`", "max": 4}]}]`))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "extends the weapon", "expected %s to be rejected as unsolvable, not unknown", stat)
+	}
+}
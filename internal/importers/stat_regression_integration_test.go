@@ -0,0 +1,92 @@
+package importers
+
+import (
+	"testing"
+
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/db"
+	"tarkov-build-optimiser/internal/env"
+	"tarkov-build-optimiser/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestImportedStatsIntegration verifies the imported database holds the fixture's modifiers exactly and that the
+// fixture weapon's builds, searched over the database with everything outside the fixture left out, match the game
+func TestImportedStatsIntegration(t *testing.T) {
+	environment, err := env.Get()
+	require.NoError(t, err, "Failed to get environment")
+
+	dbClient, err := db.CreateBuildOptimiserDBClient(environment)
+	require.NoError(t, err, "Failed to connect to database")
+	conn := dbClient.Conn
+
+	weapons, mods := importFixture(t)
+
+	for _, weapon := range weapons {
+		imported, err := models.GetWeaponById(conn, weapon.ID)
+		require.NoError(t, err)
+		assert.Equal(t, weapon.RecoilVertical, imported.RecoilVertical, weapon.Name)
+		assert.Equal(t, weapon.RecoilHorizontal, imported.RecoilHorizontal, weapon.Name)
+		assert.Equal(t, weapon.Ergonomics, imported.Ergonomics, weapon.Name)
+	}
+
+	for _, mod := range mods {
+		imported, err := models.GetWeaponModById(conn, mod.ID)
+		require.NoError(t, err)
+		assert.Equal(t, mod.RecoilModifier, imported.RecoilModifier, mod.Name)
+		assert.Equal(t, mod.ErgonomicsModifier, imported.ErgonomicsModifier, mod.Name)
+	}
+
+	dataService := candidate_tree.CreateDataService(conn)
+	constraints := models.EvaluationConstraints{TraderLevels: maxTraderLevels()}
+
+	// leave out every slot and item of the fixture's items the fixture doesn't have
+	fixtureSlots := make(map[string][]models.Slot)
+	fixtureSlotNames := make(map[string]bool)
+	fixtureItems := make(map[string]bool)
+	for _, weapon := range weapons {
+		fixtureSlots[weapon.ID] = weapon.Slots
+	}
+	for _, mod := range mods {
+		fixtureSlots[mod.ID] = mod.Slots
+	}
+	for _, slots := range fixtureSlots {
+		for _, slot := range slots {
+			fixtureSlotNames[slot.Name] = true
+			for _, item := range slot.AllowedItems {
+				fixtureItems[item.ID] = true
+			}
+		}
+	}
+	for itemID := range fixtureSlots {
+		slots, err := dataService.GetSlotsByItemID(itemID)
+		require.NoError(t, err)
+		for _, slot := range slots {
+			if !fixtureSlotNames[slot.Name] {
+				constraints.IgnoredSlotNames = append(constraints.IgnoredSlotNames, slot.Name)
+				continue
+			}
+			allowedItems, err := dataService.GetAllowedItemsBySlotID(slot.ID)
+			require.NoError(t, err)
+			for _, item := range allowedItems {
+				if !fixtureItems[item.ID] {
+					constraints.IgnoredItemIDs = append(constraints.IgnoredItemIDs, item.ID)
+				}
+			}
+		}
+	}
+
+	assertFixtureBuilds(t, dataService, constraints)
+}
+
+func maxTraderLevels() []models.TraderLevel {
+	return []models.TraderLevel{
+		{Name: "Jaeger", Level: 4},
+		{Name: "Prapor", Level: 4},
+		{Name: "Skier", Level: 4},
+		{Name: "Peacekeeper", Level: 4},
+		{Name: "Mechanic", Level: 4},
+	}
+}
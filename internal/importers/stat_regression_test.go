@@ -0,0 +1,201 @@
+package importers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/evaluator"
+	"tarkov-build-optimiser/internal/models"
+	"tarkov-build-optimiser/internal/stats"
+	"tarkov-build-optimiser/internal/tarkovdev"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// The fixtures are tarkov.dev's GetWeapons and GetWeaponMods responses trimmed to an M4A1 with its pistol grip and
+// stock slots, and the A2 buffer tube's stock slot. TestImportedStatsIntegration checks them against an import.
+const (
+	fixtureWeaponID = "5447a9cd4bdc2dbd208b4567"
+	weaponsFixture  = "m4a1_weapons.json"
+	modsFixture     = "m4a1_mods.json"
+)
+
+// fixtureBuild is the best build of the fixture weapon for a build type, with the sums of its mods' modifiers and
+// the stats the game shows on the weapon's inspect screen with the build's mods fitted
+type fixtureBuild struct {
+	buildType     string
+	itemIDs       []string
+	recoilSum     models.Stat
+	ergonomicsSum models.Stat
+	final         stats.Final
+}
+
+var fixtureBuilds = []fixtureBuild{
+	{
+		// the grips don't change recoil, so are pruned from recoil builds
+		buildType:     "recoil",
+		itemIDs:       []string{"5649be884bdc2d79388b4577", "5ae30c9a5acfc408fb139a03"},
+		recoilSum:     models.NewStat(-10.5),
+		ergonomicsSum: models.NewStat(8),
+		final:         stats.Final{RecoilVertical: 50, RecoilHorizontal: 205, Ergonomics: 58},
+	},
+	{
+		buildType:     "ergonomics",
+		itemIDs:       []string{"5b07db875acfc40dc528a5f6", "5649be884bdc2d79388b4577", "56eabf3bd2720b75698b4569"},
+		recoilSum:     models.NewStat(-7.5),
+		ergonomicsSum: models.NewStat(21),
+		final:         stats.Final{RecoilVertical: 52, RecoilHorizontal: 212, Ergonomics: 71},
+	},
+}
+
+func readFixture(t *testing.T, name string, v interface{}) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(data, v))
+}
+
+func importFixture(t *testing.T) ([]models.Weapon, []models.WeaponMod) {
+	t.Helper()
+
+	var weaponsRes tarkovdev.GetWeaponsResponse
+	readFixture(t, weaponsFixture, &weaponsRes)
+	weapons, err := convertWeapons(&weaponsRes)
+	require.NoError(t, err)
+
+	var modsRes tarkovdev.GetWeaponModsResponse
+	readFixture(t, modsFixture, &modsRes)
+	mods, err := convertWeaponMods(&modsRes)
+	require.NoError(t, err)
+
+	return weapons, mods
+}
+
+// importedData serves imported weapons and mods as the database would after an import. Prapor sells every mod at
+// level 1.
+type importedData struct {
+	weapons      map[string]*models.Weapon
+	mods         map[string]*models.WeaponMod
+	allowedItems map[string][]*models.AllowedItem
+}
+
+func newImportedData(weapons []models.Weapon, mods []models.WeaponMod) *importedData {
+	d := &importedData{
+		weapons:      make(map[string]*models.Weapon),
+		mods:         make(map[string]*models.WeaponMod),
+		allowedItems: make(map[string][]*models.AllowedItem),
+	}
+	addSlots := func(slots []models.Slot) {
+		for _, slot := range slots {
+			for i := range slot.AllowedItems {
+				d.allowedItems[slot.ID] = append(d.allowedItems[slot.ID], &slot.AllowedItems[i])
+			}
+		}
+	}
+	for i := range weapons {
+		d.weapons[weapons[i].ID] = &weapons[i]
+		addSlots(weapons[i].Slots)
+	}
+	for i := range mods {
+		d.mods[mods[i].ID] = &mods[i]
+		addSlots(mods[i].Slots)
+	}
+	return d
+}
+
+func (d *importedData) GetWeaponById(id string) (*models.Weapon, error) {
+	weapon, ok := d.weapons[id]
+	if !ok {
+		return nil, fmt.Errorf("No results for %s", id)
+	}
+	return weapon, nil
+}
+
+func (d *importedData) GetSlotsByItemID(id string) ([]models.Slot, error) {
+	if weapon, ok := d.weapons[id]; ok {
+		return weapon.Slots, nil
+	}
+	if mod, ok := d.mods[id]; ok && mod.Slots != nil {
+		return mod.Slots, nil
+	}
+	return []models.Slot{}, nil
+}
+
+func (d *importedData) GetWeaponModById(id string) (*models.WeaponMod, error) {
+	mod, ok := d.mods[id]
+	if !ok {
+		return nil, fmt.Errorf("weapon mod with id %s not found", id)
+	}
+	return mod, nil
+}
+
+func (d *importedData) GetAllowedItemsBySlotID(id string) ([]*models.AllowedItem, error) {
+	return d.allowedItems[id], nil
+}
+
+func (d *importedData) GetTraderOffer(id string) ([]models.TraderOffer, error) {
+	return []models.TraderOffer{{ID: id, Trader: "Prapor", MinTraderLevel: 1, PriceRub: 1000}}, nil
+}
+
+func (d *importedData) IsWeapon(id string) (bool, error) {
+	_, ok := d.weapons[id]
+	return ok, nil
+}
+
+func buildItemIDs(build *evaluator.Build) []string {
+	ids := make([]string, 0, len(build.OptimalItems))
+	for _, item := range build.OptimalItems {
+		ids = append(ids, item.ID)
+	}
+	return ids
+}
+
+// assertFixtureBuilds finds the fixture weapon's best builds from data and checks them against fixtureBuilds
+func assertFixtureBuilds(t *testing.T, data candidate_tree.TreeDataProvider, constraints models.EvaluationConstraints) {
+	t.Helper()
+
+	weapon, err := data.GetWeaponById(fixtureWeaponID)
+	require.NoError(t, err)
+
+	for _, expected := range fixtureBuilds {
+		tree, err := candidate_tree.CreateWeaponCandidateTree(fixtureWeaponID, expected.buildType, constraints, data)
+		require.NoError(t, err)
+
+		build := evaluator.FindBestBuild(tree, expected.buildType, map[string]bool{}, evaluator.NewMemoryCache())
+		require.NotNil(t, build, expected.buildType)
+		assert.ElementsMatch(t, expected.itemIDs, buildItemIDs(build), expected.buildType)
+		assert.Equal(t, expected.recoilSum, build.RecoilSum, expected.buildType)
+		assert.Equal(t, expected.ergonomicsSum, build.ErgonomicsSum, expected.buildType)
+
+		evaluated, err := build.ToEvaluatedWeapon()
+		require.NoError(t, err)
+		result := evaluated.ToItemEvaluationResult()
+		assert.Equal(t, expected.final, stats.Compute(weapon, &result), expected.buildType)
+	}
+}
+
+func TestConvertWeaponMods_KeepsFractionalModifiers(t *testing.T) {
+	_, mods := importFixture(t)
+
+	modifiers := make(map[string][2]models.Stat)
+	for _, mod := range mods {
+		modifiers[mod.ID] = [2]models.Stat{mod.RecoilModifier, mod.ErgonomicsModifier}
+	}
+	assert.Equal(t, [2]models.Stat{models.NewStat(-7.5), models.NewStat(11)}, modifiers["56eabf3bd2720b75698b4569"])
+	assert.Equal(t, [2]models.Stat{models.NewStat(-10.5), models.NewStat(8)}, modifiers["5ae30c9a5acfc408fb139a03"])
+}
+
+func TestFixtureBuilds_MatchGame(t *testing.T) {
+	weapons, mods := importFixture(t)
+	require.Len(t, weapons, 1)
+	assert.Equal(t, 56, weapons[0].RecoilVertical)
+	assert.Equal(t, 229, weapons[0].RecoilHorizontal)
+	assert.Equal(t, 50.0, weapons[0].Ergonomics)
+
+	constraints := models.EvaluationConstraints{TraderLevels: []models.TraderLevel{{Name: "Prapor", Level: 1}}}
+	assertFixtureBuilds(t, newImportedData(weapons, mods), constraints)
+}
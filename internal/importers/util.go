@@ -25,8 +25,9 @@ func convertPropertiesToSlots(properties interface{}) ([]models.Slot, error) {
 
 		id := slot.FieldByName("Id").String()
 		name := slot.FieldByName("Name").String()
+		required := slot.FieldByName("Required").Bool()
 
-		newSlot := models.Slot{ID: id, Name: name}
+		newSlot := models.Slot{ID: id, Name: name, Required: required}
 		filters := slot.FieldByName("Filters")
 
 		allowedItems := filters.FieldByName("AllowedItems")
@@ -95,6 +95,11 @@ func getModsFromTarkovDev(api *tarkovdev.Api) ([]models.WeaponMod, error) {
 		return nil, err
 	}
 
+	return convertWeaponMods(res)
+}
+
+// convertWeaponMods converts tarkov.dev's weapon mods to ours
+func convertWeaponMods(res *tarkovdev.GetWeaponModsResponse) ([]models.WeaponMod, error) {
 	weaponMods := make([]models.WeaponMod, 0, len(res.Items))
 
 	for i := 0; i < len(res.Items); i++ {
@@ -103,8 +108,10 @@ func getModsFromTarkovDev(api *tarkovdev.Api) ([]models.WeaponMod, error) {
 		newMod := models.WeaponMod{
 			ID:                 mod.Id,
 			Name:               mod.Name,
-			ErgonomicsModifier: int(mod.ErgonomicsModifier),
-			RecoilModifier:     int(mod.RecoilModifier),
+			ErgonomicsModifier: models.NewStat(mod.ErgonomicsModifier),
+			RecoilModifier:     models.NewStat(mod.RecoilModifier),
+			Weight:             mod.Weight,
+			AccuracyModifier:   mod.AccuracyModifier,
 			ConflictingItems:   make([]string, 0, len(mod.ConflictingItems)),
 			CategoryID:         mod.Category.Id,
 			CategoryName:       mod.Category.Name,
@@ -114,12 +121,20 @@ func getModsFromTarkovDev(api *tarkovdev.Api) ([]models.WeaponMod, error) {
 			newMod.ConflictingItems = append(newMod.ConflictingItems, mod.ConflictingItems[j].Id)
 		}
 
+		barrel, ok := mod.Properties.(*tarkovdev.GetWeaponModsItemsItemPropertiesItemPropertiesBarrel)
+		if ok {
+			newMod.CenterOfImpact = barrel.CenterOfImpact
+			newMod.DeviationCurve = barrel.DeviationCurve
+			newMod.DeviationMax = barrel.DeviationMax
+		}
+
 		var slots []models.Slot
 		switch properties := mod.Properties.(type) {
 		case *tarkovdev.GetWeaponModsItemsItemPropertiesItemPropertiesWeaponMod,
 			*tarkovdev.GetWeaponModsItemsItemPropertiesItemPropertiesScope,
 			*tarkovdev.GetWeaponModsItemsItemPropertiesItemPropertiesBarrel,
 			*tarkovdev.GetWeaponModsItemsItemPropertiesItemPropertiesMagazine:
+			var err error
 			slots, err = convertPropertiesToSlots(properties)
 			if err != nil {
 				return nil, err
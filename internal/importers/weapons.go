@@ -125,6 +125,11 @@ func getWeaponsFromTarkovDev(api *tarkovdev.Api) ([]models.Weapon, error) {
 		return nil, errors.New("no weapons in response from tarkovdev")
 	}
 
+	return convertWeapons(res)
+}
+
+// convertWeapons converts tarkov.dev's weapons to ours. Presets are imported without slots.
+func convertWeapons(res *tarkovdev.GetWeaponsResponse) ([]models.Weapon, error) {
 	if len(res.Items) == 0 {
 		return nil, errors.New("no weapons to import")
 	}
@@ -137,8 +142,9 @@ func getWeaponsFromTarkovDev(api *tarkovdev.Api) ([]models.Weapon, error) {
 		newWeapon := models.Weapon{
 			ID:                 weapon.Id,
 			Name:               weapon.Name,
-			ErgonomicsModifier: int(weapon.ErgonomicsModifier),
-			RecoilModifier:     int(weapon.RecoilModifier),
+			ErgonomicsModifier: models.NewStat(weapon.ErgonomicsModifier),
+			RecoilModifier:     models.NewStat(weapon.RecoilModifier),
+			Weight:             weapon.Weight,
 			Slots:              []models.Slot{},
 		}
 
@@ -150,10 +156,17 @@ func getWeaponsFromTarkovDev(api *tarkovdev.Api) ([]models.Weapon, error) {
 			var slots []models.Slot
 			switch properties := weapon.Properties.(type) {
 			case *tarkovdev.GetWeaponsItemsItemPropertiesItemPropertiesWeapon:
+				var err error
 				slots, err = convertPropertiesToSlots(properties)
 				if err != nil {
 					return nil, err
 				}
+				newWeapon.CenterOfImpact = properties.CenterOfImpact
+				newWeapon.DeviationCurve = properties.DeviationCurve
+				newWeapon.DeviationMax = properties.DeviationMax
+				newWeapon.RecoilVertical = properties.RecoilVertical
+				newWeapon.RecoilHorizontal = properties.RecoilHorizontal
+				newWeapon.Ergonomics = properties.Ergonomics
 			default:
 				log.Debug().Msgf("unsupported weapon mod properties type: %T - skipping", weapon.Properties)
 			}
@@ -0,0 +1,60 @@
+package jobs
+
+import (
+	"database/sql"
+	"tarkov-build-optimiser/internal/models"
+)
+
+// Ways a prediction can be made
+const (
+	// PredictionHistory is the weapon's average evaluation time in previous runs
+	PredictionHistory = "history"
+	// PredictionModel scales the average time per allowed item across all weapons by the tree's size
+	PredictionModel = "model"
+	// PredictionNone means there are no recorded runs to predict from
+	PredictionNone = "none"
+)
+
+// CostModel predicts how long evaluating a build takes from the stats recorded by previous runs
+type CostModel struct {
+	weaponAvgMs      map[string]float64
+	msPerAllowedItem float64
+}
+
+// NewCostModel creates a CostModel from per-weapon history and totals across every recorded evaluation
+func NewCostModel(history []models.WeaponDurationStats, totals models.BuildCostTotals) CostModel {
+	m := CostModel{weaponAvgMs: make(map[string]float64, len(history))}
+	for _, h := range history {
+		m.weaponAvgMs[h.ItemID] = h.AvgDurationMs
+	}
+	if totals.AllowedItemsAfter > 0 {
+		m.msPerAllowedItem = float64(totals.DurationMs) / float64(totals.AllowedItemsAfter)
+	}
+	return m
+}
+
+// LoadCostModel creates a CostModel from the build_stats table
+func LoadCostModel(db *sql.DB) (CostModel, error) {
+	history, err := models.GetSlowestWeapons(db, 0)
+	if err != nil {
+		return CostModel{}, err
+	}
+
+	totals, err := models.GetBuildCostTotals(db)
+	if err != nil {
+		return CostModel{}, err
+	}
+
+	return NewCostModel(history, totals), nil
+}
+
+// Predict returns the predicted evaluation time in milliseconds and how it was predicted
+func (m CostModel) Predict(itemID string, allowedItemsAfter int) (float64, string) {
+	if avg, ok := m.weaponAvgMs[itemID]; ok {
+		return avg, PredictionHistory
+	}
+	if m.msPerAllowedItem > 0 {
+		return m.msPerAllowedItem * float64(allowedItemsAfter), PredictionModel
+	}
+	return 0, PredictionNone
+}
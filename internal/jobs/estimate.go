@@ -0,0 +1,197 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/cli"
+	"tarkov-build-optimiser/internal/models"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Estimate describes the search space of one build before it is evaluated
+type Estimate struct {
+	ItemID       string `json:"item_id"`
+	Name         string `json:"name"`
+	BuildType    string `json:"build_type"`
+	TraderLevels string `json:"trader_levels"`
+
+	AllowedItemsBefore int `json:"allowed_items_before"`
+	AllowedItemsAfter  int `json:"allowed_items_after"`
+	SlotsBefore        int `json:"slots_before"`
+	SlotsAfter         int `json:"slots_after"`
+	// fraction of pairs of allowed items which conflict, see CandidateTree.ConflictDensity
+	ConflictDensity float64 `json:"conflict_density"`
+	// builds allowed by the unpruned tree, ignoring conflicts
+	Combinations float64 `json:"combinations"`
+	// builds allowed once useless items have been pruned
+	PrunedCombinations float64 `json:"pruned_combinations"`
+
+	PredictedMs float64 `json:"predicted_ms"`
+	// how PredictedMs was derived, one of the Prediction constants
+	Prediction string `json:"prediction"`
+
+	Error string `json:"error,omitempty"`
+}
+
+// EstimateParams selects the builds to estimate
+type EstimateParams struct {
+	WeaponIDs    []string
+	TraderLevels [][]models.TraderLevel
+	BuildType    string
+	Profile      *models.ConstraintProfile
+	Shard        models.Shard
+	// number of candidate trees built concurrently
+	Workers int
+}
+
+// EstimateBuilds builds the candidate tree of every selected weapon and trader level combination and estimates the
+// cost of evaluating it. Trees which fail to build are reported with Error set rather than failing the run.
+func EstimateBuilds(ctx context.Context, data candidate_tree.TreeDataProvider, params EstimateParams, model CostModel) ([]Estimate, error) {
+	type input struct {
+		weaponID string
+		levels   []models.TraderLevel
+	}
+
+	workers := params.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	inputs := make(chan input, workers*2)
+	estimates := make([]Estimate, 0, len(params.WeaponIDs)*len(params.TraderLevels))
+	mu := sync.Mutex{}
+	wg := sync.WaitGroup{}
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for in := range inputs {
+				e := estimateBuild(data, in.weaponID, in.levels, params, model)
+				mu.Lock()
+				estimates = append(estimates, e)
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, weaponID := range params.WeaponIDs {
+		for _, levels := range params.TraderLevels {
+			if !params.Shard.Contains(models.ShardKey(weaponID, params.BuildType, levels)) {
+				continue
+			}
+			select {
+			case inputs <- input{weaponID: weaponID, levels: levels}:
+			case <-ctx.Done():
+				break feed
+			}
+		}
+	}
+	close(inputs)
+	wg.Wait()
+
+	return estimates, ctx.Err()
+}
+
+func estimateBuild(data candidate_tree.TreeDataProvider, weaponID string, levels []models.TraderLevel, params EstimateParams, model CostModel) Estimate {
+	e := Estimate{
+		ItemID:       weaponID,
+		BuildType:    params.BuildType,
+		TraderLevels: formatTraderLevels(levels),
+	}
+
+	constraints := params.Profile.Constraints(levels)
+	tree, err := candidate_tree.CreateWeaponCandidateTree(weaponID, params.BuildType, constraints, data)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to create weapon tree for %s", weaponID)
+		e.Error = err.Error()
+		e.Prediction = PredictionNone
+		return e
+	}
+
+	e.Name = tree.Item.Name
+	e.AllowedItemsBefore = tree.SizeBeforePruning.AllowedItems
+	e.AllowedItemsAfter = tree.SizeAfterPruning.AllowedItems
+	e.SlotsBefore = tree.SizeBeforePruning.Slots
+	e.SlotsAfter = tree.SizeAfterPruning.Slots
+	e.Combinations = tree.SizeBeforePruning.Combinations
+	e.PrunedCombinations = tree.SizeAfterPruning.Combinations
+	e.ConflictDensity = tree.ConflictDensity()
+	e.PredictedMs, e.Prediction = model.Predict(weaponID, e.AllowedItemsAfter)
+
+	return e
+}
+
+// SortEstimates orders estimates by one of the cli.Sort orders, largest first except for cli.SortWeapon.
+// Ties are broken by weapon and trader levels so output is stable between runs.
+func SortEstimates(estimates []Estimate, by string) {
+	key := func(e Estimate) float64 {
+		switch by {
+		case cli.SortItems:
+			return float64(e.AllowedItemsBefore)
+		case cli.SortPredicted:
+			return e.PredictedMs
+		case cli.SortConflicts:
+			return e.ConflictDensity
+		case cli.SortWeapon:
+			return 0
+		default:
+			return e.Combinations
+		}
+	}
+
+	sort.SliceStable(estimates, func(i, j int) bool {
+		a, b := estimates[i], estimates[j]
+		if ka, kb := key(a), key(b); ka != kb {
+			return ka > kb
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.ItemID != b.ItemID {
+			return a.ItemID < b.ItemID
+		}
+		return a.TraderLevels < b.TraderLevels
+	})
+}
+
+// WriteEstimates writes estimates in one of the cli.Format formats
+func WriteEstimates(w io.Writer, estimates []Estimate, format string) error {
+	if format == cli.FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(estimates)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "WEAPON\tNAME\tTRADERS\tITEMS\tPRUNED ITEMS\tSLOTS\tPRUNED SLOTS\tCONFLICTS\tCOMBINATIONS\tPREDICTED\tSOURCE")
+	totalMs := 0.0
+	for _, e := range estimates {
+		if e.Error != "" {
+			fmt.Fprintf(tw, "%s\t%s\t%s\terror: %s\n", e.ItemID, e.Name, e.TraderLevels, e.Error)
+			continue
+		}
+		totalMs += e.PredictedMs
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%d\t%d\t%d\t%.3f\t%.3g\t%s\t%s\n",
+			e.ItemID, e.Name, e.TraderLevels, e.AllowedItemsBefore, e.AllowedItemsAfter, e.SlotsBefore, e.SlotsAfter,
+			e.ConflictDensity, e.Combinations, formatPredicted(e), e.Prediction)
+	}
+	fmt.Fprintf(tw, "TOTAL\t\t%d builds\t\t\t\t\t\t\t%s\t\n", len(estimates), time.Duration(totalMs*float64(time.Millisecond)).Round(time.Second))
+	return tw.Flush()
+}
+
+func formatPredicted(e Estimate) string {
+	if e.Prediction == PredictionNone {
+		return "-"
+	}
+	return time.Duration(e.PredictedMs * float64(time.Millisecond)).Round(time.Millisecond).String()
+}
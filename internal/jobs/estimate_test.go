@@ -0,0 +1,95 @@
+package jobs
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"tarkov-build-optimiser/internal/cli"
+	"tarkov-build-optimiser/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCostModel_Predict(t *testing.T) {
+	model := NewCostModel(
+		[]models.WeaponDurationStats{{ItemID: "m4a1", AvgDurationMs: 1500}},
+		models.BuildCostTotals{Evaluations: 10, DurationMs: 20000, AllowedItemsAfter: 400},
+	)
+
+	ms, source := model.Predict("m4a1", 80)
+	assert.Equal(t, 1500.0, ms)
+	assert.Equal(t, PredictionHistory, source)
+
+	ms, source = model.Predict("ak74n", 80)
+	assert.Equal(t, 4000.0, ms)
+	assert.Equal(t, PredictionModel, source)
+
+	ms, source = NewCostModel(nil, models.BuildCostTotals{}).Predict("ak74n", 80)
+	assert.Equal(t, 0.0, ms)
+	assert.Equal(t, PredictionNone, source)
+}
+
+func TestSortEstimates(t *testing.T) {
+	estimates := []Estimate{
+		{ItemID: "b", Name: "B", TraderLevels: "1,1,1,1,1", Combinations: 10, PredictedMs: 300, ConflictDensity: 0.1},
+		{ItemID: "a", Name: "A", TraderLevels: "4,4,4,4,4", Combinations: 1e12, PredictedMs: 100, ConflictDensity: 0.3},
+		{ItemID: "a", Name: "A", TraderLevels: "1,1,1,1,1", Combinations: 10, PredictedMs: 200, ConflictDensity: 0.2},
+	}
+	order := func() []string {
+		ids := make([]string, 0, len(estimates))
+		for _, e := range estimates {
+			ids = append(ids, e.ItemID+":"+e.TraderLevels)
+		}
+		return ids
+	}
+
+	SortEstimates(estimates, cli.SortCombinations)
+	assert.Equal(t, []string{"a:4,4,4,4,4", "a:1,1,1,1,1", "b:1,1,1,1,1"}, order())
+
+	SortEstimates(estimates, cli.SortPredicted)
+	assert.Equal(t, []string{"b:1,1,1,1,1", "a:1,1,1,1,1", "a:4,4,4,4,4"}, order())
+
+	SortEstimates(estimates, cli.SortConflicts)
+	assert.Equal(t, []string{"a:4,4,4,4,4", "a:1,1,1,1,1", "b:1,1,1,1,1"}, order())
+
+	SortEstimates(estimates, cli.SortWeapon)
+	assert.Equal(t, []string{"a:1,1,1,1,1", "a:4,4,4,4,4", "b:1,1,1,1,1"}, order())
+}
+
+func TestWriteEstimates(t *testing.T) {
+	estimates := []Estimate{
+		{
+			ItemID:             "5447a9cd4bdc2dbd208b4567",
+			Name:               "Colt M4A1 5.56x45 assault rifle",
+			BuildType:          "recoil",
+			TraderLevels:       "4,4,2,3,1",
+			AllowedItemsBefore: 412,
+			AllowedItemsAfter:  230,
+			SlotsBefore:        96,
+			SlotsAfter:         71,
+			ConflictDensity:    0.0125,
+			Combinations:       3.2e14,
+			PredictedMs:        90500,
+			Prediction:         PredictionHistory,
+		},
+		{ItemID: "missing", TraderLevels: "1,1,1,1,1", Prediction: PredictionNone, Error: "weapon not found"},
+	}
+
+	out := bytes.Buffer{}
+	require.NoError(t, WriteEstimates(&out, estimates, cli.FormatTable))
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, []string{"5447a9cd4bdc2dbd208b4567", "Colt", "M4A1", "5.56x45", "assault", "rifle", "4,4,2,3,1",
+		"412", "230", "96", "71", "0.013", "3.2e+14", "1m30.5s", "history"}, strings.Fields(lines[1]))
+	assert.Contains(t, lines[2], "error: weapon not found")
+	assert.Equal(t, []string{"TOTAL", "2", "builds", "1m31s"}, strings.Fields(lines[3]))
+
+	out.Reset()
+	require.NoError(t, WriteEstimates(&out, estimates, cli.FormatJSON))
+	var decoded []Estimate
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Equal(t, estimates, decoded)
+}
@@ -0,0 +1,105 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/cli"
+	"tarkov-build-optimiser/internal/gunsmith"
+	"tarkov-build-optimiser/internal/models"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// SolveParams selects the gunsmith tasks to solve and the constraints to solve them with
+type SolveParams struct {
+	Tasks []gunsmith.Task
+	// each task is solved once for every set of trader levels
+	TraderLevels [][]models.TraderLevel
+	// Profile adds its ignored slots, items and category rules when set, tasks are otherwise solved with every slot
+	Profile *models.ConstraintProfile
+	// Timeout bounds the search of each task, unbounded when 0
+	Timeout time.Duration
+}
+
+// SolveTasks solves every selected task one after another. Tasks whose weapon can't be loaded are reported with
+// gunsmith.StatusUnknown rather than failing the run.
+func SolveTasks(ctx context.Context, data candidate_tree.TreeDataProvider, params SolveParams) []*gunsmith.Solution {
+	solutions := make([]*gunsmith.Solution, 0, len(params.Tasks)*len(params.TraderLevels))
+	for _, task := range params.Tasks {
+		for _, levels := range params.TraderLevels {
+			if ctx.Err() != nil {
+				return solutions
+			}
+			solutions = append(solutions, solveTask(ctx, data, params, task, levels))
+		}
+	}
+	return solutions
+}
+
+func solveTask(ctx context.Context, data candidate_tree.TreeDataProvider, params SolveParams, task gunsmith.Task, levels []models.TraderLevel) *gunsmith.Solution {
+	constraints := models.EvaluationConstraints{TraderLevels: levels}
+	if params.Profile != nil {
+		constraints = params.Profile.Constraints(levels)
+	}
+
+	weapon, err := candidate_tree.CreateFullWeaponCandidateTree(task.WeaponID, constraints, data)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to create candidate tree for task %s", task.ID)
+		return &gunsmith.Solution{
+			TaskID:       task.ID,
+			TaskName:     task.Name,
+			WeaponID:     task.WeaponID,
+			TraderLevels: levels,
+			Status:       gunsmith.StatusUnknown,
+			Reason:       fmt.Sprintf("failed to create candidate tree: %v", err),
+			Items:        make([]gunsmith.SolutionItem, 0),
+			Stats:        make(map[string]float64),
+		}
+	}
+
+	if params.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, params.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	solution := gunsmith.Solve(ctx, weapon, task)
+	log.Info().Msgf("Solved task %s with trader levels %s in %s: %s", task.ID, formatTraderLevels(levels), time.Since(start), solution.Status)
+	return solution
+}
+
+// WriteSolutions writes solutions in one of the cli.Format formats. Tables list the items of each feasible build
+// under its row.
+func WriteSolutions(w io.Writer, solutions []*gunsmith.Solution, format string) error {
+	if format == cli.FormatJSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(solutions)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "TASK\tWEAPON\tTRADERS\tSTATUS\tPRICE\tRECOIL\tERGONOMICS\tWEIGHT\tDETAIL")
+	for _, s := range solutions {
+		if s.Status != gunsmith.StatusFeasible {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t-\t-\t-\t-\t%s\n", s.TaskID, s.WeaponName, formatTraderLevels(s.TraderLevels), s.Status, s.Reason)
+			continue
+		}
+
+		detail := fmt.Sprintf("%d items", len(s.Items))
+		if !s.Optimal {
+			detail += ", search stopped before proving it the best"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%g\t%g\t%.3f\t%s\n",
+			s.TaskID, s.WeaponName, formatTraderLevels(s.TraderLevels), s.Status, s.PriceRub, s.Stats["recoil"], s.Stats["ergonomics"], s.Stats["weight"], detail)
+		for _, item := range s.Items {
+			fmt.Fprintf(tw, "\t\t\t\t%d\t\t\t\t%s: %s\n", item.PriceRub, item.SlotName, item.Name)
+		}
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"tarkov-build-optimiser/internal/cli"
+	"tarkov-build-optimiser/internal/gunsmith"
+	"tarkov-build-optimiser/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteSolutions(t *testing.T) {
+	levels := []models.TraderLevel{
+		{Name: "Jaeger", Level: 4},
+		{Name: "Prapor", Level: 4},
+		{Name: "Peacekeeper", Level: 2},
+		{Name: "Mechanic", Level: 3},
+		{Name: "Skier", Level: 1},
+	}
+	solutions := []*gunsmith.Solution{
+		{
+			TaskID:       "gunsmith-1",
+			WeaponName:   "MP-133",
+			TraderLevels: levels,
+			Status:       gunsmith.StatusFeasible,
+			Optimal:      true,
+			Items:        []gunsmith.SolutionItem{{ID: "stock", Name: "MP-133 wooden stock", SlotName: "Stock", PriceRub: 1500}},
+			Stats:        map[string]float64{"recoil": -20, "ergonomics": 52, "weight": 3.45},
+			PriceRub:     1500,
+		},
+		{
+			TaskID:       "gunsmith-2",
+			WeaponName:   "AKS-74U",
+			TraderLevels: levels,
+			Status:       gunsmith.StatusInfeasible,
+			Reason:       "ergonomics can't go above 40, task needs ergonomics >= 58",
+		},
+	}
+
+	out := bytes.Buffer{}
+	require.NoError(t, WriteSolutions(&out, solutions, cli.FormatTable))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.True(t, strings.HasPrefix(lines[0], "TASK"))
+	assert.Contains(t, lines[1], "4,4,2,3,1")
+	assert.Contains(t, lines[1], "feasible")
+	assert.Contains(t, lines[1], "3.450")
+	assert.Contains(t, lines[2], "Stock: MP-133 wooden stock")
+	assert.Contains(t, lines[3], "ergonomics can't go above 40")
+
+	out.Reset()
+	require.NoError(t, WriteSolutions(&out, solutions, cli.FormatJSON))
+	decoded := make([]gunsmith.Solution, 0)
+	require.NoError(t, json.Unmarshal(out.Bytes(), &decoded))
+	assert.Len(t, decoded, 2)
+}
@@ -0,0 +1,86 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"tarkov-build-optimiser/internal/models"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultStaleAfter is how long an InProgress build may go without a heartbeat before it is requeued
+	DefaultStaleAfter = 5 * time.Minute
+	// DefaultReapInterval is how often the reaper looks for stale builds
+	DefaultReapInterval = time.Minute
+	// DefaultMaxRetryBackoff caps the exponential backoff between attempts at evaluating a failed build
+	DefaultMaxRetryBackoff = 30 * time.Minute
+)
+
+// Seed makes sure a build exists in the queue for every weapon and trader level combination.
+// Existing builds are left alone, Failed builds are retried by workers according to their RetryPolicy.
+// Builds are created for the selection's profile, which must be set. Only builds in the selection's shard are
+// created. Seeding is idempotent, so every evaluator process may seed the same work concurrently.
+func Seed(ctx context.Context, db *sql.DB, weaponIds []string, traderLevels [][]models.TraderLevel, selection Selection) error {
+	buildType := selection.BuildType
+	for i := 0; i < len(weaponIds); i++ {
+		for j := 0; j < len(traderLevels); j++ {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+
+			if !selection.Shard.Contains(models.ShardKey(weaponIds[i], buildType, traderLevels[j])) {
+				continue
+			}
+
+			constraints := selection.Profile.Constraints(traderLevels[j])
+
+			existingBuild, err := models.GetOptimumBuildByConstraints(db, weaponIds[i], buildType, constraints)
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to check existing build for weapon %s", weaponIds[i])
+				continue
+			}
+
+			if existingBuild != nil {
+				log.Debug().Msgf("Build %d for weapon %s with constraints %v already exists (status: %s)", existingBuild.BuildID, weaponIds[i], traderLevels[j], existingBuild.Status)
+				continue
+			}
+
+			log.Debug().Msgf("Creating new pending build for weapon %s with constraints %v", weaponIds[i], traderLevels[j])
+			_, err = models.CreatePendingOptimumBuild(db, weaponIds[i], buildType, constraints)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// RunReaper periodically requeues InProgress builds whose worker has stopped sending heartbeats, until ctx is done
+func RunReaper(ctx context.Context, db *sql.DB, interval time.Duration, staleAfter time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		reapStaleBuilds(db, staleAfter)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func reapStaleBuilds(db *sql.DB, staleAfter time.Duration) {
+	count, err := models.RequeueStaleBuilds(db, staleAfter)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to requeue stale builds")
+		return
+	}
+	if count > 0 {
+		log.Info().Msgf("Requeued %d stale builds", count)
+	}
+}
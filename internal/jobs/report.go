@@ -0,0 +1,119 @@
+package jobs
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"tarkov-build-optimiser/internal/models"
+	"text/tabwriter"
+	"time"
+)
+
+// WriteFailedBuilds writes failed builds as a table, one row per build
+func WriteFailedBuilds(w io.Writer, builds []models.FailedBuild) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "BUILD\tWEAPON\tNAME\tTYPE\tPROFILE\tTRADERS\tATTEMPTS\tLAST ERROR\tREASON")
+	for _, b := range builds {
+		lastError := "-"
+		if b.LastErrorAt != nil {
+			lastError = b.LastErrorAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			b.BuildID, b.ItemID, b.Name, b.BuildType, b.Profile, formatTraderLevels(b.TraderLevels), b.Attempts, lastError, b.Reason)
+	}
+	return tw.Flush()
+}
+
+// formatTraderLevels renders levels as e.g. 4,4,2,3,1 in models.TraderNames order
+func formatTraderLevels(levels []models.TraderLevel) string {
+	byName := make(map[string]int, len(levels))
+	for _, l := range levels {
+		byName[l.Name] = l.Level
+	}
+
+	str := ""
+	for i, name := range models.TraderNames {
+		if i > 0 {
+			str += ","
+		}
+		str += fmt.Sprintf("%d", byName[name])
+	}
+	return str
+}
+
+// statusColumns are the statuses shown by status tables, in order
+var statusColumns = []string{
+	models.EvaluationPending.ToString(),
+	models.EvaluationInProgress.ToString(),
+	models.EvaluationCompleted.ToString(),
+	models.EvaluationFailed.ToString(),
+}
+
+type statusRow struct {
+	labels []string
+	counts map[string]int
+}
+
+// WriteStatusCounts writes one row per weapon with its build count in each status, followed by a total row
+func WriteStatusCounts(w io.Writer, counts []models.BuildStatusCount) error {
+	rows := make([]*statusRow, 0)
+	byItem := make(map[string]*statusRow)
+	for _, c := range counts {
+		r, ok := byItem[c.ItemID]
+		if !ok {
+			r = &statusRow{labels: []string{c.ItemID, c.Name}, counts: make(map[string]int)}
+			byItem[c.ItemID] = r
+			rows = append(rows, r)
+		}
+		r.counts[c.Status] += c.Count
+	}
+
+	return writeStatusTable(w, []string{"WEAPON", "NAME"}, rows)
+}
+
+// WriteShardStatusCounts writes one row per shard with its build count in each status, followed by a total row
+func WriteShardStatusCounts(w io.Writer, counts []models.ShardStatusCount, shardCount int) error {
+	rows := make([]*statusRow, 0, shardCount)
+	for i := 0; i < shardCount; i++ {
+		shard := models.Shard{Index: i, Count: shardCount}
+		rows = append(rows, &statusRow{labels: []string{shard.String()}, counts: make(map[string]int)})
+	}
+	for _, c := range counts {
+		if c.ShardIndex >= 0 && c.ShardIndex < shardCount {
+			rows[c.ShardIndex].counts[c.Status] += c.Count
+		}
+	}
+
+	return writeStatusTable(w, []string{"SHARD"}, rows)
+}
+
+func writeStatusTable(w io.Writer, headers []string, rows []*statusRow) error {
+	totals := &statusRow{labels: make([]string, len(headers)), counts: make(map[string]int)}
+	totals.labels[0] = "TOTAL"
+	for _, r := range rows {
+		for status, count := range r.counts {
+			totals.counts[status] += count
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\t%s\tDONE\n", strings.Join(headers, "\t"), strings.ToUpper(strings.Join(statusColumns, "\t")))
+	for _, r := range append(rows, totals) {
+		total := 0
+		fmt.Fprint(tw, strings.Join(r.labels, "\t"))
+		for _, status := range statusColumns {
+			fmt.Fprintf(tw, "\t%d", r.counts[status])
+			total += r.counts[status]
+		}
+		fmt.Fprintf(tw, "\t%s\n", percentage(r.counts[models.EvaluationCompleted.ToString()], total))
+	}
+
+	return tw.Flush()
+}
+
+func percentage(part int, total int) string {
+	if total == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", float64(part)*100/float64(total))
+}
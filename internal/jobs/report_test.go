@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"tarkov-build-optimiser/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteFailedBuilds(t *testing.T) {
+	lastError := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	builds := []models.FailedBuild{
+		{
+			BuildID:   12,
+			ItemID:    "5447a9cd4bdc2dbd208b4567",
+			Name:      "Colt M4A1 5.56x45 assault rifle",
+			BuildType: "recoil",
+			Profile:   "iron-sights",
+			TraderLevels: []models.TraderLevel{
+				{Name: "Skier", Level: 1},
+				{Name: "Jaeger", Level: 4},
+				{Name: "Mechanic", Level: 3},
+				{Name: "Prapor", Level: 4},
+				{Name: "Peacekeeper", Level: 2},
+			},
+			Reason:      "failed to create candidate tree: weapon not found",
+			Attempts:    3,
+			LastErrorAt: &lastError,
+		},
+	}
+
+	out := bytes.Buffer{}
+	require.NoError(t, WriteFailedBuilds(&out, builds))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.True(t, strings.HasPrefix(lines[0], "BUILD"))
+	assert.Contains(t, lines[1], "iron-sights")
+	assert.Contains(t, lines[1], "4,4,2,3,1")
+	assert.Contains(t, lines[1], "2026-10-18T09:30:00Z")
+	assert.Contains(t, lines[1], "failed to create candidate tree: weapon not found")
+}
+
+func TestWriteStatusCounts(t *testing.T) {
+	counts := []models.BuildStatusCount{
+		{ItemID: "weapon-a", Name: "A", Status: "Completed", Count: 3},
+		{ItemID: "weapon-a", Name: "A", Status: "Pending", Count: 1},
+		{ItemID: "weapon-b", Name: "B", Status: "Failed", Count: 2},
+	}
+
+	out := bytes.Buffer{}
+	require.NoError(t, WriteStatusCounts(&out, counts))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 4)
+	assert.Equal(t, []string{"WEAPON", "NAME", "PENDING", "INPROGRESS", "COMPLETED", "FAILED", "DONE"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"weapon-a", "A", "1", "0", "3", "0", "75.0%"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"weapon-b", "B", "0", "0", "0", "2", "0.0%"}, strings.Fields(lines[2]))
+	assert.Equal(t, []string{"TOTAL", "1", "0", "3", "2", "50.0%"}, strings.Fields(lines[3]))
+}
+
+func TestWriteShardStatusCounts(t *testing.T) {
+	counts := []models.ShardStatusCount{
+		{ShardIndex: 0, Status: "Completed", Count: 4},
+		{ShardIndex: 2, Status: "Completed", Count: 1},
+		{ShardIndex: 2, Status: "InProgress", Count: 1},
+	}
+
+	out := bytes.Buffer{}
+	require.NoError(t, WriteShardStatusCounts(&out, counts, 3))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 5)
+	assert.Equal(t, []string{"0/3", "0", "0", "4", "0", "100.0%"}, strings.Fields(lines[1]))
+	assert.Equal(t, []string{"1/3", "0", "0", "0", "0", "-"}, strings.Fields(lines[2]))
+	assert.Equal(t, []string{"2/3", "0", "1", "1", "0", "50.0%"}, strings.Fields(lines[3]))
+	assert.Equal(t, []string{"TOTAL", "0", "1", "5", "0", "83.3%"}, strings.Fields(lines[4]))
+}
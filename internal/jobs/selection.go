@@ -0,0 +1,176 @@
+package jobs
+
+import (
+	"bufio"
+	"database/sql"
+	"fmt"
+	"os"
+	"strings"
+	"tarkov-build-optimiser/internal/cli"
+	"tarkov-build-optimiser/internal/models"
+)
+
+// Selection is a cli.Selector with weapon names resolved to IDs
+type Selection struct {
+	// empty means every weapon
+	WeaponIDs []string
+	BuildType string
+	// empty means every trader level combination
+	TraderLevels [][]models.TraderLevel
+	// nil means every profile
+	Profile *models.ConstraintProfile
+	// ProfileDerived is set when Profile is the selected profile with the selector's ignored slots added, which isn't
+	// stored until builds are evaluated with it
+	ProfileDerived bool
+	Shard          models.Shard
+}
+
+// Filter returns the filter matching the selected builds
+func (s Selection) Filter() models.BuildFilter {
+	filter := models.BuildFilter{
+		ItemIDs:      s.WeaponIDs,
+		BuildType:    s.BuildType,
+		TraderLevels: s.TraderLevels,
+		Shard:        s.Shard,
+	}
+	if s.Profile != nil {
+		filter.Profile = s.Profile.Name
+	}
+	return filter
+}
+
+// ClaimFilter returns the filter of the builds workers claim. It is Filter, but matching every build type when
+// anyBuildType and every profile when anyProfile, as seeding still needs a build type and profile when claiming doesn't.
+func (s Selection) ClaimFilter(anyBuildType bool, anyProfile bool) models.BuildFilter {
+	filter := s.Filter()
+	if anyBuildType {
+		filter.BuildType = ""
+	}
+	if anyProfile {
+		filter.Profile = ""
+	}
+	return filter
+}
+
+// ResolveSelection reads the selector's weapons file, resolves weapon names to IDs and loads the selected profile.
+// Ignored slots are added to the selected profile, or to the default profile when none is selected.
+func ResolveSelection(db *sql.DB, selector cli.Selector) (Selection, error) {
+	selection := Selection{
+		BuildType:    selector.BuildType,
+		TraderLevels: selector.TraderLevels,
+		Shard:        selector.Shard,
+	}
+
+	profileName := selector.Profile
+	if profileName == "" && len(selector.IgnoredSlots) > 0 {
+		profileName = models.DefaultProfileName
+	}
+	if profileName != "" {
+		profile, err := models.GetConstraintProfileByName(db, profileName)
+		if err != nil {
+			return selection, err
+		}
+		if profile == nil {
+			return selection, fmt.Errorf("no constraint profile named %q", profileName)
+		}
+		selection.Profile = profile.WithIgnoredSlots(selector.IgnoredSlots)
+		selection.ProfileDerived = selection.Profile != profile
+	}
+
+	wanted := append([]string{}, selector.Weapons...)
+	if selector.WeaponsFile != "" {
+		fromFile, err := ReadWeaponsFile(selector.WeaponsFile)
+		if err != nil {
+			return selection, err
+		}
+		wanted = append(wanted, fromFile...)
+	}
+
+	if len(wanted) == 0 {
+		return selection, nil
+	}
+
+	weapons, err := models.GetWeaponsShort(db)
+	if err != nil {
+		return selection, err
+	}
+
+	selection.WeaponIDs, err = ResolveWeapons(weapons, wanted)
+	return selection, err
+}
+
+// ReadWeaponsFile reads weapon IDs or names from path, one per line. Blank lines and lines starting with # are ignored.
+func ReadWeaponsFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var weapons []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		weapons = append(weapons, line)
+	}
+
+	return weapons, scanner.Err()
+}
+
+// ResolveWeapons maps each wanted weapon to an ID. A value matches a weapon by exact ID, then by name ignoring case,
+// then by a unique part of the name. Duplicates are removed.
+func ResolveWeapons(weapons []models.WeaponShort, wanted []string) ([]string, error) {
+	ids := make([]string, 0, len(wanted))
+	seen := make(map[string]bool, len(wanted))
+
+	for _, w := range wanted {
+		id, err := resolveWeapon(weapons, w)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+
+	return ids, nil
+}
+
+func resolveWeapon(weapons []models.WeaponShort, wanted string) (string, error) {
+	for _, weapon := range weapons {
+		if weapon.ID == wanted {
+			return weapon.ID, nil
+		}
+	}
+
+	for _, weapon := range weapons {
+		if strings.EqualFold(weapon.Name, wanted) {
+			return weapon.ID, nil
+		}
+	}
+
+	var matches []models.WeaponShort
+	lower := strings.ToLower(wanted)
+	for _, weapon := range weapons {
+		if strings.Contains(strings.ToLower(weapon.Name), lower) {
+			matches = append(matches, weapon)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("no weapon matches %q", wanted)
+	case 1:
+		return matches[0].ID, nil
+	}
+
+	names := make([]string, 0, len(matches))
+	for _, m := range matches {
+		names = append(names, fmt.Sprintf("%s (%s)", m.Name, m.ID))
+	}
+	return "", fmt.Errorf("%q matches several weapons: %s", wanted, strings.Join(names, ", "))
+}
@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"tarkov-build-optimiser/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var mockWeapons = []models.WeaponShort{
+	{ID: "5447a9cd4bdc2dbd208b4567", Name: "Colt M4A1 5.56x45 assault rifle"},
+	{ID: "6895bb82c4519957df062f82", Name: "Radian Weapons Model 1 FA 5.56x45 assault rifle"},
+	{ID: "5644bd2b4bdc2d3b4c8b4572", Name: "Kalashnikov AK-74N 5.45x39 assault rifle"},
+}
+
+func TestResolveWeapons(t *testing.T) {
+	ids, err := ResolveWeapons(mockWeapons, []string{
+		"5447a9cd4bdc2dbd208b4567",
+		"radian weapons model 1 fa 5.56x45 assault rifle",
+		"AK-74N",
+		"Colt M4A1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"5447a9cd4bdc2dbd208b4567", "6895bb82c4519957df062f82", "5644bd2b4bdc2d3b4c8b4572"}, ids)
+}
+
+func TestResolveWeapons_Errors(t *testing.T) {
+	_, err := ResolveWeapons(mockWeapons, []string{"5.56x45"})
+	assert.ErrorContains(t, err, "matches several weapons")
+
+	_, err = ResolveWeapons(mockWeapons, []string{"SA-58"})
+	assert.ErrorContains(t, err, "no weapon matches")
+}
+
+func TestReadWeaponsFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "weapons.txt")
+	content := "# rifles\n5447a9cd4bdc2dbd208b4567\n\n  AK-74N  \n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+
+	weapons, err := ReadWeaponsFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"5447a9cd4bdc2dbd208b4567", "AK-74N"}, weapons)
+}
+
+func TestSelection_ClaimFilter(t *testing.T) {
+	selection := Selection{
+		WeaponIDs: []string{"5447a9cd4bdc2dbd208b4567"},
+		BuildType: "recoil",
+		Profile:   &models.ConstraintProfile{Name: models.DefaultProfileName},
+	}
+
+	filter := selection.ClaimFilter(true, true)
+	assert.Equal(t, models.BuildFilter{ItemIDs: []string{"5447a9cd4bdc2dbd208b4567"}}, filter,
+		"expected every build type and profile to be claimed")
+
+	filter = selection.ClaimFilter(false, false)
+	assert.Equal(t, selection.Filter(), filter)
+}
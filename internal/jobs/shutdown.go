@@ -0,0 +1,46 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"tarkov-build-optimiser/internal/models"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// WaitForWorkers blocks until done is closed. If ctx is cancelled first, workers have stopped claiming and
+// in-flight builds are given until timeout to finish. Searches still running after that are abandoned and their builds
+// released back to Pending so another evaluator can pick them up straight away rather than waiting for the reaper.
+// Returns false if the workers were abandoned.
+func WaitForWorkers(ctx context.Context, db *sql.DB, workers []*Worker, done <-chan struct{}, timeout time.Duration) bool {
+	select {
+	case <-done:
+		return true
+	case <-ctx.Done():
+	}
+
+	log.Info().Msgf("Shutdown requested. Waiting up to %s for in-flight builds to finish.", timeout)
+
+	select {
+	case <-done:
+		log.Info().Msg("In-flight builds finished.")
+		return true
+	case <-time.After(timeout):
+	}
+
+	workerIDs := make([]string, 0, len(workers))
+	for _, w := range workers {
+		w.Abandon()
+		workerIDs = append(workerIDs, w.ID)
+	}
+
+	released, err := models.ReleaseWorkerBuilds(db, workerIDs)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to release in-flight builds. They will be requeued once their heartbeat goes stale.")
+		return false
+	}
+
+	log.Info().Msgf("Released %d in-flight builds back to Pending", released)
+	return false
+}
@@ -0,0 +1,348 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/evaluator"
+	"tarkov-build-optimiser/internal/models"
+	"tarkov-build-optimiser/internal/tracer"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultHeartbeatInterval is how often a worker reports that it is still evaluating a build
+	DefaultHeartbeatInterval = 30 * time.Second
+	// DefaultPollInterval is how long an idle worker waits before trying to claim again
+	DefaultPollInterval = 5 * time.Second
+)
+
+// EvaluationResult is sent on Worker.Results after a build has been saved
+type EvaluationResult struct {
+	Result         *evaluator.Build
+	Weapon         *candidate_tree.CandidateTree
+	EvaluationType string
+	BuildID        int
+	WorkerID       string
+	// Duration covers building the candidate tree, searching and saving the build
+	Duration time.Duration
+}
+
+// ToBuildStats converts the result into the search statistics persisted for every build
+func (r EvaluationResult) ToBuildStats() models.BuildStats {
+	return models.BuildStats{
+		BuildID:            r.BuildID,
+		ItemID:             r.Weapon.Item.ID,
+		BuildType:          r.EvaluationType,
+		WorkerID:           r.WorkerID,
+		Duration:           r.Duration,
+		ItemsEvaluated:     r.Result.ItemsEvaluated,
+		CacheHits:          r.Result.CacheHits,
+		CacheMisses:        r.Result.CacheMisses,
+		AllowedItemsBefore: r.Weapon.SizeBeforePruning.AllowedItems,
+		AllowedItemsAfter:  r.Weapon.SizeAfterPruning.AllowedItems,
+		SlotsBefore:        r.Weapon.SizeBeforePruning.Slots,
+		SlotsAfter:         r.Weapon.SizeAfterPruning.Slots,
+	}
+}
+
+// Worker claims Pending builds from optimal_build_status and evaluates them until the queue is drained
+type Worker struct {
+	ID           string
+	DB           *sql.DB
+	DataProvider candidate_tree.TreeDataProvider
+	Cache        evaluator.Cache
+	// Results optionally receives every completed build
+	Results chan<- EvaluationResult
+	// Retry decides when Failed builds are claimed again
+	Retry models.RetryPolicy
+	// Filter limits the builds this worker claims
+	Filter models.BuildFilter
+	// Watch keeps the worker polling once the queue is drained, so builds requested through the API are picked up
+	Watch bool
+	// TraceDir, when set, is where the search of every build is traced to, see tracer.Writer
+	TraceDir string
+
+	HeartbeatInterval time.Duration
+	PollInterval      time.Duration
+
+	// profiles caches the constraint profiles of claimed builds by name
+	profiles map[string]*models.ConstraintProfile
+
+	// searchCtx is cancelled by Abandon, see searchContext
+	searchMu     sync.Mutex
+	searchCtx    context.Context
+	cancelSearch context.CancelFunc
+}
+
+// searchContext returns the context searches run under. Unlike the context passed to Run it isn't cancelled when
+// shutting down, only once Abandon is called, so in-flight builds get their chance to finish.
+func (w *Worker) searchContext() context.Context {
+	w.searchMu.Lock()
+	defer w.searchMu.Unlock()
+	if w.searchCtx == nil {
+		w.searchCtx, w.cancelSearch = context.WithCancel(context.Background())
+	}
+	return w.searchCtx
+}
+
+// Abandon stops the search in progress, and any later one. The build being searched is neither completed nor failed,
+// it is left for whoever releases it.
+func (w *Worker) Abandon() {
+	w.searchContext()
+	w.cancelSearch()
+}
+
+// NewWorkerID returns an identifier which is unique across evaluator processes and machines
+func NewWorkerID(index int) string {
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+	return fmt.Sprintf("%s-%d-%d", hostname, os.Getpid(), index)
+}
+
+// Run claims and evaluates builds until there is nothing left to claim and seeded is closed, or ctx is done.
+// Watching workers only stop when ctx is done.
+// While seeding is still in progress an empty queue only means the seeder hasn't caught up, so the worker polls.
+// Cancelling ctx stops the worker claiming new builds but the build in progress is finished first.
+func (w *Worker) Run(ctx context.Context, seeded <-chan struct{}) {
+	pollInterval := w.PollInterval
+	if pollInterval <= 0 {
+		pollInterval = DefaultPollInterval
+	}
+
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		claimed, err := models.ClaimPendingBuild(w.DB, w.ID, w.Retry, w.Filter)
+		if err != nil {
+			log.Error().Err(err).Msgf("Worker %s failed to claim a build", w.ID)
+		}
+
+		if claimed != nil {
+			w.process(ctx, claimed)
+			continue
+		}
+
+		if err == nil && !w.Watch && isClosed(seeded) {
+			// failed builds waiting out their backoff are still work for this run
+			retryable, err := models.HasRetryableBuilds(w.DB, w.Retry, w.Filter)
+			if err != nil {
+				log.Error().Err(err).Msgf("Worker %s failed to check for retryable builds", w.ID)
+			} else if !retryable {
+				log.Info().Msgf("Worker %s found no pending builds. Stopping.", w.ID)
+				return
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// profile returns the named constraint profile, loading it the first time it is needed
+func (w *Worker) profile(name string) (*models.ConstraintProfile, error) {
+	if profile, ok := w.profiles[name]; ok {
+		return profile, nil
+	}
+
+	profile, err := models.GetConstraintProfileByName(w.DB, name)
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("no constraint profile named %q", name)
+	}
+
+	if w.profiles == nil {
+		w.profiles = make(map[string]*models.ConstraintProfile)
+	}
+	w.profiles[name] = profile
+	return profile, nil
+}
+
+func (w *Worker) process(ctx context.Context, claimed *models.ClaimedBuild) {
+	start := time.Now()
+	log.Info().Msgf("Worker %s processing build %d for weapon %s (previous failed attempts: %d)", w.ID, claimed.BuildID, claimed.ItemID, claimed.Attempts)
+
+	defer func() {
+		// a panic while searching is almost always bad item data, record it rather than taking the process down
+		if r := recover(); r != nil {
+			log.Error().Msgf("Panic while evaluating build %d for weapon %s: %v", claimed.BuildID, claimed.ItemID, r)
+			w.fail(claimed.BuildID, fmt.Sprintf("panic: %v", r))
+		}
+	}()
+
+	// keep the claim alive while shutting down, in-flight builds are allowed to finish
+	stopHeartbeat := w.startHeartbeat(context.WithoutCancel(ctx), claimed.BuildID)
+	defer stopHeartbeat()
+
+	profile, err := w.profile(claimed.Profile)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to load constraint profile %s for build %d", claimed.Profile, claimed.BuildID)
+		w.fail(claimed.BuildID, fmt.Sprintf("failed to load constraint profile: %v", err))
+		return
+	}
+	constraints := profile.Constraints(claimed.TraderLevels)
+
+	weapon, err := candidate_tree.CreateWeaponCandidateTree(claimed.ItemID, claimed.BuildType, constraints, w.DataProvider)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to create weapon tree for %s. Skipping", claimed.ItemID)
+		w.fail(claimed.BuildID, fmt.Sprintf("failed to create candidate tree: %v", err))
+		return
+	}
+
+	weapon.SortAllowedItems(candidate_tree.BestFirstSort(claimed.BuildType))
+
+	log.Info().Msgf("Generated weapon candidate tree for %s with constraints %v", claimed.ItemID, constraints)
+	build, err := w.findBestBuild(w.searchContext(), claimed, weapon)
+	if errors.Is(err, context.Canceled) {
+		log.Warn().Msgf("Worker %s abandoned build %d for weapon %s", w.ID, claimed.BuildID, claimed.ItemID)
+		return
+	}
+	if build == nil {
+		log.Warn().Msgf("No build of weapon %s satisfies constraints %v", claimed.ItemID, constraints)
+		w.fail(claimed.BuildID, evaluator.ErrNoBuild.Error())
+		return
+	}
+
+	log.Info().Msgf("Evaluation complete - weapon %s with constraints %v", claimed.ItemID, constraints)
+
+	evaledWeapon, err := build.ToEvaluatedWeapon()
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to convert result to evaluated weapon for weapon %s with constraints %v", claimed.ItemID, constraints)
+		w.fail(claimed.BuildID, fmt.Sprintf("failed to convert result to evaluated weapon: %v", err))
+		return
+	}
+
+	evaluationResult := evaledWeapon.ToItemEvaluationResult()
+
+	// the build is still worth saving without its explanation
+	explanation, err := build.Explain(w.DataProvider)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Failed to explain build for weapon %s with constraints %v", claimed.ItemID, constraints)
+		explanation = nil
+	}
+
+	err = models.SetBuildCompleted(w.DB, claimed.BuildID, w.ID, &evaluationResult, explanation)
+	if errors.Is(err, models.ErrBuildNotClaimed) {
+		// the build was requeued while we searched, whoever holds it now saves their own result
+		log.Warn().Msgf("Worker %s lost its claim on build %d, dropping the result", w.ID, claimed.BuildID)
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to save build for weapon %s with constraints %v", claimed.ItemID, constraints)
+		w.fail(claimed.BuildID, fmt.Sprintf("failed to save build: %v", err))
+		return
+	}
+
+	log.Info().Msgf("Saved build for weapon %s with constraints %v", claimed.ItemID, constraints)
+
+	if w.Results != nil {
+		w.Results <- EvaluationResult{
+			BuildID:        claimed.BuildID,
+			EvaluationType: claimed.BuildType,
+			Weapon:         weapon,
+			Result:         build,
+			WorkerID:       w.ID,
+			Duration:       time.Since(start),
+		}
+	}
+}
+
+// findBestBuild searches for the claimed build, tracing the search when TraceDir is set. A trace which can't be
+// written is logged, the build is still evaluated. ctx's error is returned once it is done.
+func (w *Worker) findBestBuild(ctx context.Context, claimed *models.ClaimedBuild, weapon *candidate_tree.CandidateTree) (*evaluator.Build, error) {
+	if w.TraceDir == "" {
+		return evaluator.FindBestBuildContext(ctx, weapon, claimed.BuildType, map[string]bool{}, w.Cache)
+	}
+
+	path := filepath.Join(w.TraceDir, fmt.Sprintf("%d-%s-%s.trace", claimed.BuildID, claimed.ItemID, claimed.BuildType))
+	file, err := os.Create(path)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to create trace %s for build %d", path, claimed.BuildID)
+		return evaluator.FindBestBuildContext(ctx, weapon, claimed.BuildType, map[string]bool{}, w.Cache)
+	}
+
+	trace := tracer.NewWriter(file)
+	build, err := evaluator.FindBestBuildTraced(ctx, weapon, claimed.BuildType, map[string]bool{}, w.Cache, trace)
+	if err := trace.Close(); err != nil {
+		log.Error().Err(err).Msgf("Failed to write trace %s for build %d", path, claimed.BuildID)
+	} else {
+		log.Info().Msgf("Traced build %d to %s", claimed.BuildID, path)
+	}
+	return build, err
+}
+
+// fail marks the build Failed, unless the worker has lost its claim on it
+func (w *Worker) fail(buildID int, reason string) {
+	err := models.SetBuildFailed(w.DB, buildID, w.ID, reason)
+	if errors.Is(err, models.ErrBuildNotClaimed) {
+		log.Warn().Msgf("Worker %s lost its claim on build %d, not marking it failed: %s", w.ID, buildID, reason)
+		return
+	}
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to set build failed for build %d", buildID)
+	}
+}
+
+// startHeartbeat keeps the claimed build's heartbeat fresh until the returned func is called
+func (w *Worker) startHeartbeat(ctx context.Context, buildID int) func() {
+	interval := w.HeartbeatInterval
+	if interval <= 0 {
+		interval = DefaultHeartbeatInterval
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				err := models.HeartbeatBuild(w.DB, buildID, w.ID)
+				if errors.Is(err, models.ErrBuildNotClaimed) {
+					// the reaper gave up on us, another worker may pick this build up as well
+					log.Warn().Msgf("Worker %s lost its claim on build %d", w.ID, buildID)
+					return
+				}
+				if err != nil {
+					log.Error().Err(err).Msgf("Worker %s failed to send heartbeat for build %d", w.ID, buildID)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func isClosed(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+)
+
+// Reasons an item wasn't chosen for a slot
+const (
+	// RejectedWorseStats items could beat the chosen item on their own, but not with the rest of the build
+	RejectedWorseStats = "worse_stats"
+	// RejectedConflict items conflict with an item chosen for another slot
+	RejectedConflict = "conflict"
+	// RejectedPruned items can't beat the chosen item even at best, so the search never tried them
+	RejectedPruned = "pruned"
+	// RejectedUnavailable items aren't sold at the build's trader levels
+	RejectedUnavailable = "unavailable"
+)
+
+// RejectedItem is an item which fits a slot but wasn't chosen for it
+type RejectedItem struct {
+	ID     string `json:"item_id"`
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+	// ConflictsWith is the chosen item a conflicting item can't be fitted with
+	ConflictsWith string `json:"conflicts_with,omitempty"`
+	// Trader and TraderLevel are the lowest trader level an unavailable item is sold at, empty if no trader sells it
+	Trader      string `json:"trader,omitempty"`
+	TraderLevel int    `json:"trader_level,omitempty"`
+}
+
+// SlotExplanation is why a slot of a build holds its item
+type SlotExplanation struct {
+	SlotID   string `json:"slot_id"`
+	SlotName string `json:"slot_name"`
+	// ItemID is the chosen item, empty when the slot was left empty
+	ItemID string `json:"item_id"`
+	// Alternatives are the best items which weren't chosen, best first
+	Alternatives []RejectedItem `json:"alternatives"`
+}
+
+// BuildExplanation explains every slot of a build, the weapon's and those of its mods
+type BuildExplanation struct {
+	BuildType string            `json:"build_type"`
+	Slots     []SlotExplanation `json:"slots"`
+}
+
+// GetBuildExplanation returns the explanation stored with a completed build, nil if it has none
+func GetBuildExplanation(db *sql.DB, buildID int) (*BuildExplanation, error) {
+	var explanation sql.NullString
+	err := db.QueryRow(`SELECT explanation FROM optimum_builds WHERE build_id = $1;`, buildID).Scan(&explanation)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && !explanation.Valid) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &BuildExplanation{}
+	if err := json.Unmarshal([]byte(explanation.String), result); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// BuildFilter narrows the optimum builds a query applies to. Empty fields match everything.
+type BuildFilter struct {
+	ItemIDs   []string
+	BuildType string
+	// each entry is a full set of trader levels, a build matches if it was evaluated for any of them
+	TraderLevels [][]TraderLevel
+	// when set only builds in this shard match
+	Shard Shard
+	// Profile is the name of a ConstraintProfile
+	Profile string
+}
+
+// IsEmpty reports whether the filter matches every build
+func (f BuildFilter) IsEmpty() bool {
+	return len(f.ItemIDs) == 0 && f.BuildType == "" && len(f.TraderLevels) == 0 && !f.Shard.IsSet() && f.Profile == ""
+}
+
+// conditions returns SQL conditions on the optimum_builds alias ob, each prefixed with AND, with their
+// placeholders numbered after the args already in args.
+func (f BuildFilter) conditions(args []interface{}) (string, []interface{}) {
+	var sb strings.Builder
+
+	if len(f.ItemIDs) > 0 {
+		args = append(args, pq.Array(f.ItemIDs))
+		sb.WriteString(fmt.Sprintf(" AND ob.item_id = ANY($%d)", len(args)))
+	}
+
+	if f.BuildType != "" {
+		args = append(args, f.BuildType)
+		sb.WriteString(fmt.Sprintf(" AND ob.build_type = $%d", len(args)))
+	}
+
+	if f.Profile != "" {
+		args = append(args, f.Profile)
+		sb.WriteString(fmt.Sprintf(" AND ob.profile = $%d", len(args)))
+	}
+
+	if len(f.TraderLevels) > 0 {
+		keys := make([]string, 0, len(f.TraderLevels))
+		for _, levels := range f.TraderLevels {
+			keys = append(keys, traderLevelsKey(levels))
+		}
+		args = append(args, pq.Array(keys))
+		sb.WriteString(fmt.Sprintf(" AND concat_ws(',', ob.jaeger_level, ob.prapor_level, ob.peacekeeper_level, ob.mechanic_level, ob.skier_level) = ANY($%d)", len(args)))
+	}
+
+	if f.Shard.IsSet() {
+		args = append(args, f.Shard.Count, f.Shard.Index)
+		sb.WriteString(fmt.Sprintf(" AND ob.shard_key %% $%d = $%d", len(args)-1, len(args)))
+	}
+
+	return sb.String(), args
+}
+
+// traderLevelsKey renders levels as e.g. 4,4,2,3,1 in TraderNames order
+func traderLevelsKey(levels []TraderLevel) string {
+	tradersMap := constraintsToTraderMap(EvaluationConstraints{TraderLevels: levels})
+
+	parts := make([]string, 0, len(TraderNames))
+	for _, name := range TraderNames {
+		parts = append(parts, fmt.Sprintf("%d", tradersMap[name]))
+	}
+
+	return strings.Join(parts, ",")
+}
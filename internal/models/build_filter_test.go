@@ -0,0 +1,69 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildFilter_Conditions(t *testing.T) {
+	filter := BuildFilter{
+		ItemIDs:   []string{"weapon-a"},
+		BuildType: "recoil",
+		TraderLevels: [][]TraderLevel{
+			{
+				{Name: "Skier", Level: 1},
+				{Name: "Jaeger", Level: 4},
+				{Name: "Mechanic", Level: 3},
+				{Name: "Prapor", Level: 4},
+				{Name: "Peacekeeper", Level: 2},
+			},
+		},
+	}
+
+	clause, args := filter.conditions([]interface{}{"Pending"})
+
+	assert.Equal(t, " AND ob.item_id = ANY($2) AND ob.build_type = $3"+
+		" AND concat_ws(',', ob.jaeger_level, ob.prapor_level, ob.peacekeeper_level, ob.mechanic_level, ob.skier_level) = ANY($4)", clause)
+	assert.Len(t, args, 4)
+	assert.Equal(t, "recoil", args[2])
+}
+
+func TestBuildFilter_Shard(t *testing.T) {
+	filter := BuildFilter{Shard: Shard{Index: 1, Count: 3}}
+	clause, args := filter.conditions(nil)
+
+	assert.False(t, filter.IsEmpty())
+	assert.Equal(t, " AND ob.shard_key % $1 = $2", clause)
+	assert.Equal(t, []interface{}{3, 1}, args)
+}
+
+func TestBuildFilter_Profile(t *testing.T) {
+	filter := BuildFilter{Profile: "iron-sights"}
+	clause, args := filter.conditions(nil)
+
+	assert.False(t, filter.IsEmpty())
+	assert.Equal(t, " AND ob.profile = $1", clause)
+	assert.Equal(t, []interface{}{"iron-sights"}, args)
+}
+
+func TestBuildFilter_EmptyMatchesEverything(t *testing.T) {
+	filter := BuildFilter{}
+	clause, args := filter.conditions(nil)
+
+	assert.True(t, filter.IsEmpty())
+	assert.Equal(t, "", clause)
+	assert.Empty(t, args)
+}
+
+func TestTraderLevelsKey(t *testing.T) {
+	levels := []TraderLevel{
+		{Name: "Mechanic", Level: 3},
+		{Name: "Jaeger", Level: 4},
+		{Name: "Prapor", Level: 4},
+		{Name: "Skier", Level: 1},
+		{Name: "Peacekeeper", Level: 2},
+	}
+
+	assert.Equal(t, "4,4,2,3,1", traderLevelsKey(levels))
+}
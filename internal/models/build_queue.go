@@ -0,0 +1,361 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// ErrBuildNotClaimed is returned when a worker touches a build it no longer holds,
+// e.g. because it was requeued after the worker's heartbeat went stale.
+var ErrBuildNotClaimed = errors.New("build is not claimed by this worker")
+
+// ClaimedBuild is a build taken from the queue by a worker
+type ClaimedBuild struct {
+	BuildID      int
+	ItemID       string
+	BuildType    string
+	Profile      string
+	TraderLevels []TraderLevel
+	// Attempts is the number of times evaluating this build has already failed
+	Attempts int
+}
+
+// RetryPolicy decides when a Failed build may be claimed again. The wait before retrying doubles with every
+// failed attempt, starting at Backoff and capped at MaxBackoff.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     time.Duration
+	MaxBackoff  time.Duration
+}
+
+// retryableCondition matches Failed builds which are allowed another attempt, ignoring the backoff.
+// It expects $1 to be the Failed status and $2 the maximum attempts.
+const retryableCondition = `obs.status = $1 AND obs.attempts < $2`
+
+// backoffElapsedCondition matches builds whose last failure was longer ago than the backoff for their attempts, with
+// backoff and maxBackoff the placeholders of RetryPolicy's Backoff and MaxBackoff in seconds
+func backoffElapsedCondition(backoff string, maxBackoff string) string {
+	return `obs.last_error_at < now() - make_interval(secs => LEAST(` + backoff + ` * power(2, GREATEST(obs.attempts - 1, 0)), ` + maxBackoff + `))`
+}
+
+// ClaimPendingBuild atomically moves the oldest Pending build matching filter to InProgress and assigns it to
+// workerID. Failed builds are claimed too once retry allows it, after all Pending builds of the same priority.
+// Builds with a higher priority, such as those requested through the API, are always claimed first.
+// Rows locked by other workers are skipped, so any number of evaluator processes can claim concurrently.
+// Returns nil if there is nothing left to claim.
+func ClaimPendingBuild(db *sql.DB, workerID string, retry RetryPolicy, filter BuildFilter) (*ClaimedBuild, error) {
+	args := []interface{}{
+		EvaluationFailed.ToString(),
+		retry.MaxAttempts,
+		EvaluationPending.ToString(),
+		EvaluationInProgress.ToString(),
+		workerID,
+		retry.Backoff.Seconds(),
+		retry.MaxBackoff.Seconds(),
+	}
+	filterConditions, args := filter.conditions(args)
+
+	query := `
+		WITH next AS (
+			SELECT obs.build_id
+			FROM optimal_build_status obs
+			JOIN optimum_builds ob ON ob.build_id = obs.build_id
+			WHERE (obs.status = $3
+				OR (` + retryableCondition + `
+					AND ` + backoffElapsedCondition("$6", "$7") + `))
+				` + filterConditions + `
+			ORDER BY obs.priority DESC, (obs.status = $3) DESC, obs.build_id
+			LIMIT 1
+			FOR UPDATE OF obs SKIP LOCKED
+		)
+		UPDATE optimal_build_status obs
+		SET status = $4,
+			worker_id = $5,
+			heartbeat_at = now(),
+			evaluation_start = now()
+		FROM next, optimum_builds ob
+		WHERE obs.build_id = next.build_id
+			AND ob.build_id = next.build_id
+		RETURNING ob.build_id, ob.item_id, ob.build_type, ob.profile,
+			ob.jaeger_level, ob.prapor_level, ob.peacekeeper_level, ob.mechanic_level, ob.skier_level,
+			obs.attempts;`
+
+	claimed := &ClaimedBuild{}
+	levels := make(map[string]int, len(TraderNames))
+	var jaeger, prapor, peacekeeper, mechanic, skier int
+	err := db.QueryRow(query, args...).Scan(
+		&claimed.BuildID,
+		&claimed.ItemID,
+		&claimed.BuildType,
+		&claimed.Profile,
+		&jaeger,
+		&prapor,
+		&peacekeeper,
+		&mechanic,
+		&skier,
+		&claimed.Attempts,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	levels["Jaeger"] = jaeger
+	levels["Prapor"] = prapor
+	levels["Peacekeeper"] = peacekeeper
+	levels["Mechanic"] = mechanic
+	levels["Skier"] = skier
+	for _, name := range TraderNames {
+		claimed.TraderLevels = append(claimed.TraderLevels, TraderLevel{Name: name, Level: levels[name]})
+	}
+
+	return claimed, nil
+}
+
+// HeartbeatBuild records that workerID is still evaluating buildID
+func HeartbeatBuild(db *sql.DB, buildID int, workerID string) error {
+	query := `UPDATE optimal_build_status
+		SET heartbeat_at = now()
+		WHERE build_id = $1
+			AND worker_id = $2
+			AND status = $3;`
+	res, err := db.Exec(query, buildID, workerID, EvaluationInProgress.ToString())
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrBuildNotClaimed
+	}
+
+	return nil
+}
+
+// RequeueStaleBuilds moves InProgress builds whose worker hasn't sent a heartbeat within staleAfter
+// back to Pending, returning the number of builds requeued.
+func RequeueStaleBuilds(db *sql.DB, staleAfter time.Duration) (int64, error) {
+	query := `UPDATE optimal_build_status
+		SET status = $1,
+			worker_id = NULL,
+			heartbeat_at = NULL
+		WHERE status = $2
+			AND (heartbeat_at IS NULL OR heartbeat_at < now() - make_interval(secs => $3));`
+	res, err := db.Exec(query, EvaluationPending.ToString(), EvaluationInProgress.ToString(), staleAfter.Seconds())
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// ReleaseWorkerBuilds moves InProgress builds claimed by any of workerIDs back to Pending so they can be
+// claimed again, returning the number of builds released.
+func ReleaseWorkerBuilds(db *sql.DB, workerIDs []string) (int64, error) {
+	query := `UPDATE optimal_build_status
+		SET status = $1,
+			worker_id = NULL,
+			heartbeat_at = NULL
+		WHERE status = $2
+			AND worker_id = ANY($3);`
+	res, err := db.Exec(query, EvaluationPending.ToString(), EvaluationInProgress.ToString(), pq.Array(workerIDs))
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// HasRetryableBuilds reports whether any Failed build matching filter is still allowed another attempt under
+// retry, including builds which are waiting for their backoff to pass.
+func HasRetryableBuilds(db *sql.DB, retry RetryPolicy, filter BuildFilter) (bool, error) {
+	filterConditions, args := filter.conditions([]interface{}{EvaluationFailed.ToString(), retry.MaxAttempts})
+	query := `SELECT EXISTS (
+		SELECT 1
+		FROM optimal_build_status obs
+		JOIN optimum_builds ob ON ob.build_id = obs.build_id
+		WHERE ` + retryableCondition + filterConditions + `
+	);`
+	var exists bool
+	err := db.QueryRow(query, args...).Scan(&exists)
+	return exists, err
+}
+
+// FailedBuild describes a build whose evaluation failed
+type FailedBuild struct {
+	BuildID      int
+	ItemID       string
+	Name         string
+	BuildType    string
+	Profile      string
+	TraderLevels []TraderLevel
+	Reason       string
+	Attempts     int
+	LastErrorAt  *time.Time
+}
+
+// GetFailedBuilds returns Failed builds matching filter, ordered by weapon
+func GetFailedBuilds(db *sql.DB, filter BuildFilter) ([]FailedBuild, error) {
+	filterConditions, args := filter.conditions([]interface{}{EvaluationFailed.ToString()})
+	query := `
+		SELECT ob.build_id, ob.item_id, COALESCE(w.name, ''), ob.build_type, ob.profile,
+			ob.jaeger_level, ob.prapor_level, ob.peacekeeper_level, ob.mechanic_level, ob.skier_level,
+			COALESCE(obs.failure_reason, ''), obs.attempts, obs.last_error_at
+		FROM optimal_build_status obs
+		JOIN optimum_builds ob ON ob.build_id = obs.build_id
+		LEFT JOIN weapons w ON w.item_id = ob.item_id
+		WHERE obs.status = $1` + filterConditions + `
+		ORDER BY ob.item_id, ob.build_id;`
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var builds []FailedBuild
+	for rows.Next() {
+		build := FailedBuild{}
+		var jaeger, prapor, peacekeeper, mechanic, skier int
+		var lastErrorAt sql.NullTime
+		err := rows.Scan(
+			&build.BuildID,
+			&build.ItemID,
+			&build.Name,
+			&build.BuildType,
+			&build.Profile,
+			&jaeger,
+			&prapor,
+			&peacekeeper,
+			&mechanic,
+			&skier,
+			&build.Reason,
+			&build.Attempts,
+			&lastErrorAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		build.TraderLevels = []TraderLevel{
+			{Name: "Jaeger", Level: jaeger},
+			{Name: "Prapor", Level: prapor},
+			{Name: "Peacekeeper", Level: peacekeeper},
+			{Name: "Mechanic", Level: mechanic},
+			{Name: "Skier", Level: skier},
+		}
+		if lastErrorAt.Valid {
+			build.LastErrorAt = &lastErrorAt.Time
+		}
+		builds = append(builds, build)
+	}
+
+	return builds, rows.Err()
+}
+
+// RequeueBuilds moves builds matching filter whose status is one of statuses back to Pending and resets their
+// attempts, returning the number of builds requeued. InProgress builds are never requeued, their worker still owns them.
+func RequeueBuilds(db *sql.DB, filter BuildFilter, statuses []string) (int64, error) {
+	filterConditions, args := filter.conditions([]interface{}{
+		EvaluationPending.ToString(),
+		pq.Array(statuses),
+		EvaluationInProgress.ToString(),
+	})
+	query := `UPDATE optimal_build_status obs
+		SET status = $1,
+			attempts = 0,
+			failure_reason = NULL,
+			worker_id = NULL,
+			heartbeat_at = NULL
+		FROM optimum_builds ob
+		WHERE ob.build_id = obs.build_id
+			AND obs.status = ANY($2)
+			AND obs.status <> $3` + filterConditions + `;`
+	res, err := db.Exec(query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+// BuildStatusCount is the number of builds for a weapon in a given status
+type BuildStatusCount struct {
+	ItemID string
+	Name   string
+	Status string
+	Count  int
+}
+
+// GetBuildStatusCounts counts builds matching filter grouped by weapon and status
+func GetBuildStatusCounts(db *sql.DB, filter BuildFilter) ([]BuildStatusCount, error) {
+	filterConditions, args := filter.conditions(nil)
+	query := `
+		SELECT ob.item_id, COALESCE(w.name, ''), obs.status, COUNT(*)
+		FROM optimal_build_status obs
+		JOIN optimum_builds ob ON ob.build_id = obs.build_id
+		LEFT JOIN weapons w ON w.item_id = ob.item_id
+		WHERE TRUE` + filterConditions + `
+		GROUP BY ob.item_id, w.name, obs.status
+		ORDER BY ob.item_id, obs.status;`
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]BuildStatusCount, 0)
+	for rows.Next() {
+		c := BuildStatusCount{}
+		err := rows.Scan(&c.ItemID, &c.Name, &c.Status, &c.Count)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, rows.Err()
+}
+
+// ShardStatusCount is the number of builds in a shard with a given status
+type ShardStatusCount struct {
+	ShardIndex int
+	Status     string
+	Count      int
+}
+
+// GetShardStatusCounts counts builds matching filter grouped by status and by shard when split into shardCount
+// shards. Counts come straight from optimal_build_status, so they are consistent however many evaluators are running.
+func GetShardStatusCounts(db *sql.DB, filter BuildFilter, shardCount int) ([]ShardStatusCount, error) {
+	filterConditions, args := filter.conditions([]interface{}{shardCount})
+	query := `
+		SELECT ob.shard_key % $1, obs.status, COUNT(*)
+		FROM optimal_build_status obs
+		JOIN optimum_builds ob ON ob.build_id = obs.build_id
+		WHERE TRUE` + filterConditions + `
+		GROUP BY 1, obs.status
+		ORDER BY 1, obs.status;`
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	counts := make([]ShardStatusCount, 0)
+	for rows.Next() {
+		c := ShardStatusCount{}
+		err := rows.Scan(&c.ShardIndex, &c.Status, &c.Count)
+		if err != nil {
+			return nil, err
+		}
+		counts = append(counts, c)
+	}
+
+	return counts, rows.Err()
+}
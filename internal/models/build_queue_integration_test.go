@@ -0,0 +1,213 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"tarkov-build-optimiser/internal/db"
+	"tarkov-build-optimiser/internal/env"
+	"tarkov-build-optimiser/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mockQueueConstraints(level int) models.EvaluationConstraints {
+	levels := make([]models.TraderLevel, 0, len(models.TraderNames))
+	for _, name := range models.TraderNames {
+		levels = append(levels, models.TraderLevel{Name: name, Level: level})
+	}
+	return models.EvaluationConstraints{TraderLevels: levels}
+}
+
+// TestBuildQueueIntegration verifies builds are claimed exactly once and stale claims are requeued
+func TestBuildQueueIntegration(t *testing.T) {
+	environment, err := env.Get()
+	require.NoError(t, err, "Failed to get environment")
+
+	dbClient, err := db.CreateBuildOptimiserDBClient(environment)
+	require.NoError(t, err, "Failed to connect to database")
+	conn := dbClient.Conn
+
+	require.NoError(t, models.PurgeOptimumBuilds(conn))
+
+	firstID, err := models.CreatePendingOptimumBuild(conn, "mock_weapon", "recoil", mockQueueConstraints(1))
+	require.NoError(t, err)
+	secondID, err := models.CreatePendingOptimumBuild(conn, "mock_weapon", "recoil", mockQueueConstraints(2))
+	require.NoError(t, err)
+
+	// creating the same build again returns the existing build
+	duplicateID, err := models.CreatePendingOptimumBuild(conn, "mock_weapon", "recoil", mockQueueConstraints(1))
+	require.NoError(t, err)
+	assert.Equal(t, firstID, duplicateID)
+
+	first, err := models.ClaimPendingBuild(conn, "worker-a", models.RetryPolicy{}, models.BuildFilter{})
+	require.NoError(t, err)
+	require.NotNil(t, first)
+	second, err := models.ClaimPendingBuild(conn, "worker-b", models.RetryPolicy{}, models.BuildFilter{})
+	require.NoError(t, err)
+	require.NotNil(t, second)
+
+	assert.ElementsMatch(t, []int{firstID, secondID}, []int{first.BuildID, second.BuildID})
+	assert.Equal(t, "mock_weapon", first.ItemID)
+	assert.Len(t, first.TraderLevels, len(models.TraderNames))
+
+	empty, err := models.ClaimPendingBuild(conn, "worker-c", models.RetryPolicy{}, models.BuildFilter{})
+	require.NoError(t, err)
+	assert.Nil(t, empty, "expected the queue to be drained")
+
+	require.NoError(t, models.HeartbeatBuild(conn, first.BuildID, "worker-a"))
+	assert.ErrorIs(t, models.HeartbeatBuild(conn, first.BuildID, "worker-b"), models.ErrBuildNotClaimed)
+
+	// nothing is stale yet
+	requeued, err := models.RequeueStaleBuilds(conn, time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), requeued)
+
+	time.Sleep(10 * time.Millisecond)
+	requeued, err = models.RequeueStaleBuilds(conn, time.Millisecond)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), requeued)
+
+	reclaimed, err := models.ClaimPendingBuild(conn, "worker-c", models.RetryPolicy{}, models.BuildFilter{})
+	require.NoError(t, err)
+	require.NotNil(t, reclaimed)
+	assert.ErrorIs(t, models.HeartbeatBuild(conn, reclaimed.BuildID, "worker-a"), models.ErrBuildNotClaimed)
+
+	require.NoError(t, models.PurgeOptimumBuilds(conn))
+}
+
+// TestBuildRetryIntegration verifies failed builds record a reason and are retried until they run out of attempts
+func TestBuildRetryIntegration(t *testing.T) {
+	environment, err := env.Get()
+	require.NoError(t, err, "Failed to get environment")
+
+	dbClient, err := db.CreateBuildOptimiserDBClient(environment)
+	require.NoError(t, err, "Failed to connect to database")
+	conn := dbClient.Conn
+
+	require.NoError(t, models.PurgeOptimumBuilds(conn))
+
+	retry := models.RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+	buildID, err := models.CreatePendingOptimumBuild(conn, "mock_weapon", "recoil", mockQueueConstraints(1))
+	require.NoError(t, err)
+
+	claimed, err := models.ClaimPendingBuild(conn, "worker-a", retry, models.BuildFilter{})
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, 0, claimed.Attempts)
+	assert.ErrorIs(t, models.SetBuildFailed(conn, buildID, "worker-b", "boom"), models.ErrBuildNotClaimed)
+	require.NoError(t, models.SetBuildFailed(conn, buildID, "worker-a", "failed to create candidate tree: boom"))
+	assert.ErrorIs(t, models.SetBuildFailed(conn, buildID, "worker-a", "boom"), models.ErrBuildNotClaimed,
+		"expected a build which is no longer InProgress not to be failed again")
+
+	failed, err := models.GetFailedBuilds(conn, models.BuildFilter{ItemIDs: []string{"mock_weapon"}})
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	assert.Equal(t, "failed to create candidate tree: boom", failed[0].Reason)
+	assert.Equal(t, 1, failed[0].Attempts)
+	assert.NotNil(t, failed[0].LastErrorAt)
+
+	time.Sleep(10 * time.Millisecond)
+	claimed, err = models.ClaimPendingBuild(conn, "worker-a", retry, models.BuildFilter{})
+	require.NoError(t, err)
+	require.NotNil(t, claimed, "expected the failed build to be retried")
+	assert.Equal(t, 1, claimed.Attempts)
+	require.NoError(t, models.SetBuildFailed(conn, buildID, "worker-a", "failed to save build: boom"))
+
+	time.Sleep(10 * time.Millisecond)
+	claimed, err = models.ClaimPendingBuild(conn, "worker-a", retry, models.BuildFilter{})
+	require.NoError(t, err)
+	assert.Nil(t, claimed, "expected no retries once max attempts is reached")
+
+	retryable, err := models.HasRetryableBuilds(conn, retry, models.BuildFilter{})
+	require.NoError(t, err)
+	assert.False(t, retryable)
+
+	requeued, err := models.RequeueBuilds(conn, models.BuildFilter{ItemIDs: []string{"mock_weapon"}}, []string{models.EvaluationFailed.ToString()})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), requeued)
+
+	claimed, err = models.ClaimPendingBuild(conn, "worker-a", retry, models.BuildFilter{})
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, 0, claimed.Attempts)
+
+	require.NoError(t, models.PurgeOptimumBuilds(conn))
+}
+
+// TestBuildQueueFilterIntegration verifies workers only claim builds matching their filter
+func TestBuildQueueFilterIntegration(t *testing.T) {
+	environment, err := env.Get()
+	require.NoError(t, err, "Failed to get environment")
+
+	dbClient, err := db.CreateBuildOptimiserDBClient(environment)
+	require.NoError(t, err, "Failed to connect to database")
+	conn := dbClient.Conn
+
+	require.NoError(t, models.PurgeOptimumBuilds(conn))
+
+	_, err = models.CreatePendingOptimumBuild(conn, "mock_weapon", "recoil", mockQueueConstraints(1))
+	require.NoError(t, err)
+	_, err = models.CreatePendingOptimumBuild(conn, "other_weapon", "recoil", mockQueueConstraints(1))
+	require.NoError(t, err)
+	wantedID, err := models.CreatePendingOptimumBuild(conn, "other_weapon", "recoil", mockQueueConstraints(3))
+	require.NoError(t, err)
+
+	filter := models.BuildFilter{
+		ItemIDs:      []string{"other_weapon"},
+		BuildType:    "recoil",
+		TraderLevels: [][]models.TraderLevel{mockQueueConstraints(3).TraderLevels},
+	}
+
+	claimed, err := models.ClaimPendingBuild(conn, "worker-a", models.RetryPolicy{}, filter)
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, wantedID, claimed.BuildID)
+
+	claimed, err = models.ClaimPendingBuild(conn, "worker-a", models.RetryPolicy{}, filter)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+
+	counts, err := models.GetBuildStatusCounts(conn, models.BuildFilter{ItemIDs: []string{"other_weapon"}})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []models.BuildStatusCount{
+		{ItemID: "other_weapon", Status: models.EvaluationPending.ToString(), Count: 1},
+		{ItemID: "other_weapon", Status: models.EvaluationInProgress.ToString(), Count: 1},
+	}, counts)
+
+	purged, err := models.PurgeOptimumBuildsByFilter(conn, models.BuildFilter{ItemIDs: []string{"other_weapon"}})
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+
+	require.NoError(t, models.PurgeOptimumBuilds(conn))
+}
+
+// TestShardKeyIntegration verifies the generated shard_key column matches models.ShardKey
+func TestShardKeyIntegration(t *testing.T) {
+	environment, err := env.Get()
+	require.NoError(t, err, "Failed to get environment")
+
+	dbClient, err := db.CreateBuildOptimiserDBClient(environment)
+	require.NoError(t, err, "Failed to connect to database")
+	conn := dbClient.Conn
+
+	require.NoError(t, models.PurgeOptimumBuilds(conn))
+
+	constraints := mockQueueConstraints(2)
+	buildID, err := models.CreatePendingOptimumBuild(conn, "mock_weapon", "recoil", constraints)
+	require.NoError(t, err)
+
+	var shardKey int64
+	require.NoError(t, conn.QueryRow(`SELECT shard_key FROM optimum_builds WHERE build_id = $1;`, buildID).Scan(&shardKey))
+	assert.Equal(t, models.ShardKey("mock_weapon", "recoil", constraints.TraderLevels), shardKey)
+
+	shard := models.Shard{Index: int(shardKey % 3), Count: 3}
+	claimed, err := models.ClaimPendingBuild(conn, "worker-a", models.RetryPolicy{}, models.BuildFilter{Shard: shard})
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, buildID, claimed.BuildID)
+
+	require.NoError(t, models.PurgeOptimumBuilds(conn))
+}
@@ -0,0 +1,184 @@
+package models
+
+import (
+	"database/sql"
+	"time"
+)
+
+// BuildStats describes a single evaluation of a build
+type BuildStats struct {
+	BuildID            int
+	ItemID             string
+	BuildType          string
+	WorkerID           string
+	Duration           time.Duration
+	ItemsEvaluated     int64
+	CacheHits          int64
+	CacheMisses        int64
+	AllowedItemsBefore int
+	AllowedItemsAfter  int
+	SlotsBefore        int
+	SlotsAfter         int
+}
+
+// WeaponDurationStats aggregates evaluation durations for a weapon across all recorded runs
+type WeaponDurationStats struct {
+	ItemID            string  `json:"item_id"`
+	Name              string  `json:"name"`
+	Evaluations       int64   `json:"evaluations"`
+	AvgDurationMs     float64 `json:"avg_duration_ms"`
+	MaxDurationMs     int64   `json:"max_duration_ms"`
+	TotalDurationMs   int64   `json:"total_duration_ms"`
+	AvgItemsEvaluated float64 `json:"avg_items_evaluated"`
+}
+
+// WeaponPruningStats aggregates how much candidate tree pruning shrank a weapon's search space
+type WeaponPruningStats struct {
+	ItemID                string  `json:"item_id"`
+	Name                  string  `json:"name"`
+	Evaluations           int64   `json:"evaluations"`
+	AvgAllowedItemsBefore float64 `json:"avg_allowed_items_before"`
+	AvgAllowedItemsAfter  float64 `json:"avg_allowed_items_after"`
+	AvgSlotsBefore        float64 `json:"avg_slots_before"`
+	AvgSlotsAfter         float64 `json:"avg_slots_after"`
+	// fraction of allowed items removed by pruning, 0 when nothing was pruned
+	AllowedItemsReduction float64 `json:"allowed_items_reduction"`
+}
+
+func InsertBuildStats(db *sql.DB, stats BuildStats) error {
+	query := `INSERT INTO build_stats (
+			build_id,
+			item_id,
+			build_type,
+			worker_id,
+			duration_ms,
+			items_evaluated,
+			cache_hits,
+			cache_misses,
+			allowed_items_before,
+			allowed_items_after,
+			slots_before,
+			slots_after
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);`
+	_, err := db.Exec(
+		query,
+		stats.BuildID,
+		stats.ItemID,
+		stats.BuildType,
+		stats.WorkerID,
+		stats.Duration.Milliseconds(),
+		stats.ItemsEvaluated,
+		stats.CacheHits,
+		stats.CacheMisses,
+		stats.AllowedItemsBefore,
+		stats.AllowedItemsAfter,
+		stats.SlotsBefore,
+		stats.SlotsAfter,
+	)
+	return err
+}
+
+// GetSlowestWeapons returns up to limit weapons ordered by their average evaluation duration, slowest first.
+// A limit below 1 returns every weapon with recorded stats.
+func GetSlowestWeapons(db *sql.DB, limit int) ([]WeaponDurationStats, error) {
+	var limitArg sql.NullInt64
+	if limit > 0 {
+		limitArg = sql.NullInt64{Int64: int64(limit), Valid: true}
+	}
+
+	query := `
+		SELECT bs.item_id,
+			COALESCE(w.name, ''),
+			COUNT(*),
+			AVG(bs.duration_ms),
+			MAX(bs.duration_ms),
+			SUM(bs.duration_ms),
+			AVG(bs.items_evaluated)
+		FROM build_stats bs
+		LEFT JOIN weapons w ON w.item_id = bs.item_id
+		GROUP BY bs.item_id, w.name
+		ORDER BY AVG(bs.duration_ms) DESC
+		LIMIT $1;`
+	rows, err := db.Query(query, limitArg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]WeaponDurationStats, 0)
+	for rows.Next() {
+		s := WeaponDurationStats{}
+		err := rows.Scan(&s.ItemID, &s.Name, &s.Evaluations, &s.AvgDurationMs, &s.MaxDurationMs, &s.TotalDurationMs, &s.AvgItemsEvaluated)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, s)
+	}
+
+	return results, rows.Err()
+}
+
+// GetPruningEffectiveness returns up to limit weapons ordered by their average allowed item count after
+// pruning, largest search spaces first
+func GetPruningEffectiveness(db *sql.DB, limit int) ([]WeaponPruningStats, error) {
+	query := `
+		SELECT bs.item_id,
+			COALESCE(w.name, ''),
+			COUNT(*),
+			AVG(bs.allowed_items_before),
+			AVG(bs.allowed_items_after),
+			AVG(bs.slots_before),
+			AVG(bs.slots_after),
+			COALESCE(1 - SUM(bs.allowed_items_after)::float / NULLIF(SUM(bs.allowed_items_before), 0), 0)
+		FROM build_stats bs
+		LEFT JOIN weapons w ON w.item_id = bs.item_id
+		GROUP BY bs.item_id, w.name
+		ORDER BY AVG(bs.allowed_items_after) DESC
+		LIMIT $1;`
+	rows, err := db.Query(query, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := make([]WeaponPruningStats, 0)
+	for rows.Next() {
+		s := WeaponPruningStats{}
+		err := rows.Scan(
+			&s.ItemID,
+			&s.Name,
+			&s.Evaluations,
+			&s.AvgAllowedItemsBefore,
+			&s.AvgAllowedItemsAfter,
+			&s.AvgSlotsBefore,
+			&s.AvgSlotsAfter,
+			&s.AllowedItemsReduction,
+		)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, s)
+	}
+
+	return results, rows.Err()
+}
+
+// BuildCostTotals sums duration and search space size across every recorded evaluation
+type BuildCostTotals struct {
+	Evaluations       int64
+	DurationMs        int64
+	AllowedItemsAfter int64
+}
+
+func GetBuildCostTotals(db *sql.DB) (BuildCostTotals, error) {
+	query := `SELECT COUNT(*), COALESCE(SUM(duration_ms), 0), COALESCE(SUM(allowed_items_after), 0) FROM build_stats;`
+	totals := BuildCostTotals{}
+	err := db.QueryRow(query).Scan(&totals.Evaluations, &totals.DurationMs, &totals.AllowedItemsAfter)
+	return totals, err
+}
+
+func PurgeBuildStats(db *sql.DB) error {
+	_, err := db.Exec("TRUNCATE build_stats;")
+	return err
+}
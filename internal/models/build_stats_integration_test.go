@@ -0,0 +1,51 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"tarkov-build-optimiser/internal/db"
+	"tarkov-build-optimiser/internal/env"
+	"tarkov-build-optimiser/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBuildStatsIntegration verifies stats are aggregated per weapon across evaluations
+func TestBuildStatsIntegration(t *testing.T) {
+	environment, err := env.Get()
+	require.NoError(t, err, "Failed to get environment")
+
+	dbClient, err := db.CreateBuildOptimiserDBClient(environment)
+	require.NoError(t, err, "Failed to connect to database")
+	conn := dbClient.Conn
+
+	require.NoError(t, models.PurgeBuildStats(conn))
+
+	stats := []models.BuildStats{
+		{BuildID: 1, ItemID: "fast_weapon", BuildType: "recoil", Duration: 100 * time.Millisecond, AllowedItemsBefore: 10, AllowedItemsAfter: 10, SlotsBefore: 4, SlotsAfter: 4},
+		{BuildID: 2, ItemID: "slow_weapon", BuildType: "recoil", Duration: 3 * time.Second, ItemsEvaluated: 500, AllowedItemsBefore: 100, AllowedItemsAfter: 40, SlotsBefore: 20, SlotsAfter: 12},
+		{BuildID: 3, ItemID: "slow_weapon", BuildType: "recoil", Duration: time.Second, ItemsEvaluated: 300, AllowedItemsBefore: 100, AllowedItemsAfter: 20, SlotsBefore: 20, SlotsAfter: 8},
+	}
+	for _, s := range stats {
+		require.NoError(t, models.InsertBuildStats(conn, s))
+	}
+
+	slowest, err := models.GetSlowestWeapons(conn, 10)
+	require.NoError(t, err)
+	require.Len(t, slowest, 2)
+	assert.Equal(t, "slow_weapon", slowest[0].ItemID)
+	assert.Equal(t, int64(2), slowest[0].Evaluations)
+	assert.InDelta(t, 2000, slowest[0].AvgDurationMs, 0.001)
+	assert.Equal(t, int64(3000), slowest[0].MaxDurationMs)
+
+	pruning, err := models.GetPruningEffectiveness(conn, 10)
+	require.NoError(t, err)
+	require.Len(t, pruning, 2)
+	assert.Equal(t, "slow_weapon", pruning[0].ItemID)
+	assert.InDelta(t, 0.7, pruning[0].AllowedItemsReduction, 0.001)
+	assert.InDelta(t, 0, pruning[1].AllowedItemsReduction, 0.001)
+
+	require.NoError(t, models.PurgeBuildStats(conn))
+}
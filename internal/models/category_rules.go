@@ -0,0 +1,31 @@
+package models
+
+import "strings"
+
+// CategoryMatches reports whether a category rule names the category, by ID or case-insensitively by name
+func CategoryMatches(rule string, categoryID string, categoryName string) bool {
+	if rule == "" {
+		return false
+	}
+	return rule == categoryID || strings.EqualFold(rule, categoryName)
+}
+
+// ExcludesCategory reports whether mods of the category may not be used under the constraints
+func (c EvaluationConstraints) ExcludesCategory(categoryID string, categoryName string) bool {
+	for _, rule := range c.ExcludedCategories {
+		if CategoryMatches(rule, categoryID, categoryName) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsRequiredCategory reports whether the category satisfies one of the constraints' required categories
+func (c EvaluationConstraints) IsRequiredCategory(categoryID string, categoryName string) bool {
+	for _, rule := range c.RequiredCategories {
+		if CategoryMatches(rule, categoryID, categoryName) {
+			return true
+		}
+	}
+	return false
+}
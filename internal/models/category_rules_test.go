@@ -0,0 +1,28 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategoryMatches(t *testing.T) {
+	assert.True(t, CategoryMatches("Silencer", "550aa4cd4bdc2dd8348b456c", "Silencer"))
+	assert.True(t, CategoryMatches("silencer", "550aa4cd4bdc2dd8348b456c", "Silencer"))
+	assert.True(t, CategoryMatches("550aa4cd4bdc2dd8348b456c", "550aa4cd4bdc2dd8348b456c", "Silencer"))
+	assert.False(t, CategoryMatches("Foregrip", "550aa4cd4bdc2dd8348b456c", "Silencer"))
+	assert.False(t, CategoryMatches("", "", ""))
+}
+
+func TestEvaluationConstraints_CategoryRules(t *testing.T) {
+	constraints := EvaluationConstraints{
+		ExcludedCategories: []string{"Silencer"},
+		RequiredCategories: []string{"Foregrip"},
+	}
+
+	assert.True(t, constraints.ExcludesCategory("", "Silencer"))
+	assert.False(t, constraints.ExcludesCategory("", "Foregrip"))
+	assert.True(t, constraints.IsRequiredCategory("", "foregrip"))
+	assert.False(t, constraints.IsRequiredCategory("", "Silencer"))
+	assert.False(t, EvaluationConstraints{}.ExcludesCategory("", "Silencer"))
+}
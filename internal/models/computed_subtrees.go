@@ -21,15 +21,15 @@ type ComputedSubtree struct {
 	SkierLevel             int                 `json:"skier_level"`
 	DepthEvaluated         int                 `json:"depth_evaluated"`
 	GameDataVersion        string              `json:"game_data_version"`
-	RecoilSum              int                 `json:"recoil_sum"`
-	ErgonomicsSum          int                 `json:"ergonomics_sum"`
+	RecoilSum              Stat                `json:"recoil_sum"`
+	ErgonomicsSum          Stat                `json:"ergonomics_sum"`
 	ChosenAssignments      []SubtreeAssignment `json:"chosen_assignments"`
 	ChosenItemIDs          []string            `json:"chosen_item_ids"`
 	ConflictsItemIDs       []string            `json:"conflicts_item_ids"`
-	PotentialMinRecoil     int                 `json:"potential_min_recoil"`
-	PotentialMaxRecoil     int                 `json:"potential_max_recoil"`
-	PotentialMinErgonomics int                 `json:"potential_min_ergonomics"`
-	PotentialMaxErgonomics int                 `json:"potential_max_ergonomics"`
+	PotentialMinRecoil     Stat                `json:"potential_min_recoil"`
+	PotentialMaxRecoil     Stat                `json:"potential_max_recoil"`
+	PotentialMinErgonomics Stat                `json:"potential_min_ergonomics"`
+	PotentialMaxErgonomics Stat                `json:"potential_max_ergonomics"`
 }
 
 func UpsertComputedSubtree(db *sql.DB, s *ComputedSubtree) error {
@@ -0,0 +1,163 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/lib/pq"
+)
+
+// DefaultProfileName is the constraint profile used when none is requested
+const DefaultProfileName = "default"
+
+// ConstraintProfile is a named set of constraints shared by the evaluator and the API. Builds are keyed by
+// profile, so a build is only ever served for the constraints it was evaluated with.
+type ConstraintProfile struct {
+	Name             string   `json:"name"`
+	Description      string   `json:"description"`
+	IgnoredSlotNames []string `json:"ignored_slot_names"`
+	IgnoredItemIDs   []string `json:"ignored_item_ids"`
+	// ExcludedCategories and RequiredCategories are weapon mod category names or IDs, see EvaluationConstraints
+	ExcludedCategories []string `json:"excluded_categories"`
+	RequiredCategories []string `json:"required_categories"`
+	// MaxWeight in kg of the weapon and its mods, no limit when 0
+	MaxWeight float64 `json:"max_weight"`
+}
+
+// Constraints returns the profile's constraints at the given trader levels
+func (p *ConstraintProfile) Constraints(traderLevels []TraderLevel) EvaluationConstraints {
+	return EvaluationConstraints{
+		Profile:          p.Name,
+		TraderLevels:     traderLevels,
+		IgnoredSlotNames: append([]string{}, p.IgnoredSlotNames...),
+		IgnoredItemIDs:   append([]string{}, p.IgnoredItemIDs...),
+		// keep nil when empty so constraints without category rules compare equal however they were built
+		ExcludedCategories: append([]string(nil), p.ExcludedCategories...),
+		RequiredCategories: append([]string(nil), p.RequiredCategories...),
+		MaxWeight:          p.MaxWeight,
+	}
+}
+
+// WithIgnoredSlots returns the profile with the named slots also left empty, or the profile itself when it already
+// leaves them all empty. As builds are keyed by profile the result is named after the profile and the added slots,
+// e.g. default-ignore-mount, so its builds are never mistaken for the profile's.
+func (p *ConstraintProfile) WithIgnoredSlots(slotNames []string) *ConstraintProfile {
+	ignored := make(map[string]bool, len(p.IgnoredSlotNames))
+	for _, name := range p.IgnoredSlotNames {
+		ignored[name] = true
+	}
+
+	added := make([]string, 0, len(slotNames))
+	for _, name := range slotNames {
+		if !ignored[name] {
+			ignored[name] = true
+			added = append(added, name)
+		}
+	}
+	if len(added) == 0 {
+		return p
+	}
+	sort.Strings(added)
+
+	derived := *p
+	derived.IgnoredSlotNames = append(append([]string{}, p.IgnoredSlotNames...), added...)
+	derived.Name = p.Name + "-ignore-" + strings.ReplaceAll(strings.ToLower(strings.Join(added, "-")), " ", "-")
+	derived.Description = fmt.Sprintf("%s with %s slots also left empty", p.Name, strings.Join(added, ", "))
+	return &derived
+}
+
+// profileName returns the profile constraints were built from, builds predating profiles used DefaultProfileName
+func profileName(constraints EvaluationConstraints) string {
+	if constraints.Profile == "" {
+		return DefaultProfileName
+	}
+	return constraints.Profile
+}
+
+func UpsertConstraintProfile(db *sql.DB, profile ConstraintProfile) error {
+	query := `INSERT INTO constraint_profiles (name, description, ignored_slot_names, ignored_item_ids, excluded_categories, required_categories, max_weight)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (name) DO UPDATE SET
+			description = excluded.description,
+			ignored_slot_names = excluded.ignored_slot_names,
+			ignored_item_ids = excluded.ignored_item_ids,
+			excluded_categories = excluded.excluded_categories,
+			required_categories = excluded.required_categories,
+			max_weight = excluded.max_weight;`
+	_, err := db.Exec(query,
+		profile.Name,
+		profile.Description,
+		pq.Array(nonNil(profile.IgnoredSlotNames)),
+		pq.Array(nonNil(profile.IgnoredItemIDs)),
+		pq.Array(nonNil(profile.ExcludedCategories)),
+		pq.Array(nonNil(profile.RequiredCategories)),
+		profile.MaxWeight,
+	)
+	return err
+}
+
+// GetConstraintProfileByName returns the named profile, or nil if it doesn't exist
+func GetConstraintProfileByName(db *sql.DB, name string) (*ConstraintProfile, error) {
+	query := `SELECT name, description, ignored_slot_names, ignored_item_ids, excluded_categories, required_categories, max_weight
+		FROM constraint_profiles
+		WHERE name = $1;`
+	profile := &ConstraintProfile{}
+	err := db.QueryRow(query, name).Scan(
+		&profile.Name,
+		&profile.Description,
+		pq.Array(&profile.IgnoredSlotNames),
+		pq.Array(&profile.IgnoredItemIDs),
+		pq.Array(&profile.ExcludedCategories),
+		pq.Array(&profile.RequiredCategories),
+		&profile.MaxWeight,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+func GetAllConstraintProfiles(db *sql.DB) ([]ConstraintProfile, error) {
+	query := `SELECT name, description, ignored_slot_names, ignored_item_ids, excluded_categories, required_categories, max_weight
+		FROM constraint_profiles
+		ORDER BY name;`
+	rows, err := db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	profiles := make([]ConstraintProfile, 0)
+	for rows.Next() {
+		profile := ConstraintProfile{}
+		err := rows.Scan(
+			&profile.Name,
+			&profile.Description,
+			pq.Array(&profile.IgnoredSlotNames),
+			pq.Array(&profile.IgnoredItemIDs),
+			pq.Array(&profile.ExcludedCategories),
+			pq.Array(&profile.RequiredCategories),
+			&profile.MaxWeight,
+		)
+		if err != nil {
+			return nil, err
+		}
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, rows.Err()
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,74 @@
+package models_test
+
+import (
+	"testing"
+
+	"tarkov-build-optimiser/internal/db"
+	"tarkov-build-optimiser/internal/env"
+	"tarkov-build-optimiser/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConstraintProfilesIntegration verifies profiles are loaded from the database and builds are keyed by profile
+func TestConstraintProfilesIntegration(t *testing.T) {
+	environment, err := env.Get()
+	require.NoError(t, err, "Failed to get environment")
+
+	dbClient, err := db.CreateBuildOptimiserDBClient(environment)
+	require.NoError(t, err, "Failed to connect to database")
+	conn := dbClient.Conn
+
+	require.NoError(t, models.PurgeOptimumBuilds(conn))
+
+	defaultProfile, err := models.GetConstraintProfileByName(conn, models.DefaultProfileName)
+	require.NoError(t, err)
+	require.NotNil(t, defaultProfile)
+	assert.Equal(t, []string{"Scope", "Ubgl", "Tactical"}, defaultProfile.IgnoredSlotNames)
+
+	require.NoError(t, models.UpsertConstraintProfile(conn, models.ConstraintProfile{
+		Name:               "mock-profile",
+		Description:        "used by integration tests",
+		IgnoredSlotNames:   []string{"Scope"},
+		IgnoredItemIDs:     []string{"mock_item"},
+		ExcludedCategories: []string{"Silencer"},
+		RequiredCategories: []string{"Foregrip"},
+	}))
+	mockProfile, err := models.GetConstraintProfileByName(conn, "mock-profile")
+	require.NoError(t, err)
+	require.NotNil(t, mockProfile)
+	assert.Equal(t, []string{"mock_item"}, mockProfile.IgnoredItemIDs)
+	assert.Equal(t, []string{"Silencer"}, mockProfile.ExcludedCategories)
+	assert.Equal(t, []string{"Foregrip"}, mockProfile.RequiredCategories)
+
+	missing, err := models.GetConstraintProfileByName(conn, "missing-profile")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	levels := mockQueueConstraints(4).TraderLevels
+	defaultID, err := models.CreatePendingOptimumBuild(conn, "mock_weapon", "recoil", defaultProfile.Constraints(levels))
+	require.NoError(t, err)
+	mockID, err := models.CreatePendingOptimumBuild(conn, "mock_weapon", "recoil", mockProfile.Constraints(levels))
+	require.NoError(t, err)
+	assert.NotEqual(t, defaultID, mockID, "expected each profile to get its own build")
+
+	build, err := models.GetOptimumBuildByConstraints(conn, "mock_weapon", "recoil", mockProfile.Constraints(levels))
+	require.NoError(t, err)
+	require.NotNil(t, build)
+	assert.Equal(t, mockID, build.BuildID)
+
+	counts, err := models.GetBuildStatusCounts(conn, models.BuildFilter{Profile: "mock-profile"})
+	require.NoError(t, err)
+	require.Len(t, counts, 1)
+	assert.Equal(t, 1, counts[0].Count)
+
+	claimed, err := models.ClaimPendingBuild(conn, "worker-a", models.RetryPolicy{}, models.BuildFilter{Profile: "mock-profile"})
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, "mock-profile", claimed.Profile)
+
+	require.NoError(t, models.PurgeOptimumBuilds(conn))
+	_, err = conn.Exec("DELETE FROM constraint_profiles WHERE name = $1", "mock-profile")
+	require.NoError(t, err)
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConstraintProfile_WithIgnoredSlots(t *testing.T) {
+	profile := &ConstraintProfile{Name: "default", IgnoredSlotNames: []string{"Scope", "Ubgl", "Tactical"}, RequiredCategories: []string{"Foregrip"}}
+
+	derived := profile.WithIgnoredSlots([]string{"Pistol Grip", "Scope", "Mount"})
+	assert.Equal(t, "default-ignore-mount-pistol-grip", derived.Name)
+	assert.Equal(t, []string{"Scope", "Ubgl", "Tactical", "Mount", "Pistol Grip"}, derived.IgnoredSlotNames)
+	assert.Equal(t, []string{"Foregrip"}, derived.RequiredCategories)
+	// the profile itself is unchanged
+	assert.Equal(t, []string{"Scope", "Ubgl", "Tactical"}, profile.IgnoredSlotNames)
+
+	// slots the profile already leaves empty don't need a profile of their own
+	assert.Same(t, profile, profile.WithIgnoredSlots([]string{"Scope"}))
+	assert.Same(t, profile, profile.WithIgnoredSlots(nil))
+}
@@ -0,0 +1,124 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// PriorityOnDemand is given to builds requested through the API, so evaluators claim them before batch builds
+const PriorityOnDemand = 100
+
+// Job is a request to evaluate a single build on demand. A job's ID is the ID of the build it evaluates.
+type Job struct {
+	ID            int           `json:"id"`
+	ItemID        string        `json:"item_id"`
+	BuildType     string        `json:"build_type"`
+	Profile       string        `json:"profile"`
+	TraderLevels  []TraderLevel `json:"trader_levels"`
+	Status        string        `json:"status"`
+	Priority      int           `json:"priority"`
+	Attempts      int           `json:"attempts"`
+	FailureReason string        `json:"failure_reason,omitempty"`
+	RequestedAt   *time.Time    `json:"requested_at,omitempty"`
+	// CompletedAt is set once the build is Completed
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	// QueuePosition is the number of Pending builds which will be claimed before this one, only set while Pending
+	QueuePosition *int `json:"queue_position,omitempty"`
+}
+
+// RequestBuild queues the build for the given weapon and constraints with PriorityOnDemand, creating it if it
+// doesn't exist yet, and returns the ID of its job. Requesting a build which is already queued raises its priority.
+// A Failed build is moved back to Pending once retry's backoff for its attempts has passed, even if it has run out of
+// retries, and is left Failed before then. Its attempts are kept, so a build which keeps failing is requeued less and
+// less often however often it is requested.
+func RequestBuild(db *sql.DB, itemID string, buildType string, constraints EvaluationConstraints, retry RetryPolicy) (int, error) {
+	buildID, err := CreatePendingOptimumBuild(db, itemID, buildType, constraints)
+	if err != nil {
+		return -1, err
+	}
+
+	// every expression sees the row as it was, so status is only changed once the others are decided
+	requeue := `(obs.status = $3 AND ` + backoffElapsedCondition("$5", "$6") + `)`
+	query := `UPDATE optimal_build_status obs
+		SET priority = GREATEST(obs.priority, $1),
+			requested_at = CASE WHEN ` + requeue + ` THEN now() ELSE COALESCE(obs.requested_at, now()) END,
+			failure_reason = CASE WHEN ` + requeue + ` THEN NULL ELSE obs.failure_reason END,
+			status = CASE WHEN ` + requeue + ` THEN $4 ELSE obs.status END
+		WHERE obs.build_id = $2;`
+	_, err = db.Exec(query, PriorityOnDemand, buildID, EvaluationFailed.ToString(), EvaluationPending.ToString(),
+		retry.Backoff.Seconds(), retry.MaxBackoff.Seconds())
+	if err != nil {
+		return -1, err
+	}
+
+	return buildID, nil
+}
+
+// GetJobById returns the job evaluating buildID, or nil if there is no such build
+func GetJobById(db *sql.DB, buildID int) (*Job, error) {
+	query := `
+		SELECT ob.build_id, ob.item_id, ob.build_type, ob.profile,
+			ob.jaeger_level, ob.prapor_level, ob.peacekeeper_level, ob.mechanic_level, ob.skier_level,
+			obs.status, obs.priority, obs.attempts, COALESCE(obs.failure_reason, ''), obs.requested_at,
+			CASE WHEN obs.status = $2 THEN obs.evaluation_end END,
+			CASE WHEN obs.status = $3 THEN (
+				SELECT COUNT(*)
+				FROM optimal_build_status ahead
+				WHERE ahead.status = $3
+					AND (ahead.priority > obs.priority
+						OR (ahead.priority = obs.priority AND ahead.build_id < obs.build_id))
+			) END
+		FROM optimum_builds ob
+		JOIN optimal_build_status obs ON obs.build_id = ob.build_id
+		WHERE ob.build_id = $1;`
+
+	job := &Job{}
+	var jaeger, prapor, peacekeeper, mechanic, skier int
+	var requestedAt, completedAt sql.NullTime
+	var queuePosition sql.NullInt64
+	err := db.QueryRow(query, buildID, EvaluationCompleted.ToString(), EvaluationPending.ToString()).Scan(
+		&job.ID,
+		&job.ItemID,
+		&job.BuildType,
+		&job.Profile,
+		&jaeger,
+		&prapor,
+		&peacekeeper,
+		&mechanic,
+		&skier,
+		&job.Status,
+		&job.Priority,
+		&job.Attempts,
+		&job.FailureReason,
+		&requestedAt,
+		&completedAt,
+		&queuePosition,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job.TraderLevels = []TraderLevel{
+		{Name: "Jaeger", Level: jaeger},
+		{Name: "Prapor", Level: prapor},
+		{Name: "Peacekeeper", Level: peacekeeper},
+		{Name: "Mechanic", Level: mechanic},
+		{Name: "Skier", Level: skier},
+	}
+	if requestedAt.Valid {
+		job.RequestedAt = &requestedAt.Time
+	}
+	if completedAt.Valid {
+		job.CompletedAt = &completedAt.Time
+	}
+	if queuePosition.Valid {
+		position := int(queuePosition.Int64)
+		job.QueuePosition = &position
+	}
+
+	return job, nil
+}
@@ -0,0 +1,166 @@
+package models_test
+
+import (
+	"testing"
+	"time"
+
+	"tarkov-build-optimiser/internal/db"
+	"tarkov-build-optimiser/internal/env"
+	"tarkov-build-optimiser/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestJobsIntegration verifies requested builds are prioritised and reported as jobs
+func TestJobsIntegration(t *testing.T) {
+	environment, err := env.Get()
+	require.NoError(t, err, "Failed to get environment")
+
+	dbClient, err := db.CreateBuildOptimiserDBClient(environment)
+	require.NoError(t, err, "Failed to connect to database")
+	conn := dbClient.Conn
+
+	require.NoError(t, models.PurgeOptimumBuilds(conn))
+
+	batchID, err := models.CreatePendingOptimumBuild(conn, "mock_weapon", "recoil", mockQueueConstraints(1))
+	require.NoError(t, err)
+	jobID, err := models.RequestBuild(conn, "mock_weapon", "recoil", mockQueueConstraints(4), models.RetryPolicy{})
+	require.NoError(t, err)
+
+	// requesting the same build again returns the same job
+	duplicateID, err := models.RequestBuild(conn, "mock_weapon", "recoil", mockQueueConstraints(4), models.RetryPolicy{})
+	require.NoError(t, err)
+	assert.Equal(t, jobID, duplicateID)
+
+	job, err := models.GetJobById(conn, jobID)
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, models.EvaluationPending.ToString(), job.Status)
+	assert.Equal(t, models.PriorityOnDemand, job.Priority)
+	assert.NotNil(t, job.RequestedAt)
+	require.NotNil(t, job.QueuePosition)
+	assert.Equal(t, 0, *job.QueuePosition)
+
+	batchJob, err := models.GetJobById(conn, batchID)
+	require.NoError(t, err)
+	require.NotNil(t, batchJob.QueuePosition)
+	assert.Equal(t, 1, *batchJob.QueuePosition, "expected the requested build to be ahead of the batch build")
+
+	// the requested build is claimed first even though it was created last
+	claimed, err := models.ClaimPendingBuild(conn, "worker-a", models.RetryPolicy{}, models.BuildFilter{})
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	assert.Equal(t, jobID, claimed.BuildID)
+
+	// only the worker holding the build may save it
+	err = models.SetBuildCompleted(conn, jobID, "worker-b", &models.ItemEvaluationResult{ID: "mock_weapon"}, nil)
+	assert.ErrorIs(t, err, models.ErrBuildNotClaimed)
+
+	require.NoError(t, models.SetBuildCompleted(conn, jobID, "worker-a", &models.ItemEvaluationResult{ID: "mock_weapon"}, nil))
+	job, err = models.GetJobById(conn, jobID)
+	require.NoError(t, err)
+	assert.Equal(t, models.EvaluationCompleted.ToString(), job.Status)
+	assert.NotNil(t, job.CompletedAt)
+	assert.Nil(t, job.QueuePosition)
+
+	missing, err := models.GetJobById(conn, -1)
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+
+	require.NoError(t, models.PurgeOptimumBuilds(conn))
+}
+
+// TestRequestFailedBuildIntegration verifies requesting a build which has run out of retries queues it again once its
+// backoff has passed, and not before
+func TestRequestFailedBuildIntegration(t *testing.T) {
+	environment, err := env.Get()
+	require.NoError(t, err, "Failed to get environment")
+
+	dbClient, err := db.CreateBuildOptimiserDBClient(environment)
+	require.NoError(t, err, "Failed to connect to database")
+	conn := dbClient.Conn
+
+	require.NoError(t, models.PurgeOptimumBuilds(conn))
+
+	retry := models.RetryPolicy{MaxAttempts: 1, Backoff: time.Hour, MaxBackoff: time.Hour}
+
+	buildID, err := models.CreatePendingOptimumBuild(conn, "mock_weapon", "recoil", mockQueueConstraints(4))
+	require.NoError(t, err)
+	claimed, err := models.ClaimPendingBuild(conn, "worker-a", retry, models.BuildFilter{})
+	require.NoError(t, err)
+	require.NotNil(t, claimed)
+	require.NoError(t, models.SetBuildFailed(conn, buildID, "worker-a", evaluatorNoBuild))
+
+	// within the backoff the failure is reported, however often the build is requested
+	for i := 0; i < 3; i++ {
+		jobID, err := models.RequestBuild(conn, "mock_weapon", "recoil", mockQueueConstraints(4), retry)
+		require.NoError(t, err)
+		assert.Equal(t, buildID, jobID)
+	}
+	job, err := models.GetJobById(conn, buildID)
+	require.NoError(t, err)
+	assert.Equal(t, models.EvaluationFailed.ToString(), job.Status)
+	assert.Equal(t, evaluatorNoBuild, job.FailureReason)
+	assert.Equal(t, 1, job.Attempts)
+
+	claimed, err = models.ClaimPendingBuild(conn, "worker-a", retry, models.BuildFilter{})
+	require.NoError(t, err)
+	require.Nil(t, claimed, "expected the build to have run out of attempts")
+
+	// once the backoff has passed a request queues it again, keeping its attempts
+	retry.Backoff, retry.MaxBackoff = time.Millisecond, time.Millisecond
+	time.Sleep(10 * time.Millisecond)
+	_, err = models.RequestBuild(conn, "mock_weapon", "recoil", mockQueueConstraints(4), retry)
+	require.NoError(t, err)
+
+	job, err = models.GetJobById(conn, buildID)
+	require.NoError(t, err)
+	assert.Equal(t, models.EvaluationPending.ToString(), job.Status)
+	assert.Equal(t, 1, job.Attempts)
+	assert.Empty(t, job.FailureReason)
+	assert.Equal(t, models.PriorityOnDemand, job.Priority)
+
+	claimed, err = models.ClaimPendingBuild(conn, "worker-a", retry, models.BuildFilter{})
+	require.NoError(t, err)
+	require.NotNil(t, claimed, "expected the requested build to be claimed again")
+	assert.Equal(t, buildID, claimed.BuildID)
+
+	require.NoError(t, models.PurgeOptimumBuilds(conn))
+}
+
+// evaluatorNoBuild is the reason a build fails when no build satisfies its constraints, which is the same every time
+const evaluatorNoBuild = "no build satisfies the constraints"
+
+// TestRequestedBuildOfAnyTypeIsClaimedIntegration verifies a build requested for another build type and profile is
+// claimed by an evaluator run with the default selection, which only claims by build type and profile when asked to
+func TestRequestedBuildOfAnyTypeIsClaimedIntegration(t *testing.T) {
+	environment, err := env.Get()
+	require.NoError(t, err, "Failed to get environment")
+
+	dbClient, err := db.CreateBuildOptimiserDBClient(environment)
+	require.NoError(t, err, "Failed to connect to database")
+	conn := dbClient.Conn
+
+	require.NoError(t, models.PurgeOptimumBuilds(conn))
+
+	constraints := mockQueueConstraints(4)
+	constraints.Profile = "iron-sights"
+	jobID, err := models.RequestBuild(conn, "mock_weapon", "ergonomics", constraints, models.RetryPolicy{})
+	require.NoError(t, err)
+
+	// what evaluate --watch claimed before, with the defaulted build type and profile
+	selected := models.BuildFilter{BuildType: "recoil", Profile: models.DefaultProfileName}
+	claimed, err := models.ClaimPendingBuild(conn, "worker-a", models.RetryPolicy{}, selected)
+	require.NoError(t, err)
+	assert.Nil(t, claimed)
+
+	claimed, err = models.ClaimPendingBuild(conn, "worker-a", models.RetryPolicy{}, models.BuildFilter{})
+	require.NoError(t, err)
+	require.NotNil(t, claimed, "expected the requested build to be claimed")
+	assert.Equal(t, jobID, claimed.BuildID)
+	assert.Equal(t, "ergonomics", claimed.BuildType)
+	assert.Equal(t, "iron-sights", claimed.Profile)
+
+	require.NoError(t, models.PurgeOptimumBuilds(conn))
+}
@@ -12,9 +12,17 @@ import (
 )
 
 type EvaluationConstraints struct {
+	// Profile is the name of the ConstraintProfile the ignored slots and items came from, builds are keyed by it
+	Profile          string
 	TraderLevels     []TraderLevel
 	IgnoredSlotNames []string
 	IgnoredItemIDs   []string
+	// ExcludedCategories are weapon mod categories, by name or ID, which may not be used
+	ExcludedCategories []string
+	// RequiredCategories are weapon mod categories, by name or ID, which the build must use at least once each
+	RequiredCategories []string
+	// MaxWeight limits the weight of the weapon with its mods, in kg. No limit when 0.
+	MaxWeight float64
 }
 
 type ItemEvaluationResult struct {
@@ -24,11 +32,17 @@ type ItemEvaluationResult struct {
 	Name               string                 `json:"name"`
 	EvaluationType     string                 `json:"evaluation_type"`
 	IsSubtree          bool                   `json:"is_subtree"`
-	RecoilModifier     int                    `json:"recoil_modifier"`
-	ErgonomicsModifier int                    `json:"ergonomics_modifier"`
+	RecoilModifier     Stat                   `json:"recoil_modifier"`
+	ErgonomicsModifier Stat                   `json:"ergonomics_modifier"`
+	Weight             float64                `json:"weight"`
+	AccuracyModifier   float64                `json:"accuracy_modifier"`
 	Slots              []SlotEvaluationResult `json:"slots"`
-	RecoilSum          int                    `json:"recoil_sum"`
-	ErgonomicsSum      int                    `json:"ergonomics_sum"`
+	RecoilSum          Stat                   `json:"recoil_sum"`
+	ErgonomicsSum      Stat                   `json:"ergonomics_sum"`
+	// WeightSum is the weight of the weapon with its mods, in kg. Only set on the weapon.
+	WeightSum float64 `json:"weight_sum"`
+	// AccuracySum is the sum of the mods' accuracy modifiers in percent. Only set on the weapon.
+	AccuracySum float64 `json:"accuracy_sum"`
 }
 
 type SlotEvaluationResult struct {
@@ -56,12 +70,42 @@ func (s *SlotEvaluationResult) MarshalJSON() ([]byte, error) {
 }
 
 type TraderLevel struct {
-	Name  string
-	Level int
+	Name  string `json:"name"`
+	Level int    `json:"level"`
 }
 
 var TraderNames = []string{"Jaeger", "Prapor", "Peacekeeper", "Mechanic", "Skier"}
 
+// IsValidBuildType returns true for the build types builds can be optimised for
+func IsValidBuildType(buildType string) bool {
+	return buildType == "recoil" || buildType == "ergonomics" || buildType == "weight" || buildType == "accuracy"
+}
+
+// CompleteTraderLevels validates levels and returns a level for every trader, in TraderNames order. Traders which
+// aren't listed are at level 4.
+func CompleteTraderLevels(levels []TraderLevel) ([]TraderLevel, error) {
+	byName := make(map[string]int, len(TraderNames))
+	for _, traderName := range TraderNames {
+		byName[traderName] = 4
+	}
+	for _, traderLevel := range levels {
+		if _, ok := byName[traderLevel.Name]; !ok {
+			return nil, fmt.Errorf("Unknown trader %s", traderLevel.Name)
+		}
+		if traderLevel.Level > 4 || traderLevel.Level < 1 {
+			return nil, fmt.Errorf("Invalid level [%d] for trader %s", traderLevel.Level, traderLevel.Name)
+		}
+		byName[traderLevel.Name] = traderLevel.Level
+	}
+
+	complete := make([]TraderLevel, 0, len(TraderNames))
+	for _, traderName := range TraderNames {
+		complete = append(complete, TraderLevel{Name: traderName, Level: byName[traderName]})
+	}
+
+	return complete, nil
+}
+
 func constraintsToTraderMap(constraints EvaluationConstraints) map[string]int {
 	tradersMap := make(map[string]int)
 
@@ -94,6 +138,8 @@ func (s EvaluatorStatus) ToString() string {
 	return [...]string{"Pending", "InProgress", "Completed", "Failed"}[s]
 }
 
+// CreatePendingOptimumBuild creates a Pending build for the given weapon and constraints. It is safe to call
+// concurrently from several evaluator processes - if the build already exists its ID is returned instead.
 func CreatePendingOptimumBuild(db *sql.DB, id string, evaluationType string, constraints EvaluationConstraints) (int, error) {
 	tradersMap := constraintsToTraderMap(constraints)
 
@@ -106,25 +152,41 @@ func CreatePendingOptimumBuild(db *sql.DB, id string, evaluationType string, con
 	query := `INSERT INTO optimum_builds (
 			item_id,
 			build_type,
+			profile,
 			jaeger_level,
 			prapor_level,
 			peacekeeper_level,
 			mechanic_level,
 			skier_level
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (item_id, build_type, profile, jaeger_level, prapor_level, peacekeeper_level, mechanic_level, skier_level) DO NOTHING
 		returning build_id;`
-	var buildID int
-	err = tx.QueryRow(
-		query,
+	args := []interface{}{
 		id,
 		evaluationType,
+		profileName(constraints),
 		tradersMap["Jaeger"],
 		tradersMap["Prapor"],
 		tradersMap["Peacekeeper"],
 		tradersMap["Mechanic"],
 		tradersMap["Skier"],
-	).Scan(&buildID)
+	}
+	var buildID int
+	err = tx.QueryRow(query, args...).Scan(&buildID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// another evaluator created this build first
+		existingQuery := `SELECT build_id FROM optimum_builds
+			WHERE item_id = $1
+				AND build_type = $2
+				AND profile = $3
+				AND jaeger_level = $4
+				AND prapor_level = $5
+				AND peacekeeper_level = $6
+				AND mechanic_level = $7
+				AND skier_level = $8;`
+		err = tx.QueryRow(existingQuery, args...).Scan(&buildID)
+	}
 	if err != nil {
 		return -1, err
 	}
@@ -134,7 +196,8 @@ func CreatePendingOptimumBuild(db *sql.DB, id string, evaluationType string, con
 			status,
 			evaluation_start
 		)
-		VALUES ($1, $2, $3);`
+		VALUES ($1, $2, $3)
+		ON CONFLICT (build_id) DO NOTHING;`
 	_, err = tx.Exec(
 		queryStatus,
 		buildID,
@@ -161,57 +224,102 @@ func SetBuildInProgress(db *sql.DB, buildID int) error {
 	return nil
 }
 
-func SetBuildFailed(db *sql.DB, buildID int) error {
+// SetBuildFailed marks a build claimed by workerID as Failed, recording why and counting the failed attempt.
+// ErrBuildNotClaimed is returned if the worker no longer holds the build.
+func SetBuildFailed(db *sql.DB, buildID int, workerID string, reason string) error {
 	query := `UPDATE optimal_build_status
 		SET status = $1,
-		    evaluation_end = $2
-		WHERE build_id = $3;`
-	_, err := db.Exec(query, EvaluationFailed.ToString(), time.Now(), buildID)
+		    evaluation_end = $2,
+		    failure_reason = $3,
+		    attempts = attempts + 1,
+		    last_error_at = $2,
+		    worker_id = NULL,
+		    heartbeat_at = NULL
+		WHERE build_id = $4
+			AND worker_id = $5
+			AND status = $6;`
+	res, err := db.Exec(query, EvaluationFailed.ToString(), time.Now(), reason, buildID, workerID, EvaluationInProgress.ToString())
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
 	if err != nil {
 		return err
 	}
+	if n == 0 {
+		return ErrBuildNotClaimed
+	}
 
 	return nil
 }
 
-func SetBuildCompleted(db *sql.DB, buildID int, build *ItemEvaluationResult) error {
+// SetBuildCompleted saves the build claimed by workerID with its explanation, which may be nil.
+// ErrBuildNotClaimed is returned, and nothing saved, if the worker no longer holds the build.
+func SetBuildCompleted(db *sql.DB, buildID int, workerID string, build *ItemEvaluationResult, explanation *BuildExplanation) error {
 	serialisedBuild, err := json.Marshal(build)
 	if err != nil {
 		log.Error().Err(err).Msg("Failed to marshal build")
 		return err
 	}
 
+	// stored as null without an explanation
+	var serialisedExplanation sql.NullString
+	if explanation != nil {
+		explanationJSON, err := json.Marshal(explanation)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to marshal build explanation")
+			return err
+		}
+		serialisedExplanation = sql.NullString{String: string(explanationJSON), Valid: true}
+	}
+
 	tx, err := db.Begin()
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
+	// the status is updated first so the row stays locked, and the build is only saved, while the worker holds it
+	queryStatus := `update optimal_build_status set
+			status = $1,
+			evaluation_end = $2
+		where build_id = $3
+			and worker_id = $4
+			and status = $5;`
+	res, err := tx.Exec(
+		queryStatus,
+		EvaluationCompleted.ToString(),
+		time.Now(),
+		buildID,
+		workerID,
+		EvaluationInProgress.ToString())
+	if err != nil {
+		return err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrBuildNotClaimed
+	}
+
 	queryBuild := `update optimum_builds set
 			build = $1,
 			is_subtree = $2,
             recoil_sum = $3,
-			ergonomics_sum = $4
-		where build_id = $5;`
+			ergonomics_sum = $4,
+			explanation = $5
+		where build_id = $6;`
 	_, err = tx.Exec(
 		queryBuild,
 		serialisedBuild,
 		build.IsSubtree,
 		build.RecoilSum,
 		build.ErgonomicsSum,
-		buildID)
-	if err != nil {
-		return err
-	}
-
-	queryStatus := `update optimal_build_status set
-			status = $1,
-			evaluation_end = $2
-		where build_id = $3;`
-	_, err = tx.Exec(
-		queryStatus,
-		EvaluationCompleted.ToString(),
-		time.Now(),
+		serialisedExplanation,
 		buildID)
 	if err != nil {
 		return err
@@ -229,16 +337,18 @@ func GetEvaluatedSubtree(ctx context.Context, db *sql.DB, itemId string, buildTy
 		FROM optimum_builds
 		where item_id = $1
 			and build_type = $2
-			and jaeger_level = $3
-			and prapor_level = $4
-			and peacekeeper_level = $5
-			and mechanic_level = $6
-			and skier_level = $7;`
+			and profile = $3
+			and jaeger_level = $4
+			and prapor_level = $5
+			and peacekeeper_level = $6
+			and mechanic_level = $7
+			and skier_level = $8;`
 	rows, err := db.QueryContext(
 		ctx,
 		query,
 		itemId,
 		buildType,
+		profileName(constraints),
 		tradersMap["Jaeger"],
 		tradersMap["Prapor"],
 		tradersMap["Peacekeeper"],
@@ -291,15 +401,17 @@ func GetOptimumBuildByConstraints(db *sql.DB, itemId string, buildType string, c
 		WHERE
 		    ob.item_id = $1
 			AND ob.build_type = $2
-			AND ob.jaeger_level = $3
-			AND ob.prapor_level = $4
-			AND ob.peacekeeper_level = $5
-			AND ob.mechanic_level = $6
-			AND ob.skier_level = $7;`
+			AND ob.profile = $3
+			AND ob.jaeger_level = $4
+			AND ob.prapor_level = $5
+			AND ob.peacekeeper_level = $6
+			AND ob.mechanic_level = $7
+			AND ob.skier_level = $8;`
 	rows, err := db.Query(
 		query,
 		itemId,
 		buildType,
+		profileName(constraints),
 		tradersMap["Jaeger"],
 		tradersMap["Prapor"],
 		tradersMap["Peacekeeper"],
@@ -346,15 +458,18 @@ func GetOptimumBuildByConstraints(db *sql.DB, itemId string, buildType string, c
 	return &results[0], nil
 }
 
-func ResetInProgressBuilds(db *sql.DB) error {
-	query := `UPDATE optimal_build_status
-		SET status = $1
-		WHERE status = $2;`
-	_, err := db.Exec(query, EvaluationPending.ToString(), EvaluationInProgress.ToString())
-	return err
-}
-
 func PurgeOptimumBuilds(db *sql.DB) error {
 	_, err := db.Exec("TRUNCATE optimum_builds CASCADE;")
 	return err
 }
+
+// PurgeOptimumBuildsByFilter deletes builds matching filter along with their status, returning the number deleted
+func PurgeOptimumBuildsByFilter(db *sql.DB, filter BuildFilter) (int64, error) {
+	filterConditions, args := filter.conditions(nil)
+	res, err := db.Exec(`DELETE FROM optimum_builds ob WHERE TRUE`+filterConditions+`;`, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompleteTraderLevels(t *testing.T) {
+	levels, err := CompleteTraderLevels([]TraderLevel{{Name: "Prapor", Level: 2}})
+	require.NoError(t, err)
+	assert.Equal(t, []TraderLevel{
+		{Name: "Jaeger", Level: 4},
+		{Name: "Prapor", Level: 2},
+		{Name: "Peacekeeper", Level: 4},
+		{Name: "Mechanic", Level: 4},
+		{Name: "Skier", Level: 4},
+	}, levels)
+
+	_, err = CompleteTraderLevels([]TraderLevel{{Name: "Ragman", Level: 2}})
+	assert.Error(t, err)
+
+	_, err = CompleteTraderLevels([]TraderLevel{{Name: "Skier", Level: 5}})
+	assert.Error(t, err)
+}
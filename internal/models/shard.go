@@ -0,0 +1,65 @@
+package models
+
+import (
+	"crypto/md5"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Shard is one of Count deterministic slices of the (weapon, trader levels) space, numbered from 0
+type Shard struct {
+	Index int
+	Count int
+}
+
+// IsSet reports whether work is split into shards at all
+func (s Shard) IsSet() bool {
+	return s.Count > 0
+}
+
+// Contains reports whether a build with the given shard key belongs to this shard
+func (s Shard) Contains(shardKey int64) bool {
+	return !s.IsSet() || shardKey%int64(s.Count) == int64(s.Index)
+}
+
+func (s Shard) String() string {
+	return fmt.Sprintf("%d/%d", s.Index, s.Count)
+}
+
+// ParseShard parses a shard written as index/count, e.g. 0/4
+func ParseShard(value string) (Shard, error) {
+	index, count, ok := strings.Cut(value, "/")
+	if !ok {
+		return Shard{}, fmt.Errorf("invalid shard %q, expected index/count e.g. 0/4", value)
+	}
+
+	s := Shard{}
+	var err error
+	s.Index, err = strconv.Atoi(index)
+	if err != nil {
+		return Shard{}, fmt.Errorf("invalid shard index %q", index)
+	}
+	s.Count, err = strconv.Atoi(count)
+	if err != nil {
+		return Shard{}, fmt.Errorf("invalid shard count %q", count)
+	}
+
+	if s.Count < 1 {
+		return Shard{}, errors.New("shard count must be at least 1")
+	}
+	if s.Index < 0 || s.Index >= s.Count {
+		return Shard{}, fmt.Errorf("shard index must be between 0 and %d", s.Count-1)
+	}
+
+	return s, nil
+}
+
+// ShardKey hashes a build's identity so builds spread evenly across shards. It matches the generated
+// optimum_builds.shard_key column: the first 32 bits of the md5 of item:buildType:levels.
+func ShardKey(itemID string, buildType string, levels []TraderLevel) int64 {
+	sum := md5.Sum([]byte(itemID + ":" + buildType + ":" + traderLevelsKey(levels)))
+	return int64(binary.BigEndian.Uint32(sum[:4]))
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseShard(t *testing.T) {
+	s, err := ParseShard("2/4")
+	require.NoError(t, err)
+	assert.Equal(t, Shard{Index: 2, Count: 4}, s)
+	assert.Equal(t, "2/4", s.String())
+
+	for _, invalid := range []string{"4/4", "-1/4", "1/0", "1", "a/4", "1/b"} {
+		_, err := ParseShard(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestShardKey(t *testing.T) {
+	levels := []TraderLevel{
+		{Name: "Jaeger", Level: 4},
+		{Name: "Prapor", Level: 4},
+		{Name: "Peacekeeper", Level: 2},
+		{Name: "Mechanic", Level: 3},
+		{Name: "Skier", Level: 1},
+	}
+
+	// md5("5447a9cd4bdc2dbd208b4567:recoil:4,4,2,3,1") starts with 6491b815
+	key := ShardKey("5447a9cd4bdc2dbd208b4567", "recoil", levels)
+	assert.Equal(t, int64(0x6491b815), key)
+	assert.NotEqual(t, key, ShardKey("5447a9cd4bdc2dbd208b4567", "ergonomics", levels))
+}
+
+func TestShardKey_SpreadsAcrossShards(t *testing.T) {
+	shards := 4
+	counts := make([]int, shards)
+	for j := 1; j <= 4; j++ {
+		for p := 1; p <= 4; p++ {
+			for pk := 1; pk <= 4; pk++ {
+				levels := []TraderLevel{
+					{Name: "Jaeger", Level: j},
+					{Name: "Prapor", Level: p},
+					{Name: "Peacekeeper", Level: pk},
+					{Name: "Mechanic", Level: 4},
+					{Name: "Skier", Level: 4},
+				}
+				counts[ShardKey("weapon", "recoil", levels)%int64(shards)]++
+			}
+		}
+	}
+
+	for i, c := range counts {
+		// 64 builds over 4 shards, none should be badly starved or overloaded
+		assert.InDelta(t, 16, c, 10, "shard %d", i)
+	}
+}
+
+func TestShard_Contains(t *testing.T) {
+	assert.True(t, Shard{}.Contains(7), "an unset shard contains everything")
+	assert.True(t, Shard{Index: 3, Count: 4}.Contains(7))
+	assert.False(t, Shard{Index: 0, Count: 4}.Contains(7))
+}
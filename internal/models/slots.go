@@ -6,19 +6,23 @@ type Slot struct {
 	ID           string        `json:"slot_id"`
 	Name         string        `json:"name"`
 	AllowedItems []AllowedItem `json:"allowed_items"`
+	// Required slots must be filled for the item to be usable in game, e.g. a weapon's barrel
+	Required bool `json:"required"`
 }
 
 func upsertSlot(tx *sql.Tx, itemID string, slot Slot) error {
 	query := `INSERT INTO slots (
 			slot_id,
 			item_id,
-			name
+			name,
+			required
 		)
-		VALUES ($1, $2, $3)
+		VALUES ($1, $2, $3, $4)
 		ON CONFLICT (slot_id) DO UPDATE SET
-			name = $3
+			name = $3,
+			required = $4
 		;`
-	_, err := tx.Exec(query, slot.ID, itemID, slot.Name)
+	_, err := tx.Exec(query, slot.ID, itemID, slot.Name, slot.Required)
 	if err != nil {
 		return err
 	}
@@ -44,7 +48,7 @@ func upsertManySlot(tx *sql.Tx, itemID string, slots []Slot) error {
 }
 
 func GetSlotsByItemID(db *sql.DB, itemID string) ([]Slot, error) {
-	rows, err := db.Query(`select slot_id, name from slots where item_id = $1`, itemID)
+	rows, err := db.Query(`select slot_id, name, required from slots where item_id = $1`, itemID)
 	if err != nil {
 		return nil, err
 	}
@@ -53,7 +57,7 @@ func GetSlotsByItemID(db *sql.DB, itemID string) ([]Slot, error) {
 	slots := make([]Slot, 0)
 	for rows.Next() {
 		slot := Slot{}
-		err := rows.Scan(&slot.ID, &slot.Name)
+		err := rows.Scan(&slot.ID, &slot.Name, &slot.Required)
 		if err != nil {
 			return nil, err
 		}
@@ -64,7 +68,7 @@ func GetSlotsByItemID(db *sql.DB, itemID string) ([]Slot, error) {
 }
 
 func GetAllSlots(db *sql.DB) (map[string][]Slot, error) {
-	rows, err := db.Query(`select slot_id, name, item_id from slots`)
+	rows, err := db.Query(`select slot_id, name, required, item_id from slots`)
 	if err != nil {
 		return nil, err
 	}
@@ -74,7 +78,7 @@ func GetAllSlots(db *sql.DB) (map[string][]Slot, error) {
 	slotsByItemID := make(map[string][]Slot)
 	for rows.Next() {
 		slot := Slot{}
-		err := rows.Scan(&slot.ID, &slot.Name, &itemID)
+		err := rows.Scan(&slot.ID, &slot.Name, &slot.Required, &itemID)
 		if err != nil {
 			return nil, err
 		}
@@ -0,0 +1,87 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// StatScale is the number of Stat units in one point of a stat
+const StatScale = 1000
+
+// Stat is a recoil or ergonomics modifier, or a sum of them, in fixed-point thousandths of a point. Fractional
+// modifiers such as -2.5 are kept exactly and sums don't depend on the order they were added in, so builds which tie
+// in game also tie here. Stats are written to JSON and the DB as decimal points, e.g. -2.5.
+type Stat int64
+
+// NewStat returns the Stat nearest to value, in points
+func NewStat(value float64) Stat {
+	return Stat(math.Round(value * StatScale))
+}
+
+// ParseStat parses a decimal number of points
+func ParseStat(s string) (Stat, error) {
+	value, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid stat %q: %w", s, err)
+	}
+	return NewStat(value), nil
+}
+
+// Points returns the stat in points
+func (s Stat) Points() float64 {
+	return float64(s) / StatScale
+}
+
+func (s Stat) String() string {
+	return strconv.FormatFloat(s.Points(), 'f', -1, 64)
+}
+
+func (s Stat) MarshalJSON() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+func (s *Stat) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		*s = 0
+		return nil
+	}
+	parsed, err := ParseStat(string(data))
+	if err != nil {
+		return err
+	}
+	*s = parsed
+	return nil
+}
+
+// Value writes the stat as a decimal so NUMERIC columns store it exactly
+func (s Stat) Value() (driver.Value, error) {
+	return s.String(), nil
+}
+
+func (s *Stat) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*s = 0
+	case int64:
+		*s = Stat(v * StatScale)
+	case float64:
+		*s = NewStat(v)
+	case []byte:
+		parsed, err := ParseStat(string(v))
+		if err != nil {
+			return err
+		}
+		*s = parsed
+	case string:
+		parsed, err := ParseStat(v)
+		if err != nil {
+			return err
+		}
+		*s = parsed
+	default:
+		return fmt.Errorf("can't scan %T into a stat", src)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package models
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewStat(t *testing.T) {
+	assert.Equal(t, Stat(-2500), NewStat(-2.5))
+	assert.Equal(t, Stat(1500), NewStat(1.5))
+	assert.Equal(t, Stat(-30000), NewStat(-30))
+	// float noise from the API rounds to the nearest thousandth
+	assert.Equal(t, Stat(-7000), NewStat(-6.9999999999))
+	assert.Equal(t, -2.5, NewStat(-2.5).Points())
+}
+
+func TestStat_SumsAreExact(t *testing.T) {
+	// 0.1 + 0.2 != 0.3 as floats, but is as stats
+	assert.Equal(t, NewStat(0.3), NewStat(0.1)+NewStat(0.2))
+	// int(-2.5) + int(-2.5) was -4, a -5 mod beat two -2.5 mods when they tie in game
+	assert.Equal(t, NewStat(-5), NewStat(-2.5)+NewStat(-2.5))
+}
+
+func TestStat_JSON(t *testing.T) {
+	out, err := json.Marshal(struct {
+		Recoil Stat `json:"recoil"`
+		Ergo   Stat `json:"ergo"`
+	}{NewStat(-2.5), NewStat(12)})
+	require.NoError(t, err)
+	assert.Equal(t, `{"recoil":-2.5,"ergo":12}`, string(out))
+
+	// builds and file caches written before stats were fixed-point hold whole numbers
+	var in struct {
+		Recoil Stat `json:"recoil"`
+		Ergo   Stat `json:"ergo"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(`{"recoil":-3,"ergo":1.25}`), &in))
+	assert.Equal(t, NewStat(-3), in.Recoil)
+	assert.Equal(t, NewStat(1.25), in.Ergo)
+}
+
+func TestStat_Scan(t *testing.T) {
+	var s Stat
+	require.NoError(t, s.Scan([]byte("-2.500")))
+	assert.Equal(t, NewStat(-2.5), s)
+	require.NoError(t, s.Scan(int64(-4)))
+	assert.Equal(t, NewStat(-4), s)
+	require.NoError(t, s.Scan(nil))
+	assert.Equal(t, Stat(0), s)
+	assert.Error(t, s.Scan(true))
+
+	value, err := NewStat(-2.5).Value()
+	require.NoError(t, err)
+	assert.Equal(t, "-2.5", value)
+}
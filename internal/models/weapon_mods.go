@@ -9,12 +9,18 @@ import (
 type WeaponMod struct {
 	ID                 string   `json:"item_id"`
 	Name               string   `json:"name"`
-	ErgonomicsModifier int      `json:"ergonomics_modifier"`
-	RecoilModifier     int      `json:"recoil_modifier"`
+	ErgonomicsModifier Stat     `json:"ergonomics_modifier"`
+	RecoilModifier     Stat     `json:"recoil_modifier"`
+	Weight             float64  `json:"weight"`
+	AccuracyModifier   float64  `json:"accuracy_modifier"`
 	CategoryName       string   `json:"category_name"`
 	CategoryID         string   `json:"category_id"`
 	Slots              []Slot   `json:"slots"`
 	ConflictingItems   []string `json:"conflicting_items"`
+	// CenterOfImpact and deviation are only set on barrels
+	CenterOfImpact float64 `json:"center_of_impact"`
+	DeviationCurve float64 `json:"deviation_curve"`
+	DeviationMax   float64 `json:"deviation_max"`
 }
 
 func UpsertMod(tx *sql.Tx, mod WeaponMod) error {
@@ -24,15 +30,26 @@ func UpsertMod(tx *sql.Tx, mod WeaponMod) error {
                                  ergonomics_modifier,
                                  recoil_modifier,
                                  category_name,
-                                 category_id)
-        values ($1, $2, $3, $4, $5, $6)
+                                 category_id,
+                                 weight,
+                                 accuracy_modifier,
+                                 center_of_impact,
+                                 deviation_curve,
+                                 deviation_max)
+        values ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
         on conflict (item_id) do update set name                = $2,
                                             ergonomics_modifier = $3,
                                             recoil_modifier     = $4,
                                             category_name       = $5,
-                                            category_id         = $6;`
-
-	_, err := tx.Exec(query, mod.ID, mod.Name, mod.ErgonomicsModifier, mod.RecoilModifier, mod.CategoryName, mod.CategoryID)
+                                            category_id         = $6,
+                                            weight              = $7,
+                                            accuracy_modifier   = $8,
+                                            center_of_impact    = $9,
+                                            deviation_curve     = $10,
+                                            deviation_max       = $11;`
+
+	_, err := tx.Exec(query, mod.ID, mod.Name, mod.ErgonomicsModifier, mod.RecoilModifier, mod.CategoryName, mod.CategoryID, mod.Weight,
+		mod.AccuracyModifier, mod.CenterOfImpact, mod.DeviationCurve, mod.DeviationMax)
 	if err != nil {
 		return err
 	}
@@ -68,6 +85,13 @@ func GetAllWeaponMods(db *sql.DB) ([]*WeaponMod, error) {
 			   wm.name,
 			   wm.ergonomics_modifier,
 			   wm.recoil_modifier,
+			   wm.weight,
+			   wm.accuracy_modifier,
+			   wm.center_of_impact,
+			   wm.deviation_curve,
+			   wm.deviation_max,
+			   COALESCE(wm.category_id, '')   AS category_id,
+			   COALESCE(wm.category_name, '') AS category_name,
 			   COALESCE(array_agg(ci.conflicting_item_id) FILTER (WHERE ci.conflicting_item_id IS NOT NULL),
 						'{}') AS conflicting_items
 		from weapon_mods wm
@@ -81,7 +105,7 @@ func GetAllWeaponMods(db *sql.DB) ([]*WeaponMod, error) {
 	weaponMods := make([]*WeaponMod, 0)
 	for rows.Next() {
 		weaponMod := &WeaponMod{}
-		err := rows.Scan(&weaponMod.ID, &weaponMod.Name, &weaponMod.ErgonomicsModifier, &weaponMod.RecoilModifier, pq.Array(&weaponMod.ConflictingItems))
+		err := rows.Scan(&weaponMod.ID, &weaponMod.Name, &weaponMod.ErgonomicsModifier, &weaponMod.RecoilModifier, &weaponMod.Weight, &weaponMod.AccuracyModifier, &weaponMod.CenterOfImpact, &weaponMod.DeviationCurve, &weaponMod.DeviationMax, &weaponMod.CategoryID, &weaponMod.CategoryName, pq.Array(&weaponMod.ConflictingItems))
 		if err != nil {
 			return nil, err
 		}
@@ -98,6 +122,11 @@ func GetWeaponModById(db *sql.DB, id string) (*WeaponMod, error) {
                wm.name,
                wm.ergonomics_modifier,
                wm.recoil_modifier,
+               wm.weight,
+               wm.accuracy_modifier,
+               wm.center_of_impact,
+               wm.deviation_curve,
+               wm.deviation_max,
                wm.category_id,
                wm.category_name,
                COALESCE(array_agg(ci.conflicting_item_id) FILTER (WHERE ci.conflicting_item_id IS NOT NULL),
@@ -114,7 +143,7 @@ func GetWeaponModById(db *sql.DB, id string) (*WeaponMod, error) {
 
 	weaponMod := &WeaponMod{}
 	for rows.Next() {
-		err := rows.Scan(&weaponMod.ID, &weaponMod.Name, &weaponMod.ErgonomicsModifier, &weaponMod.RecoilModifier, &weaponMod.CategoryID, &weaponMod.CategoryName, pq.Array(&weaponMod.ConflictingItems))
+		err := rows.Scan(&weaponMod.ID, &weaponMod.Name, &weaponMod.ErgonomicsModifier, &weaponMod.RecoilModifier, &weaponMod.Weight, &weaponMod.AccuracyModifier, &weaponMod.CenterOfImpact, &weaponMod.DeviationCurve, &weaponMod.DeviationMax, &weaponMod.CategoryID, &weaponMod.CategoryName, pq.Array(&weaponMod.ConflictingItems))
 		if err != nil {
 			return nil, err
 		}
@@ -9,11 +9,20 @@ import (
 )
 
 type Weapon struct {
-	ID                 string `json:"item_id"`
-	Name               string `json:"name"`
-	ErgonomicsModifier int    `json:"ergonomics_modifier"`
-	RecoilModifier     int    `json:"recoil_modifier"`
-	Slots              []Slot `json:"slots"`
+	ID                 string  `json:"item_id"`
+	Name               string  `json:"name"`
+	ErgonomicsModifier Stat    `json:"ergonomics_modifier"`
+	RecoilModifier     Stat    `json:"recoil_modifier"`
+	Weight             float64 `json:"weight"`
+	// CenterOfImpact and deviation are the weapon's own accuracy, a fitted barrel's replace them
+	CenterOfImpact float64 `json:"center_of_impact"`
+	DeviationCurve float64 `json:"deviation_curve"`
+	DeviationMax   float64 `json:"deviation_max"`
+	Slots          []Slot  `json:"slots"`
+	// RecoilVertical, RecoilHorizontal and Ergonomics are the bare weapon's, before any mods
+	RecoilVertical   int     `json:"recoil_vertical"`
+	RecoilHorizontal int     `json:"recoil_horizontal"`
+	Ergonomics       float64 `json:"ergonomics"`
 }
 
 type WeaponShort struct {
@@ -26,14 +35,30 @@ func UpsertWeapon(tx *sql.Tx, weapon Weapon) error {
 			item_id,
 			name,
 			recoil_modifier,
-			ergonomics_modifier
+			ergonomics_modifier,
+			weight,
+			center_of_impact,
+			deviation_curve,
+			deviation_max,
+			recoil_vertical,
+			recoil_horizontal,
+			ergonomics
 		)
-		VALUES ($1, $2, $3, $4)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		ON CONFLICT (item_id) DO UPDATE SET
 			name = $2,
 			recoil_modifier = $3,
-			ergonomics_modifier = $4;`
-	_, err := tx.Exec(query, weapon.ID, weapon.Name, weapon.RecoilModifier, weapon.ErgonomicsModifier)
+			ergonomics_modifier = $4,
+			weight = $5,
+			center_of_impact = $6,
+			deviation_curve = $7,
+			deviation_max = $8,
+			recoil_vertical = $9,
+			recoil_horizontal = $10,
+			ergonomics = $11;`
+	_, err := tx.Exec(query, weapon.ID, weapon.Name, weapon.RecoilModifier, weapon.ErgonomicsModifier, weapon.Weight,
+		weapon.CenterOfImpact, weapon.DeviationCurve, weapon.DeviationMax, weapon.RecoilVertical, weapon.RecoilHorizontal,
+		weapon.Ergonomics)
 	if err != nil {
 		return err
 	}
@@ -76,7 +101,14 @@ func GetWeaponById(db *sql.DB, id string) (*Weapon, error) {
 					w.item_id             as id,
 					w.recoil_modifier     as recoil_modifier,
 					w.ergonomics_modifier as ergonomics_modifier,
-					jsonb_agg(jsonb_build_object('slot_id', ws.slot_id, 'name', ws.name, 'allowed_items', (
+					w.weight              as weight,
+					w.center_of_impact    as center_of_impact,
+					w.deviation_curve     as deviation_curve,
+					w.deviation_max       as deviation_max,
+					w.recoil_vertical     as recoil_vertical,
+					w.recoil_horizontal   as recoil_horizontal,
+					w.ergonomics          as ergonomics,
+					jsonb_agg(jsonb_build_object('slot_id', ws.slot_id, 'name', ws.name, 'required', ws.required, 'allowed_items', (
 							select jsonb_agg(jsonb_build_object('item_id', sai.item_id, 'name', sai.name))
 							from slot_allowed_items sai
 							where sai.slot_id = ws.slot_id
@@ -84,7 +116,8 @@ func GetWeaponById(db *sql.DB, id string) (*Weapon, error) {
 		from weapons w
 						join slots ws on w.item_id = ws.item_id
 		where w.item_id = $1
-		group by w.name, w.item_id, w.recoil_modifier, w.ergonomics_modifier;`
+		group by w.name, w.item_id, w.recoil_modifier, w.ergonomics_modifier, w.weight, w.center_of_impact,
+						 w.deviation_curve, w.deviation_max, w.recoil_vertical, w.recoil_horizontal, w.ergonomics;`
 
 	rows, err := db.Query(query, id)
 
@@ -98,7 +131,7 @@ func GetWeaponById(db *sql.DB, id string) (*Weapon, error) {
 	for rows.Next() {
 		weapon := &Weapon{}
 		var slotsStr string
-		err := rows.Scan(&weapon.Name, &weapon.ID, &weapon.RecoilModifier, &weapon.ErgonomicsModifier, &slotsStr)
+		err := rows.Scan(&weapon.Name, &weapon.ID, &weapon.RecoilModifier, &weapon.ErgonomicsModifier, &weapon.Weight, &weapon.CenterOfImpact, &weapon.DeviationCurve, &weapon.DeviationMax, &weapon.RecoilVertical, &weapon.RecoilHorizontal, &weapon.Ergonomics, &slotsStr)
 		if err != nil {
 			return nil, err
 		}
@@ -17,6 +17,9 @@ func createMockWeapon(tx *sql.Tx) models.Weapon {
 		Name:               "M4A1",
 		ErgonomicsModifier: 12,
 		RecoilModifier:     50,
+		RecoilVertical:     56,
+		RecoilHorizontal:   220,
+		Ergonomics:         50,
 		Slots: []models.Slot{
 			{ID: "mock_slot_1", Name: "Muzzle"},
 			{ID: "mock_slot_2", Name: "Stock"},
@@ -74,10 +77,17 @@ func TestCreateAndGetWeapon(t *testing.T) {
 		t.Errorf("TestGetWeapon failed: expected %s, got %s", createdWeapon.Name, weapon.Name)
 	}
 	if weapon.ErgonomicsModifier != createdWeapon.ErgonomicsModifier {
-		t.Errorf("TestGetWeapon failed: expected %d, got %d", createdWeapon.ErgonomicsModifier, weapon.ErgonomicsModifier)
+		t.Errorf("TestGetWeapon failed: expected %s, got %s", createdWeapon.ErgonomicsModifier, weapon.ErgonomicsModifier)
 	}
 	if weapon.RecoilModifier != createdWeapon.RecoilModifier {
-		t.Errorf("TestGetWeapon failed: expected %d, got %d", createdWeapon.RecoilModifier, weapon.RecoilModifier)
+		t.Errorf("TestGetWeapon failed: expected %s, got %s", createdWeapon.RecoilModifier, weapon.RecoilModifier)
+	}
+	if weapon.RecoilVertical != createdWeapon.RecoilVertical || weapon.RecoilHorizontal != createdWeapon.RecoilHorizontal {
+		t.Errorf("TestGetWeapon failed: expected recoil %d/%d, got %d/%d", createdWeapon.RecoilVertical,
+			createdWeapon.RecoilHorizontal, weapon.RecoilVertical, weapon.RecoilHorizontal)
+	}
+	if weapon.Ergonomics != createdWeapon.Ergonomics {
+		t.Errorf("TestGetWeapon failed: expected ergonomics %g, got %g", createdWeapon.Ergonomics, weapon.Ergonomics)
 	}
 	if weapon.Slots == nil {
 		t.Errorf("TestGetWeapon failed: expected slots to be populated")
@@ -86,9 +96,9 @@ func TestCreateAndGetWeapon(t *testing.T) {
 		t.Errorf("TestGetWeapon failed: expected 2 slots, got %d", len(weapon.Slots))
 	}
 	if !helpers.ContainsSlot(weapon.Slots, createdWeapon.Slots[0]) {
-		t.Errorf("TestGetWeapon failed: expected %s, got %s", createdWeapon.Slots[0], weapon.Slots[0])
+		t.Errorf("TestGetWeapon failed: expected %v, got %v", createdWeapon.Slots[0], weapon.Slots[0])
 	}
 	if !helpers.ContainsSlot(weapon.Slots, createdWeapon.Slots[1]) {
-		t.Errorf("TestGetWeapon failed: expected %s, got %s", createdWeapon.Slots[1], weapon.Slots[1])
+		t.Errorf("TestGetWeapon failed: expected %v, got %v", createdWeapon.Slots[1], weapon.Slots[1])
 	}
 }
@@ -0,0 +1,42 @@
+package optimiser
+
+import (
+	"context"
+	"fmt"
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/models"
+)
+
+// CandidateTree creates the weapon's candidate tree for buildType under constraints, pruned as it would be for a
+// search. It shares the optimisation slots, so errors are as for Optimise less evaluator.ErrNoBuild. Creating a tree
+// can't be interrupted, so on timeout the slot is held until the tree is done and then released.
+func (s *Service) CandidateTree(ctx context.Context, itemID string, buildType string, constraints models.EvaluationConstraints) (*candidate_tree.CandidateTree, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	release, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	type created struct {
+		tree *candidate_tree.CandidateTree
+		err  error
+	}
+	done := make(chan created, 1)
+	go func() {
+		defer release()
+		tree, err := candidate_tree.CreateWeaponCandidateTree(itemID, buildType, constraints, s.data)
+		done <- created{tree: tree, err: err}
+	}()
+
+	select {
+	case res := <-done:
+		if res.err != nil {
+			return nil, fmt.Errorf("failed to create candidate tree: %w", res.err)
+		}
+		return res.tree, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,29 @@
+package optimiser
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tarkov-build-optimiser/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_CandidateTree(t *testing.T) {
+	s := NewService(&testData{}, Config{MaxConcurrent: 1, Timeout: time.Second})
+
+	tree, err := s.CandidateTree(context.Background(), "weapon", "recoil", models.EvaluationConstraints{TraderLevels: testMilestones()[2]})
+	require.NoError(t, err)
+	assert.Equal(t, "weapon", tree.Item.ID)
+	assert.Len(t, s.slots, 0, "expected the slot to be released")
+}
+
+func TestService_CandidateTreeRejectsWhenQueueFull(t *testing.T) {
+	s := NewService(&testData{}, Config{MaxConcurrent: 1, MaxQueued: 0, Timeout: time.Second})
+	s.slots <- struct{}{}
+
+	_, err := s.CandidateTree(context.Background(), "weapon", "recoil", models.EvaluationConstraints{})
+	assert.ErrorIs(t, err, ErrBusy)
+}
@@ -0,0 +1,133 @@
+package optimiser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/evaluator"
+	"tarkov-build-optimiser/internal/models"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrBusy is returned when every optimisation slot is taken and the wait queue is full
+var ErrBusy = errors.New("too many optimisations in progress")
+
+const (
+	DefaultTimeout   = 30 * time.Second
+	DefaultMaxQueued = 16
+)
+
+// Config limits how much work the Service does at once
+type Config struct {
+	// MaxConcurrent is the number of optimisations run at the same time
+	MaxConcurrent int
+	// MaxQueued is the number of optimisations allowed to wait for a slot, further requests fail with ErrBusy
+	MaxQueued int
+	// Timeout bounds each optimisation, including the time spent waiting for a slot
+	Timeout time.Duration
+}
+
+// Service finds optimum builds for arbitrary constraints on request, rather than reading precomputed builds
+type Service struct {
+	data   candidate_tree.TreeDataProvider
+	config Config
+	slots  chan struct{}
+
+	mu     sync.Mutex
+	queued int
+}
+
+// NewService creates a Service sharing data between every optimisation. data should be warm,
+// see candidate_tree.DataService.Warm.
+func NewService(data candidate_tree.TreeDataProvider, config Config) *Service {
+	if config.MaxConcurrent < 1 {
+		config.MaxConcurrent = 1
+	}
+	if config.MaxQueued < 0 {
+		config.MaxQueued = 0
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = DefaultTimeout
+	}
+
+	return &Service{
+		data:   data,
+		config: config,
+		slots:  make(chan struct{}, config.MaxConcurrent),
+	}
+}
+
+// Optimise finds the best build of the weapon for buildType under constraints. It returns ErrBusy if the service
+// is at capacity, context.DeadlineExceeded if the search doesn't finish within the configured timeout and
+// evaluator.ErrNoBuild if no build satisfies the constraints.
+func (s *Service) Optimise(ctx context.Context, itemID string, buildType string, constraints models.EvaluationConstraints) (*models.ItemEvaluationResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	release, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	weapon, err := candidate_tree.CreateWeaponCandidateTree(itemID, buildType, constraints, s.data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create candidate tree: %w", err)
+	}
+
+	weapon.SortAllowedItems(candidate_tree.BestFirstSort(buildType))
+
+	// cache entries are keyed by profile name, but a request's ignored slots and items needn't match its profile's,
+	// so entries are only reused within the request, where the constraints don't change
+	start := time.Now()
+	build, err := evaluator.FindBestBuildContext(ctx, weapon, buildType, map[string]bool{}, evaluator.NewMemoryCache())
+	if err != nil {
+		return nil, err
+	}
+	log.Debug().Msgf("Optimised %s for %s in %s, %d items evaluated", itemID, buildType, time.Since(start), build.ItemsEvaluated)
+
+	evaledWeapon, err := build.ToEvaluatedWeapon()
+	if err != nil {
+		return nil, fmt.Errorf("failed to convert build: %w", err)
+	}
+
+	result := evaledWeapon.ToItemEvaluationResult()
+	result.Status = models.EvaluationCompleted.ToString()
+	return &result, nil
+}
+
+// acquire waits for an optimisation slot, returning a function which releases it
+func (s *Service) acquire(ctx context.Context) (func(), error) {
+	release := func() { <-s.slots }
+
+	select {
+	case s.slots <- struct{}{}:
+		return release, nil
+	default:
+	}
+
+	s.mu.Lock()
+	if s.queued >= s.config.MaxQueued {
+		s.mu.Unlock()
+		return nil, ErrBusy
+	}
+	s.queued++
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		s.queued--
+		s.mu.Unlock()
+	}()
+
+	select {
+	case s.slots <- struct{}{}:
+		return release, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
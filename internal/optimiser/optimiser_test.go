@@ -0,0 +1,54 @@
+package optimiser
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"tarkov-build-optimiser/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestService_RejectsWhenQueueFull(t *testing.T) {
+	s := NewService(nil, Config{MaxConcurrent: 1, MaxQueued: 0, Timeout: time.Second})
+	s.slots <- struct{}{}
+
+	_, err := s.Optimise(context.Background(), "weapon", "recoil", models.EvaluationConstraints{})
+	assert.ErrorIs(t, err, ErrBusy)
+}
+
+func TestService_QueuedRequestTimesOut(t *testing.T) {
+	s := NewService(nil, Config{MaxConcurrent: 1, MaxQueued: 1, Timeout: 10 * time.Millisecond})
+	s.slots <- struct{}{}
+
+	_, err := s.Optimise(context.Background(), "weapon", "recoil", models.EvaluationConstraints{})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 0, s.queued)
+}
+
+func TestService_QueuedRequestGetsReleasedSlot(t *testing.T) {
+	s := NewService(nil, Config{MaxConcurrent: 1, MaxQueued: 1, Timeout: time.Second})
+	s.slots <- struct{}{}
+
+	acquired := make(chan error)
+	go func() {
+		release, err := s.acquire(context.Background())
+		if err == nil {
+			release()
+		}
+		acquired <- err
+	}()
+
+	// free the slot once the request is waiting for it
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.queued == 1
+	}, time.Second, time.Millisecond)
+	<-s.slots
+
+	require.NoError(t, <-acquired)
+	assert.Len(t, s.slots, 0)
+}
@@ -0,0 +1,119 @@
+package optimiser
+
+import (
+	"context"
+	"fmt"
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/evaluator"
+	"tarkov-build-optimiser/internal/models"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Purchase is an item bought for an upgrade stage's build
+type Purchase struct {
+	ID       string `json:"item_id"`
+	Name     string `json:"name"`
+	SlotID   string `json:"slot_id"`
+	PriceRub int    `json:"price_rub"`
+}
+
+// UpgradeStage is the build for one trader level milestone
+type UpgradeStage struct {
+	TraderLevels []models.TraderLevel         `json:"trader_levels"`
+	Build        *models.ItemEvaluationResult `json:"build"`
+	// Purchases are the build's items which weren't bought for an earlier stage
+	Purchases       []Purchase `json:"purchases"`
+	PurchaseCostRub int        `json:"purchase_cost_rub"`
+	// ReusedItemIDs are the build's items which were bought for an earlier stage
+	ReusedItemIDs []string `json:"reused_item_ids"`
+}
+
+// UpgradePath is a sequence of builds for a weapon as its traders are levelled, each reusing what was bought before
+type UpgradePath struct {
+	WeaponID     string         `json:"weapon_id"`
+	BuildType    string         `json:"build_type"`
+	Slack        float64        `json:"slack"`
+	Stages       []UpgradeStage `json:"stages"`
+	TotalCostRub int            `json:"total_cost_rub"`
+}
+
+// PlanUpgradePath finds a build of the weapon for each of the milestones' trader levels, in order. Each stage's build
+// is the cheapest to buy given the items bought for earlier stages, among the builds at most slack worse than that
+// stage's best build, see evaluator.FindReuseBuildContext. Items dropped from a build are still counted as owned at
+// later stages. The constraints' trader levels are replaced by each milestone's. Errors are as for Optimise, the
+// timeout covers the whole path.
+func (s *Service) PlanUpgradePath(ctx context.Context, itemID string, buildType string, constraints models.EvaluationConstraints, milestones [][]models.TraderLevel, slack float64) (*UpgradePath, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.config.Timeout)
+	defer cancel()
+
+	release, err := s.acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	path := &UpgradePath{
+		WeaponID:  itemID,
+		BuildType: buildType,
+		Slack:     slack,
+		Stages:    make([]UpgradeStage, 0, len(milestones)),
+	}
+	owned := make(map[string]bool)
+
+	start := time.Now()
+	for _, traderLevels := range milestones {
+		stageConstraints := constraints
+		stageConstraints.TraderLevels = traderLevels
+
+		// pruning keeps the items with the best stats, which may not be the cheapest or those already owned
+		weapon, err := candidate_tree.CreateFullWeaponCandidateTree(itemID, stageConstraints, s.data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create candidate tree: %w", err)
+		}
+
+		weapon.SortAllowedItems(candidate_tree.BestFirstSort(buildType))
+
+		build, err := evaluator.FindReuseBuildContext(ctx, weapon, buildType, owned, slack, map[string]bool{})
+		if err != nil {
+			return nil, err
+		}
+
+		stage := UpgradeStage{
+			TraderLevels:    traderLevels,
+			Purchases:       make([]Purchase, 0),
+			PurchaseCostRub: build.PurchaseCost,
+			ReusedItemIDs:   make([]string, 0),
+		}
+		for _, item := range build.OptimalItems {
+			if owned[item.ID] {
+				stage.ReusedItemIDs = append(stage.ReusedItemIDs, item.ID)
+				continue
+			}
+			stage.Purchases = append(stage.Purchases, Purchase{
+				ID:       item.ID,
+				Name:     item.Name,
+				SlotID:   item.SlotID,
+				PriceRub: weapon.GetAllowedItem(item.ID).PriceRub,
+			})
+		}
+		for _, purchase := range stage.Purchases {
+			owned[purchase.ID] = true
+		}
+
+		evaledWeapon, err := build.ToEvaluatedWeapon()
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert build: %w", err)
+		}
+		result := evaledWeapon.ToItemEvaluationResult()
+		result.Status = models.EvaluationCompleted.ToString()
+		stage.Build = &result
+
+		path.Stages = append(path.Stages, stage)
+		path.TotalCostRub += stage.PurchaseCostRub
+	}
+	log.Debug().Msgf("Planned %d stage upgrade path for %s in %s", len(milestones), itemID, time.Since(start))
+
+	return path, nil
+}
@@ -0,0 +1,120 @@
+package optimiser
+
+import (
+	"context"
+	"fmt"
+	"tarkov-build-optimiser/internal/models"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testData is a weapon with a stock slot and a muzzle slot. Prapor sells the cheap stock at level 1 and the good stock
+// at level 2, Mechanic sells the brake at level 3.
+type testData struct{}
+
+var testSlots = []models.Slot{{ID: "slot-stock", Name: "Stock"}, {ID: "slot-muzzle", Name: "Muzzle"}}
+
+var testMods = map[string]*models.WeaponMod{
+	"cheap-stock": {ID: "cheap-stock", Name: "cheap stock", RecoilModifier: models.NewStat(-5)},
+	"good-stock":  {ID: "good-stock", Name: "good stock", RecoilModifier: models.NewStat(-8)},
+	"brake":       {ID: "brake", Name: "brake", RecoilModifier: models.NewStat(-10)},
+}
+
+func (d *testData) GetWeaponById(id string) (*models.Weapon, error) {
+	if id != "weapon" {
+		return nil, fmt.Errorf("No results for %s", id)
+	}
+	return &models.Weapon{ID: "weapon", Name: "weapon", Slots: testSlots}, nil
+}
+
+func (d *testData) GetSlotsByItemID(id string) ([]models.Slot, error) {
+	if id == "weapon" {
+		return testSlots, nil
+	}
+	return []models.Slot{}, nil
+}
+
+func (d *testData) GetWeaponModById(id string) (*models.WeaponMod, error) {
+	mod, ok := testMods[id]
+	if !ok {
+		return nil, fmt.Errorf("weapon mod with id %s not found", id)
+	}
+	return mod, nil
+}
+
+func (d *testData) GetAllowedItemsBySlotID(id string) ([]*models.AllowedItem, error) {
+	switch id {
+	case "slot-stock":
+		return []*models.AllowedItem{{ID: "cheap-stock", Name: "cheap stock"}, {ID: "good-stock", Name: "good stock"}}, nil
+	case "slot-muzzle":
+		return []*models.AllowedItem{{ID: "brake", Name: "brake"}}, nil
+	}
+	return []*models.AllowedItem{}, nil
+}
+
+func (d *testData) GetTraderOffer(id string) ([]models.TraderOffer, error) {
+	switch id {
+	case "cheap-stock":
+		return []models.TraderOffer{{Trader: "Prapor", MinTraderLevel: 1, PriceRub: 1000}}, nil
+	case "good-stock":
+		return []models.TraderOffer{{Trader: "Prapor", MinTraderLevel: 2, PriceRub: 5000}}, nil
+	case "brake":
+		return []models.TraderOffer{{Trader: "Mechanic", MinTraderLevel: 3, PriceRub: 8000}}, nil
+	}
+	return []models.TraderOffer{}, nil
+}
+
+func (d *testData) IsWeapon(id string) (bool, error) {
+	return id == "weapon", nil
+}
+
+func testMilestones() [][]models.TraderLevel {
+	return [][]models.TraderLevel{
+		{{Name: "Prapor", Level: 1}, {Name: "Mechanic", Level: 1}},
+		{{Name: "Prapor", Level: 2}, {Name: "Mechanic", Level: 1}},
+		{{Name: "Prapor", Level: 2}, {Name: "Mechanic", Level: 3}},
+	}
+}
+
+func purchasedIDs(stage UpgradeStage) []string {
+	ids := make([]string, 0, len(stage.Purchases))
+	for _, purchase := range stage.Purchases {
+		ids = append(ids, purchase.ID)
+	}
+	return ids
+}
+
+func TestService_PlanUpgradePath(t *testing.T) {
+	s := NewService(&testData{}, Config{MaxConcurrent: 1, Timeout: time.Second})
+
+	path, err := s.PlanUpgradePath(context.Background(), "weapon", "recoil", models.EvaluationConstraints{}, testMilestones(), 0)
+	require.NoError(t, err)
+
+	require.Len(t, path.Stages, 3)
+	assert.Equal(t, []string{"cheap-stock"}, purchasedIDs(path.Stages[0]))
+	assert.Equal(t, 1000, path.Stages[0].PurchaseCostRub)
+	assert.Equal(t, []string{"good-stock"}, purchasedIDs(path.Stages[1]))
+	assert.Equal(t, models.NewStat(-8), path.Stages[1].Build.RecoilSum)
+	// the good stock bought at the previous stage is kept
+	assert.Equal(t, []Purchase{{ID: "brake", Name: "brake", SlotID: "slot-muzzle", PriceRub: 8000}}, path.Stages[2].Purchases)
+	assert.Equal(t, []string{"good-stock"}, path.Stages[2].ReusedItemIDs)
+	assert.Equal(t, 14000, path.TotalCostRub)
+}
+
+func TestService_PlanUpgradePath_SlackKeepsOwnedItems(t *testing.T) {
+	s := NewService(&testData{}, Config{MaxConcurrent: 1, Timeout: time.Second})
+
+	// the good stock is only 3 points better, so the cheap stock is kept throughout
+	path, err := s.PlanUpgradePath(context.Background(), "weapon", "recoil", models.EvaluationConstraints{}, testMilestones(), 3)
+	require.NoError(t, err)
+
+	require.Len(t, path.Stages, 3)
+	assert.Empty(t, path.Stages[1].Purchases)
+	assert.Equal(t, []string{"cheap-stock"}, path.Stages[1].ReusedItemIDs)
+	assert.Equal(t, models.NewStat(-5), path.Stages[1].Build.RecoilSum)
+	assert.Equal(t, []string{"brake"}, purchasedIDs(path.Stages[2]))
+	assert.Equal(t, 9000, path.TotalCostRub)
+}
@@ -0,0 +1,161 @@
+package builds_router
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"tarkov-build-optimiser/internal/builds"
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/evaluator"
+	"tarkov-build-optimiser/internal/models"
+	"tarkov-build-optimiser/internal/optimiser"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+// validateRequest is the body of the validate endpoint
+type validateRequest struct {
+	builds.Build
+	// traders which aren't listed default to level 4
+	TraderLevels []models.TraderLevel `json:"trader_levels"`
+}
+
+// compareRequest is the body of the compare endpoint
+type compareRequest struct {
+	validateRequest
+	BuildType string `json:"build_type"`
+	// Profile is the constraint profile of the optimal build, defaults to models.DefaultProfileName
+	Profile string `json:"profile"`
+}
+
+// compareResponse is the gap between the user's build and the optimal build, with the user's build's problems
+type compareResponse struct {
+	*builds.Comparison
+	Errors  []builds.NodeError           `json:"errors"`
+	Optimal *models.ItemEvaluationResult `json:"optimal"`
+}
+
+// getOptimalBuild reads the precomputed optimal build, optimising it on request if it hasn't been evaluated yet
+func getOptimalBuild(ctx context.Context, db *sql.DB, optimiserService *optimiser.Service, itemId string, buildType string, constraints models.EvaluationConstraints) (*models.ItemEvaluationResult, error) {
+	build, err := models.GetOptimumBuildByConstraints(db, itemId, buildType, constraints)
+	if err != nil {
+		return nil, err
+	}
+	if build != nil && build.Status == models.EvaluationCompleted.ToString() {
+		return build, nil
+	}
+
+	return optimiserService.Optimise(ctx, itemId, buildType, constraints)
+}
+
+func Bind(e *echo.Group, db *sql.DB, data candidate_tree.TreeDataProvider, optimiserService *optimiser.Service) *echo.Group {
+	// checks a user's build against the same rules and item data the optimiser builds by
+	e.POST("/validate", func(c echo.Context) error {
+		req := validateRequest{}
+		err := c.Bind(&req)
+		if err != nil {
+			return c.String(400, "Invalid request body")
+		}
+		if req.WeaponID == "" {
+			return c.String(400, "weapon_id is required")
+		}
+
+		traderLevels, err := models.CompleteTraderLevels(req.TraderLevels)
+		if err != nil {
+			return c.String(400, err.Error())
+		}
+
+		isWeapon, err := models.IsWeapon(db, req.WeaponID)
+		if err != nil {
+			return c.String(500, err.Error())
+		}
+		if !isWeapon {
+			return c.String(404, "Weapon not found")
+		}
+
+		validation, err := builds.Validate(data, req.Build, traderLevels)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to validate build of %s", req.WeaponID)
+			return c.String(500, err.Error())
+		}
+
+		return c.JSON(200, validation)
+	})
+
+	// diffs a user's build against the optimal build for the same constraints, slot by slot
+	e.POST("/compare", func(c echo.Context) error {
+		req := compareRequest{}
+		err := c.Bind(&req)
+		if err != nil {
+			return c.String(400, "Invalid request body")
+		}
+		if req.WeaponID == "" {
+			return c.String(400, "weapon_id is required")
+		}
+		if !models.IsValidBuildType(req.BuildType) {
+			return c.String(400, fmt.Sprintf("Invalid build type [%s]", req.BuildType))
+		}
+
+		traderLevels, err := models.CompleteTraderLevels(req.TraderLevels)
+		if err != nil {
+			return c.String(400, err.Error())
+		}
+
+		if req.Profile == "" {
+			req.Profile = models.DefaultProfileName
+		}
+		profile, err := models.GetConstraintProfileByName(db, req.Profile)
+		if err != nil {
+			return c.String(500, err.Error())
+		}
+		if profile == nil {
+			return c.String(400, fmt.Sprintf("Unknown profile [%s]", req.Profile))
+		}
+
+		isWeapon, err := models.IsWeapon(db, req.WeaponID)
+		if err != nil {
+			return c.String(500, err.Error())
+		}
+		if !isWeapon {
+			return c.String(404, "Weapon not found")
+		}
+
+		validation, err := builds.Validate(data, req.Build, traderLevels)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to validate build of %s", req.WeaponID)
+			return c.String(500, err.Error())
+		}
+
+		constraints := profile.Constraints(traderLevels)
+		optimal, err := getOptimalBuild(c.Request().Context(), db, optimiserService, req.WeaponID, req.BuildType, constraints)
+		if errors.Is(err, optimiser.ErrBusy) {
+			c.Response().Header().Set(echo.HeaderRetryAfter, "5")
+			return c.String(503, err.Error())
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return c.String(504, "Optimisation timed out")
+		}
+		if errors.Is(err, evaluator.ErrNoBuild) {
+			return c.String(422, err.Error())
+		}
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to get optimal build. item %s, constraints %v", req.WeaponID, constraints)
+			return c.String(500, err.Error())
+		}
+
+		weapon, err := data.GetWeaponById(req.WeaponID)
+		if err != nil {
+			return c.String(500, err.Error())
+		}
+
+		return c.JSON(200, compareResponse{
+			Comparison: builds.Compare(weapon, &validation.Build, optimal, req.BuildType),
+			Errors:     validation.Errors,
+			Optimal:    optimal,
+		})
+	})
+
+	return e
+}
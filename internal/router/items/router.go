@@ -1,13 +1,21 @@
 package items_router
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"github.com/rs/zerolog/log"
 	"strconv"
 	"strings"
+	"tarkov-build-optimiser/internal/analysis"
+	"tarkov-build-optimiser/internal/candidate_tree"
+	"tarkov-build-optimiser/internal/cli"
+	"tarkov-build-optimiser/internal/evaluator"
+	"tarkov-build-optimiser/internal/jobs"
 	"tarkov-build-optimiser/internal/models"
+	"tarkov-build-optimiser/internal/optimiser"
+	"tarkov-build-optimiser/internal/stats"
 
 	"github.com/labstack/echo/v4"
 )
@@ -43,7 +51,115 @@ func getTraderLevelParams(c echo.Context) ([]models.TraderLevel, error) {
 	return traderLevels, nil
 }
 
-func Bind(e *echo.Group, db *sql.DB) *echo.Group {
+// getProfileParam loads the constraint profile named by the profile query parameter, defaulting to
+// models.DefaultProfileName. Returns nil if there is no such profile.
+func getProfileParam(c echo.Context, db *sql.DB) (*models.ConstraintProfile, error) {
+	name := c.QueryParam("profile")
+	if name == "" {
+		name = models.DefaultProfileName
+	}
+
+	return models.GetConstraintProfileByName(db, name)
+}
+
+// optimiseRequest is the body of the optimise endpoint
+type optimiseRequest struct {
+	BuildType string `json:"build_type"`
+	// Profile is the constraint profile the ignored slots and items are added to, defaults to models.DefaultProfileName
+	Profile string `json:"profile"`
+	// traders which aren't listed default to level 4
+	TraderLevels     []models.TraderLevel `json:"trader_levels"`
+	IgnoredSlotNames []string             `json:"ignored_slot_names"`
+	IgnoredItemIDs   []string             `json:"ignored_item_ids"`
+	// weapon mod category names or IDs, e.g. Silencer
+	ExcludedCategories []string `json:"excluded_categories"`
+	RequiredCategories []string `json:"required_categories"`
+	// MaxWeight in kg, replaces the profile's limit when set
+	MaxWeight float64 `json:"max_weight"`
+}
+
+// toConstraints validates the request and converts it to profile's constraints, extended by the request, with a
+// level for every trader
+func (r optimiseRequest) toConstraints(profile *models.ConstraintProfile) (models.EvaluationConstraints, error) {
+	if !models.IsValidBuildType(r.BuildType) {
+		return models.EvaluationConstraints{}, fmt.Errorf("Invalid build type [%s]", r.BuildType)
+	}
+	if r.MaxWeight < 0 {
+		return models.EvaluationConstraints{}, fmt.Errorf("Invalid max weight [%g]", r.MaxWeight)
+	}
+
+	traderLevels, err := models.CompleteTraderLevels(r.TraderLevels)
+	if err != nil {
+		return models.EvaluationConstraints{}, err
+	}
+
+	constraints := profile.Constraints(traderLevels)
+	constraints.IgnoredSlotNames = append(constraints.IgnoredSlotNames, r.IgnoredSlotNames...)
+	constraints.IgnoredItemIDs = append(constraints.IgnoredItemIDs, r.IgnoredItemIDs...)
+	constraints.ExcludedCategories = append(constraints.ExcludedCategories, r.ExcludedCategories...)
+	constraints.RequiredCategories = append(constraints.RequiredCategories, r.RequiredCategories...)
+	if r.MaxWeight > 0 {
+		constraints.MaxWeight = r.MaxWeight
+	}
+
+	for _, required := range constraints.RequiredCategories {
+		for _, excluded := range constraints.ExcludedCategories {
+			if strings.EqualFold(required, excluded) {
+				return models.EvaluationConstraints{}, fmt.Errorf("Category [%s] can't be both required and excluded", required)
+			}
+		}
+	}
+
+	return constraints, nil
+}
+
+// buildResponse is a build with its stats as shown in game beside the raw modifier sums
+type buildResponse struct {
+	*models.ItemEvaluationResult
+	FinalStats stats.Final `json:"final_stats"`
+	// Explanation is only returned when asked for
+	Explanation *models.BuildExplanation `json:"explanation,omitempty"`
+}
+
+// withFinalStats computes the in-game stats of build from its weapon's base stats
+func withFinalStats(db *sql.DB, build *models.ItemEvaluationResult) (*buildResponse, error) {
+	weapon, err := models.GetWeaponById(db, build.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &buildResponse{ItemEvaluationResult: build, FinalStats: stats.Compute(weapon, build)}, nil
+}
+
+// traderUpgradesRequest is the body of the trader upgrades endpoint for several weapons
+type traderUpgradesRequest struct {
+	WeaponIDs []string `json:"weapon_ids"`
+	BuildType string   `json:"build_type"`
+	// Profile defaults to models.DefaultProfileName
+	Profile string `json:"profile"`
+	// traders which aren't listed default to level 4
+	TraderLevels []models.TraderLevel `json:"trader_levels"`
+}
+
+// requestRetry is the evaluator's default retry policy. A Failed build is only queued again by a request once the
+// evaluator would have retried it, so requests can't keep a build which always fails ahead of the batch run.
+var requestRetry = models.RetryPolicy{Backoff: cli.DefaultRetryBackoff, MaxBackoff: jobs.DefaultMaxRetryBackoff}
+
+// maxUpgradeMilestones bounds the searches made for one upgrade path, each milestone is a full optimisation
+const maxUpgradeMilestones = 8
+
+// upgradePathRequest is the body of the upgrade path endpoint
+type upgradePathRequest struct {
+	BuildType string `json:"build_type"`
+	// Profile defaults to models.DefaultProfileName
+	Profile string `json:"profile"`
+	// Milestones are the trader levels of each stage in order, traders which aren't listed default to level 4
+	Milestones [][]models.TraderLevel `json:"milestones"`
+	// Slack is how much worse than each stage's optimum the build type's stat may be to reuse items, in its units
+	Slack float64 `json:"slack"`
+}
+
+func Bind(e *echo.Group, db *sql.DB, optimiserService *optimiser.Service, data candidate_tree.TreeDataProvider) *echo.Group {
 	e.GET("/weapons", func(c echo.Context) error {
 		res, err := models.GetWeaponsShort(db)
 		if err != nil {
@@ -54,11 +170,6 @@ func Bind(e *echo.Group, db *sql.DB) *echo.Group {
 	})
 
 	e.GET("/weapons/:item_id/calculate", func(c echo.Context) error {
-		constraints := models.EvaluationConstraints{
-			TraderLevels:     []models.TraderLevel{},
-			IgnoredSlotNames: []string{"Scope", "Ubgl", "Tactical", "Mount"},
-		}
-
 		itemId := c.Param("item_id")
 		buildType := c.QueryParam("build_type")
 		traderLevels, err := getTraderLevelParams(c)
@@ -66,7 +177,15 @@ func Bind(e *echo.Group, db *sql.DB) *echo.Group {
 			return c.String(400, err.Error())
 		}
 
-		constraints.TraderLevels = traderLevels
+		profile, err := getProfileParam(c, db)
+		if err != nil {
+			return c.String(500, err.Error())
+		}
+		if profile == nil {
+			return c.String(400, fmt.Sprintf("Unknown profile [%s]", c.QueryParam("profile")))
+		}
+
+		constraints := profile.Constraints(traderLevels)
 
 		build, err := models.GetOptimumBuildByConstraints(db, itemId, buildType, constraints)
 		if err != nil {
@@ -74,11 +193,325 @@ func Bind(e *echo.Group, db *sql.DB) *echo.Group {
 			return c.String(500, err.Error())
 		}
 
-		if build == nil {
-			return c.String(404, "Build not found")
+		if build != nil && build.Status == models.EvaluationCompleted.ToString() {
+			res, err := withFinalStats(db, build)
+			if err != nil {
+				return c.String(500, err.Error())
+			}
+			if c.QueryParam("explain") == "true" {
+				res.Explanation, err = models.GetBuildExplanation(db, build.BuildID)
+				if err != nil {
+					return c.String(500, err.Error())
+				}
+			}
+			return c.JSON(200, res)
+		}
+
+		// the build hasn't been evaluated yet, queue it ahead of the batch run and hand back a job to poll
+		if !models.IsValidBuildType(buildType) {
+			return c.String(400, fmt.Sprintf("Invalid build type [%s]", buildType))
+		}
+
+		isWeapon, err := models.IsWeapon(db, itemId)
+		if err != nil {
+			return c.String(500, err.Error())
+		}
+		if !isWeapon {
+			return c.String(404, "Weapon not found")
+		}
+
+		jobID, err := models.RequestBuild(db, itemId, buildType, constraints, requestRetry)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to request build. item %s, constraints %v", itemId, constraints)
+			return c.String(500, err.Error())
+		}
+
+		job, err := models.GetJobById(db, jobID)
+		if err != nil {
+			return c.String(500, err.Error())
+		}
+
+		c.Response().Header().Set(echo.HeaderLocation, fmt.Sprintf("/api/jobs/%d", jobID))
+		// still waiting out its backoff, the job says why it failed
+		if job.Status == models.EvaluationFailed.ToString() {
+			return c.JSON(422, job)
+		}
+		return c.JSON(202, job)
+	})
+
+	// ranks each single trader level up by how much it improves the weapon's precomputed build
+	e.GET("/weapons/:item_id/trader-upgrades", func(c echo.Context) error {
+		itemId := c.Param("item_id")
+		buildType := c.QueryParam("build_type")
+		if !models.IsValidBuildType(buildType) {
+			return c.String(400, fmt.Sprintf("Invalid build type [%s]", buildType))
+		}
+
+		traderLevels, err := getTraderLevelParams(c)
+		if err != nil {
+			return c.String(400, err.Error())
+		}
+
+		profile, err := getProfileParam(c, db)
+		if err != nil {
+			return c.String(500, err.Error())
+		}
+		if profile == nil {
+			return c.String(400, fmt.Sprintf("Unknown profile [%s]", c.QueryParam("profile")))
+		}
+
+		isWeapon, err := models.IsWeapon(db, itemId)
+		if err != nil {
+			return c.String(500, err.Error())
+		}
+		if !isWeapon {
+			return c.String(404, "Weapon not found")
+		}
+
+		constraints := profile.Constraints(traderLevels)
+		res, err := analysis.RankTraderUpgrades(analysis.NewStoredBuilds(db), data, itemId, buildType, constraints)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to rank trader upgrades. item %s, constraints %v", itemId, constraints)
+			return c.String(500, err.Error())
+		}
+
+		return c.JSON(200, res)
+	})
+
+	// the weapon's candidate tree as the evaluator would search it, as JSON or Graphviz DOT (format=dot)
+	e.GET("/weapons/:item_id/candidate-tree", func(c echo.Context) error {
+		itemId := c.Param("item_id")
+		buildType := c.QueryParam("build_type")
+		if buildType == "" {
+			buildType = "recoil"
+		}
+		if !models.IsValidBuildType(buildType) {
+			return c.String(400, fmt.Sprintf("Invalid build type [%s]", buildType))
+		}
+		format := c.QueryParam("format")
+		if format != "" && format != "json" && format != "dot" {
+			return c.String(400, fmt.Sprintf("Invalid format [%s]", format))
+		}
+
+		traderLevels, err := getTraderLevelParams(c)
+		if err != nil {
+			return c.String(400, err.Error())
+		}
+
+		profile, err := getProfileParam(c, db)
+		if err != nil {
+			return c.String(500, err.Error())
+		}
+		if profile == nil {
+			return c.String(400, fmt.Sprintf("Unknown profile [%s]", c.QueryParam("profile")))
+		}
+
+		isWeapon, err := models.IsWeapon(db, itemId)
+		if err != nil {
+			return c.String(500, err.Error())
+		}
+		if !isWeapon {
+			return c.String(404, "Weapon not found")
+		}
+
+		constraints := profile.Constraints(traderLevels)
+		tree, err := optimiserService.CandidateTree(c.Request().Context(), itemId, buildType, constraints)
+		if errors.Is(err, optimiser.ErrBusy) {
+			c.Response().Header().Set(echo.HeaderRetryAfter, "5")
+			return c.String(503, err.Error())
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return c.String(504, "Candidate tree timed out")
+		}
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to create candidate tree. item %s, constraints %v", itemId, constraints)
+			return c.String(500, err.Error())
+		}
+
+		if format == "dot" {
+			c.Response().Header().Set(echo.HeaderContentType, "text/vnd.graphviz; charset=utf-8")
+			c.Response().WriteHeader(200)
+			return tree.WriteDOT(c.Response())
+		}
+		return c.JSON(200, tree.Export())
+	})
+
+	// ranks each single trader level up by how much it improves several weapons' builds together, e.g. a user's
+	// favourite weapons
+	e.POST("/weapons/trader-upgrades", func(c echo.Context) error {
+		req := traderUpgradesRequest{}
+		err := c.Bind(&req)
+		if err != nil {
+			return c.String(400, "Invalid request body")
+		}
+		if len(req.WeaponIDs) == 0 {
+			return c.String(400, "weapon_ids is required")
+		}
+		if !models.IsValidBuildType(req.BuildType) {
+			return c.String(400, fmt.Sprintf("Invalid build type [%s]", req.BuildType))
+		}
+
+		traderLevels, err := models.CompleteTraderLevels(req.TraderLevels)
+		if err != nil {
+			return c.String(400, err.Error())
+		}
+
+		if req.Profile == "" {
+			req.Profile = models.DefaultProfileName
+		}
+		profile, err := models.GetConstraintProfileByName(db, req.Profile)
+		if err != nil {
+			return c.String(500, err.Error())
+		}
+		if profile == nil {
+			return c.String(400, fmt.Sprintf("Unknown profile [%s]", req.Profile))
+		}
+
+		for _, weaponID := range req.WeaponIDs {
+			isWeapon, err := models.IsWeapon(db, weaponID)
+			if err != nil {
+				return c.String(500, err.Error())
+			}
+			if !isWeapon {
+				return c.String(404, fmt.Sprintf("Weapon %s not found", weaponID))
+			}
+		}
+
+		constraints := profile.Constraints(traderLevels)
+		res, err := analysis.RankTraderUpgradesForWeapons(analysis.NewStoredBuilds(db), data, req.WeaponIDs, req.BuildType, constraints)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to rank trader upgrades. items %v, constraints %v", req.WeaponIDs, constraints)
+			return c.String(500, err.Error())
+		}
+
+		return c.JSON(200, res)
+	})
+
+	// optimises a build for arbitrary constraints on request, unlike calculate which only serves precomputed builds
+	e.POST("/weapons/:item_id/optimise", func(c echo.Context) error {
+		itemId := c.Param("item_id")
+
+		req := optimiseRequest{}
+		err := c.Bind(&req)
+		if err != nil {
+			return c.String(400, "Invalid request body")
+		}
+
+		if req.Profile == "" {
+			req.Profile = models.DefaultProfileName
+		}
+		profile, err := models.GetConstraintProfileByName(db, req.Profile)
+		if err != nil {
+			return c.String(500, err.Error())
+		}
+		if profile == nil {
+			return c.String(400, fmt.Sprintf("Unknown profile [%s]", req.Profile))
+		}
+
+		constraints, err := req.toConstraints(profile)
+		if err != nil {
+			return c.String(400, err.Error())
+		}
+
+		isWeapon, err := models.IsWeapon(db, itemId)
+		if err != nil {
+			return c.String(500, err.Error())
+		}
+		if !isWeapon {
+			return c.String(404, "Weapon not found")
+		}
+
+		build, err := optimiserService.Optimise(c.Request().Context(), itemId, req.BuildType, constraints)
+		if errors.Is(err, optimiser.ErrBusy) {
+			c.Response().Header().Set(echo.HeaderRetryAfter, "5")
+			return c.String(503, err.Error())
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return c.String(504, "Optimisation timed out")
+		}
+		if errors.Is(err, evaluator.ErrNoBuild) {
+			return c.String(422, err.Error())
+		}
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to optimise build. item %s, constraints %v", itemId, constraints)
+			return c.String(500, err.Error())
+		}
+
+		res, err := withFinalStats(db, build)
+		if err != nil {
+			return c.String(500, err.Error())
+		}
+
+		return c.JSON(200, res)
+	})
+
+	// plans a build for each trader level milestone, reusing the items bought for earlier ones where they're close
+	// enough to optimal
+	e.POST("/weapons/:item_id/upgrade-path", func(c echo.Context) error {
+		itemId := c.Param("item_id")
+
+		req := upgradePathRequest{}
+		err := c.Bind(&req)
+		if err != nil {
+			return c.String(400, "Invalid request body")
+		}
+		if !models.IsValidBuildType(req.BuildType) {
+			return c.String(400, fmt.Sprintf("Invalid build type [%s]", req.BuildType))
+		}
+		if len(req.Milestones) == 0 || len(req.Milestones) > maxUpgradeMilestones {
+			return c.String(400, fmt.Sprintf("Between 1 and %d milestones are required", maxUpgradeMilestones))
+		}
+		if req.Slack < 0 {
+			return c.String(400, fmt.Sprintf("Invalid slack [%g]", req.Slack))
+		}
+
+		milestones := make([][]models.TraderLevel, 0, len(req.Milestones))
+		for _, milestone := range req.Milestones {
+			traderLevels, err := models.CompleteTraderLevels(milestone)
+			if err != nil {
+				return c.String(400, err.Error())
+			}
+			milestones = append(milestones, traderLevels)
+		}
+
+		if req.Profile == "" {
+			req.Profile = models.DefaultProfileName
+		}
+		profile, err := models.GetConstraintProfileByName(db, req.Profile)
+		if err != nil {
+			return c.String(500, err.Error())
+		}
+		if profile == nil {
+			return c.String(400, fmt.Sprintf("Unknown profile [%s]", req.Profile))
+		}
+
+		isWeapon, err := models.IsWeapon(db, itemId)
+		if err != nil {
+			return c.String(500, err.Error())
+		}
+		if !isWeapon {
+			return c.String(404, "Weapon not found")
+		}
+
+		// the milestones replace the profile's trader levels
+		constraints := profile.Constraints(milestones[0])
+		path, err := optimiserService.PlanUpgradePath(c.Request().Context(), itemId, req.BuildType, constraints, milestones, req.Slack)
+		if errors.Is(err, optimiser.ErrBusy) {
+			c.Response().Header().Set(echo.HeaderRetryAfter, "5")
+			return c.String(503, err.Error())
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			return c.String(504, "Optimisation timed out")
+		}
+		if errors.Is(err, evaluator.ErrNoBuild) {
+			return c.String(422, err.Error())
+		}
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to plan upgrade path. item %s, constraints %v", itemId, constraints)
+			return c.String(500, err.Error())
 		}
 
-		return c.JSON(200, build)
+		return c.JSON(200, path)
 	})
 
 	return e
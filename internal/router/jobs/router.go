@@ -0,0 +1,33 @@
+package jobs_router
+
+import (
+	"database/sql"
+	"strconv"
+	"tarkov-build-optimiser/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+func Bind(e *echo.Group, db *sql.DB) *echo.Group {
+	e.GET("/:id", func(c echo.Context) error {
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			return c.String(400, "Invalid job ID")
+		}
+
+		job, err := models.GetJobById(db, id)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to get job %d", id)
+			return c.String(500, err.Error())
+		}
+
+		if job == nil {
+			return c.String(404, "Job not found")
+		}
+
+		return c.JSON(200, job)
+	})
+
+	return e
+}
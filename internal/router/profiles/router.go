@@ -0,0 +1,36 @@
+package profiles_router
+
+import (
+	"database/sql"
+	"tarkov-build-optimiser/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+func Bind(e *echo.Group, db *sql.DB) *echo.Group {
+	e.GET("", func(c echo.Context) error {
+		res, err := models.GetAllConstraintProfiles(db)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to get constraint profiles")
+			return c.String(500, err.Error())
+		}
+
+		return c.JSON(200, res)
+	})
+
+	e.GET("/:name", func(c echo.Context) error {
+		profile, err := models.GetConstraintProfileByName(db, c.Param("name"))
+		if err != nil {
+			return c.String(500, err.Error())
+		}
+
+		if profile == nil {
+			return c.String(404, "Profile not found")
+		}
+
+		return c.JSON(200, profile)
+	})
+
+	return e
+}
@@ -1,15 +1,24 @@
 package router
 
 import (
+	"tarkov-build-optimiser/internal/candidate_tree"
 	"tarkov-build-optimiser/internal/db"
+	"tarkov-build-optimiser/internal/optimiser"
+	buildsrouter "tarkov-build-optimiser/internal/router/builds"
 	itemsrouter "tarkov-build-optimiser/internal/router/items"
+	jobsrouter "tarkov-build-optimiser/internal/router/jobs"
+	profilesrouter "tarkov-build-optimiser/internal/router/profiles"
+	statsrouter "tarkov-build-optimiser/internal/router/stats"
 
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
 
 type Config struct {
-	DB *db.Database
+	DB        *db.Database
+	Optimiser *optimiser.Service
+	// Data is the item data shared with the optimiser
+	Data candidate_tree.TreeDataProvider
 }
 
 func NewRouter(config Config) *echo.Echo {
@@ -24,7 +33,11 @@ func NewRouter(config Config) *echo.Echo {
 		return c.String(200, "Hello, World!")
 	})
 
-	itemsrouter.Bind(api.Group("/items"), config.DB.Conn)
+	itemsrouter.Bind(api.Group("/items"), config.DB.Conn, config.Optimiser, config.Data)
+	statsrouter.Bind(api.Group("/stats"), config.DB.Conn)
+	jobsrouter.Bind(api.Group("/jobs"), config.DB.Conn)
+	profilesrouter.Bind(api.Group("/profiles"), config.DB.Conn)
+	buildsrouter.Bind(api.Group("/builds"), config.DB.Conn, config.Data, config.Optimiser)
 
 	return e
 }
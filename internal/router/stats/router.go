@@ -0,0 +1,64 @@
+package stats_router
+
+import (
+	"database/sql"
+	"strconv"
+	"tarkov-build-optimiser/internal/models"
+
+	"github.com/labstack/echo/v4"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	defaultLimit = 20
+	maxLimit     = 500
+)
+
+// getLimitParam parses the optional limit query parameter, defaulting to defaultLimit
+func getLimitParam(c echo.Context) (int, bool) {
+	value := c.QueryParam("limit")
+	if value == "" {
+		return defaultLimit, true
+	}
+
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxLimit {
+		return 0, false
+	}
+
+	return limit, true
+}
+
+func Bind(e *echo.Group, db *sql.DB) *echo.Group {
+	e.GET("/slowest-weapons", func(c echo.Context) error {
+		limit, ok := getLimitParam(c)
+		if !ok {
+			return c.String(400, "Invalid limit")
+		}
+
+		res, err := models.GetSlowestWeapons(db, limit)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to get slowest weapons")
+			return c.String(500, err.Error())
+		}
+
+		return c.JSON(200, res)
+	})
+
+	e.GET("/pruning", func(c echo.Context) error {
+		limit, ok := getLimitParam(c)
+		if !ok {
+			return c.String(400, "Invalid limit")
+		}
+
+		res, err := models.GetPruningEffectiveness(db, limit)
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to get pruning effectiveness")
+			return c.String(500, err.Error())
+		}
+
+		return c.JSON(200, res)
+	})
+
+	return e
+}
@@ -0,0 +1,32 @@
+package stats
+
+import (
+	"math"
+	"tarkov-build-optimiser/internal/models"
+)
+
+const (
+	minErgonomics = 0
+	maxErgonomics = 100
+)
+
+// Final is a build's stats as the game shows them on the weapon's inspect screen
+type Final struct {
+	RecoilVertical   int `json:"recoil_vertical"`
+	RecoilHorizontal int `json:"recoil_horizontal"`
+	Ergonomics       int `json:"ergonomics"`
+}
+
+// Compute returns the in-game stats of build on weapon. The mods' recoil modifiers are percentages which together scale
+// the weapon's base recoil, so -10 and -15 take 100 vertical recoil to 75. Ergonomics modifiers are points added to the
+// weapon's base ergonomics, which the game clamps to 0-100. Values are rounded as the game rounds them for display.
+func Compute(weapon *models.Weapon, build *models.ItemEvaluationResult) Final {
+	recoilMultiplier := math.Max(0, 1+build.RecoilSum.Points()/100)
+	ergonomics := weapon.Ergonomics + build.ErgonomicsSum.Points()
+
+	return Final{
+		RecoilVertical:   int(math.Round(float64(weapon.RecoilVertical) * recoilMultiplier)),
+		RecoilHorizontal: int(math.Round(float64(weapon.RecoilHorizontal) * recoilMultiplier)),
+		Ergonomics:       int(math.Round(math.Min(maxErgonomics, math.Max(minErgonomics, ergonomics)))),
+	}
+}
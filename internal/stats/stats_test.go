@@ -0,0 +1,39 @@
+package stats
+
+import (
+	"tarkov-build-optimiser/internal/models"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompute(t *testing.T) {
+	weapon := &models.Weapon{RecoilVertical: 120, RecoilHorizontal: 300, Ergonomics: 45}
+	build := &models.ItemEvaluationResult{RecoilSum: models.NewStat(-27.5), ErgonomicsSum: models.NewStat(12.5)}
+
+	final := Compute(weapon, build)
+
+	assert.Equal(t, 87, final.RecoilVertical)
+	assert.Equal(t, 218, final.RecoilHorizontal)
+	assert.Equal(t, 58, final.Ergonomics)
+}
+
+func TestCompute_NoMods(t *testing.T) {
+	weapon := &models.Weapon{RecoilVertical: 120, RecoilHorizontal: 300, Ergonomics: 45}
+
+	final := Compute(weapon, &models.ItemEvaluationResult{})
+
+	assert.Equal(t, Final{RecoilVertical: 120, RecoilHorizontal: 300, Ergonomics: 45}, final)
+}
+
+func TestCompute_Clamped(t *testing.T) {
+	weapon := &models.Weapon{RecoilVertical: 120, RecoilHorizontal: 300, Ergonomics: 45}
+
+	high := Compute(weapon, &models.ItemEvaluationResult{RecoilSum: models.NewStat(-120), ErgonomicsSum: models.NewStat(70)})
+	assert.Equal(t, 0, high.RecoilVertical)
+	assert.Equal(t, 0, high.RecoilHorizontal)
+	assert.Equal(t, 100, high.Ergonomics)
+
+	low := Compute(weapon, &models.ItemEvaluationResult{ErgonomicsSum: models.NewStat(-60)})
+	assert.Equal(t, 0, low.Ergonomics)
+}
@@ -258,6 +258,8 @@ type GetWeaponModsItemsItem struct {
 	Id                 string                                       `json:"id"`
 	ErgonomicsModifier float64                                      `json:"ergonomicsModifier"`
 	RecoilModifier     float64                                      `json:"recoilModifier"`
+	AccuracyModifier   float64                                      `json:"accuracyModifier"`
+	Weight             float64                                      `json:"weight"`
 	Types              []ItemType                                   `json:"types"`
 	ConflictingItems   []GetWeaponModsItemsItemConflictingItemsItem `json:"conflictingItems"`
 	Category           GetWeaponModsItemsItemCategory               `json:"category"`
@@ -276,6 +278,12 @@ func (v *GetWeaponModsItemsItem) GetErgonomicsModifier() float64 { return v.Ergo
 // GetRecoilModifier returns GetWeaponModsItemsItem.RecoilModifier, and is useful for accessing the field via an interface.
 func (v *GetWeaponModsItemsItem) GetRecoilModifier() float64 { return v.RecoilModifier }
 
+// GetAccuracyModifier returns GetWeaponModsItemsItem.AccuracyModifier, and is useful for accessing the field via an interface.
+func (v *GetWeaponModsItemsItem) GetAccuracyModifier() float64 { return v.AccuracyModifier }
+
+// GetWeight returns GetWeaponModsItemsItem.Weight, and is useful for accessing the field via an interface.
+func (v *GetWeaponModsItemsItem) GetWeight() float64 { return v.Weight }
+
 // GetTypes returns GetWeaponModsItemsItem.Types, and is useful for accessing the field via an interface.
 func (v *GetWeaponModsItemsItem) GetTypes() []ItemType { return v.Types }
 
@@ -334,6 +342,10 @@ type __premarshalGetWeaponModsItemsItem struct {
 
 	RecoilModifier float64 `json:"recoilModifier"`
 
+	AccuracyModifier float64 `json:"accuracyModifier"`
+
+	Weight float64 `json:"weight"`
+
 	Types []ItemType `json:"types"`
 
 	ConflictingItems []GetWeaponModsItemsItemConflictingItemsItem `json:"conflictingItems"`
@@ -358,6 +370,8 @@ func (v *GetWeaponModsItemsItem) __premarshalJSON() (*__premarshalGetWeaponModsI
 	retval.Id = v.Id
 	retval.ErgonomicsModifier = v.ErgonomicsModifier
 	retval.RecoilModifier = v.RecoilModifier
+	retval.AccuracyModifier = v.AccuracyModifier
+	retval.Weight = v.Weight
 	retval.Types = v.Types
 	retval.ConflictingItems = v.ConflictingItems
 	retval.Category = v.Category
@@ -864,6 +878,9 @@ type GetWeaponModsItemsItemPropertiesItemPropertiesBarrel struct {
 	Typename       string                                                              `json:"__typename"`
 	Ergonomics     float64                                                             `json:"ergonomics"`
 	RecoilModifier float64                                                             `json:"recoilModifier"`
+	CenterOfImpact float64                                                             `json:"centerOfImpact"`
+	DeviationCurve float64                                                             `json:"deviationCurve"`
+	DeviationMax   float64                                                             `json:"deviationMax"`
 	Slots          []GetWeaponModsItemsItemPropertiesItemPropertiesBarrelSlotsItemSlot `json:"slots"`
 }
 
@@ -882,6 +899,21 @@ func (v *GetWeaponModsItemsItemPropertiesItemPropertiesBarrel) GetRecoilModifier
 	return v.RecoilModifier
 }
 
+// GetCenterOfImpact returns GetWeaponModsItemsItemPropertiesItemPropertiesBarrel.CenterOfImpact, and is useful for accessing the field via an interface.
+func (v *GetWeaponModsItemsItemPropertiesItemPropertiesBarrel) GetCenterOfImpact() float64 {
+	return v.CenterOfImpact
+}
+
+// GetDeviationCurve returns GetWeaponModsItemsItemPropertiesItemPropertiesBarrel.DeviationCurve, and is useful for accessing the field via an interface.
+func (v *GetWeaponModsItemsItemPropertiesItemPropertiesBarrel) GetDeviationCurve() float64 {
+	return v.DeviationCurve
+}
+
+// GetDeviationMax returns GetWeaponModsItemsItemPropertiesItemPropertiesBarrel.DeviationMax, and is useful for accessing the field via an interface.
+func (v *GetWeaponModsItemsItemPropertiesItemPropertiesBarrel) GetDeviationMax() float64 {
+	return v.DeviationMax
+}
+
 // GetSlots returns GetWeaponModsItemsItemPropertiesItemPropertiesBarrel.Slots, and is useful for accessing the field via an interface.
 func (v *GetWeaponModsItemsItemPropertiesItemPropertiesBarrel) GetSlots() []GetWeaponModsItemsItemPropertiesItemPropertiesBarrelSlotsItemSlot {
 	return v.Slots
@@ -889,9 +921,10 @@ func (v *GetWeaponModsItemsItemPropertiesItemPropertiesBarrel) GetSlots() []GetW
 
 // GetWeaponModsItemsItemPropertiesItemPropertiesBarrelSlotsItemSlot includes the requested fields of the GraphQL type ItemSlot.
 type GetWeaponModsItemsItemPropertiesItemPropertiesBarrelSlotsItemSlot struct {
-	Id      string                                                                              `json:"id"`
-	Name    string                                                                              `json:"name"`
-	Filters GetWeaponModsItemsItemPropertiesItemPropertiesBarrelSlotsItemSlotFiltersItemFilters `json:"filters"`
+	Id       string                                                                              `json:"id"`
+	Name     string                                                                              `json:"name"`
+	Required bool                                                                                `json:"required"`
+	Filters  GetWeaponModsItemsItemPropertiesItemPropertiesBarrelSlotsItemSlotFiltersItemFilters `json:"filters"`
 }
 
 // GetId returns GetWeaponModsItemsItemPropertiesItemPropertiesBarrelSlotsItemSlot.Id, and is useful for accessing the field via an interface.
@@ -904,6 +937,11 @@ func (v *GetWeaponModsItemsItemPropertiesItemPropertiesBarrelSlotsItemSlot) GetN
 	return v.Name
 }
 
+// GetRequired returns GetWeaponModsItemsItemPropertiesItemPropertiesBarrelSlotsItemSlot.Required, and is useful for accessing the field via an interface.
+func (v *GetWeaponModsItemsItemPropertiesItemPropertiesBarrelSlotsItemSlot) GetRequired() bool {
+	return v.Required
+}
+
 // GetFilters returns GetWeaponModsItemsItemPropertiesItemPropertiesBarrelSlotsItemSlot.Filters, and is useful for accessing the field via an interface.
 func (v *GetWeaponModsItemsItemPropertiesItemPropertiesBarrelSlotsItemSlot) GetFilters() GetWeaponModsItemsItemPropertiesItemPropertiesBarrelSlotsItemSlotFiltersItemFilters {
 	return v.Filters
@@ -1053,9 +1091,10 @@ func (v *GetWeaponModsItemsItemPropertiesItemPropertiesMagazine) GetSlots() []Ge
 
 // GetWeaponModsItemsItemPropertiesItemPropertiesMagazineSlotsItemSlot includes the requested fields of the GraphQL type ItemSlot.
 type GetWeaponModsItemsItemPropertiesItemPropertiesMagazineSlotsItemSlot struct {
-	Id      string                                                                                `json:"id"`
-	Name    string                                                                                `json:"name"`
-	Filters GetWeaponModsItemsItemPropertiesItemPropertiesMagazineSlotsItemSlotFiltersItemFilters `json:"filters"`
+	Id       string                                                                                `json:"id"`
+	Name     string                                                                                `json:"name"`
+	Required bool                                                                                  `json:"required"`
+	Filters  GetWeaponModsItemsItemPropertiesItemPropertiesMagazineSlotsItemSlotFiltersItemFilters `json:"filters"`
 }
 
 // GetId returns GetWeaponModsItemsItemPropertiesItemPropertiesMagazineSlotsItemSlot.Id, and is useful for accessing the field via an interface.
@@ -1068,6 +1107,11 @@ func (v *GetWeaponModsItemsItemPropertiesItemPropertiesMagazineSlotsItemSlot) Ge
 	return v.Name
 }
 
+// GetRequired returns GetWeaponModsItemsItemPropertiesItemPropertiesMagazineSlotsItemSlot.Required, and is useful for accessing the field via an interface.
+func (v *GetWeaponModsItemsItemPropertiesItemPropertiesMagazineSlotsItemSlot) GetRequired() bool {
+	return v.Required
+}
+
 // GetFilters returns GetWeaponModsItemsItemPropertiesItemPropertiesMagazineSlotsItemSlot.Filters, and is useful for accessing the field via an interface.
 func (v *GetWeaponModsItemsItemPropertiesItemPropertiesMagazineSlotsItemSlot) GetFilters() GetWeaponModsItemsItemPropertiesItemPropertiesMagazineSlotsItemSlotFiltersItemFilters {
 	return v.Filters
@@ -1195,9 +1239,10 @@ func (v *GetWeaponModsItemsItemPropertiesItemPropertiesScope) GetSlots() []GetWe
 
 // GetWeaponModsItemsItemPropertiesItemPropertiesScopeSlotsItemSlot includes the requested fields of the GraphQL type ItemSlot.
 type GetWeaponModsItemsItemPropertiesItemPropertiesScopeSlotsItemSlot struct {
-	Id      string                                                                             `json:"id"`
-	Name    string                                                                             `json:"name"`
-	Filters GetWeaponModsItemsItemPropertiesItemPropertiesScopeSlotsItemSlotFiltersItemFilters `json:"filters"`
+	Id       string                                                                             `json:"id"`
+	Name     string                                                                             `json:"name"`
+	Required bool                                                                               `json:"required"`
+	Filters  GetWeaponModsItemsItemPropertiesItemPropertiesScopeSlotsItemSlotFiltersItemFilters `json:"filters"`
 }
 
 // GetId returns GetWeaponModsItemsItemPropertiesItemPropertiesScopeSlotsItemSlot.Id, and is useful for accessing the field via an interface.
@@ -1210,6 +1255,11 @@ func (v *GetWeaponModsItemsItemPropertiesItemPropertiesScopeSlotsItemSlot) GetNa
 	return v.Name
 }
 
+// GetRequired returns GetWeaponModsItemsItemPropertiesItemPropertiesScopeSlotsItemSlot.Required, and is useful for accessing the field via an interface.
+func (v *GetWeaponModsItemsItemPropertiesItemPropertiesScopeSlotsItemSlot) GetRequired() bool {
+	return v.Required
+}
+
 // GetFilters returns GetWeaponModsItemsItemPropertiesItemPropertiesScopeSlotsItemSlot.Filters, and is useful for accessing the field via an interface.
 func (v *GetWeaponModsItemsItemPropertiesItemPropertiesScopeSlotsItemSlot) GetFilters() GetWeaponModsItemsItemPropertiesItemPropertiesScopeSlotsItemSlotFiltersItemFilters {
 	return v.Filters
@@ -1299,9 +1349,10 @@ func (v *GetWeaponModsItemsItemPropertiesItemPropertiesWeaponMod) GetSlots() []G
 
 // GetWeaponModsItemsItemPropertiesItemPropertiesWeaponModSlotsItemSlot includes the requested fields of the GraphQL type ItemSlot.
 type GetWeaponModsItemsItemPropertiesItemPropertiesWeaponModSlotsItemSlot struct {
-	Id      string                                                                                 `json:"id"`
-	Name    string                                                                                 `json:"name"`
-	Filters GetWeaponModsItemsItemPropertiesItemPropertiesWeaponModSlotsItemSlotFiltersItemFilters `json:"filters"`
+	Id       string                                                                                 `json:"id"`
+	Name     string                                                                                 `json:"name"`
+	Required bool                                                                                   `json:"required"`
+	Filters  GetWeaponModsItemsItemPropertiesItemPropertiesWeaponModSlotsItemSlotFiltersItemFilters `json:"filters"`
 }
 
 // GetId returns GetWeaponModsItemsItemPropertiesItemPropertiesWeaponModSlotsItemSlot.Id, and is useful for accessing the field via an interface.
@@ -1314,6 +1365,11 @@ func (v *GetWeaponModsItemsItemPropertiesItemPropertiesWeaponModSlotsItemSlot) G
 	return v.Name
 }
 
+// GetRequired returns GetWeaponModsItemsItemPropertiesItemPropertiesWeaponModSlotsItemSlot.Required, and is useful for accessing the field via an interface.
+func (v *GetWeaponModsItemsItemPropertiesItemPropertiesWeaponModSlotsItemSlot) GetRequired() bool {
+	return v.Required
+}
+
 // GetFilters returns GetWeaponModsItemsItemPropertiesItemPropertiesWeaponModSlotsItemSlot.Filters, and is useful for accessing the field via an interface.
 func (v *GetWeaponModsItemsItemPropertiesItemPropertiesWeaponModSlotsItemSlot) GetFilters() GetWeaponModsItemsItemPropertiesItemPropertiesWeaponModSlotsItemSlotFiltersItemFilters {
 	return v.Filters
@@ -1360,6 +1416,7 @@ type GetWeaponsItemsItem struct {
 	Id                 string                                    `json:"id"`
 	ErgonomicsModifier float64                                   `json:"ergonomicsModifier"`
 	RecoilModifier     float64                                   `json:"recoilModifier"`
+	Weight             float64                                   `json:"weight"`
 	Types              []ItemType                                `json:"types"`
 	ConflictingItems   []GetWeaponsItemsItemConflictingItemsItem `json:"conflictingItems"`
 	Properties         GetWeaponsItemsItemProperties             `json:"-"`
@@ -1380,6 +1437,9 @@ func (v *GetWeaponsItemsItem) GetErgonomicsModifier() float64 { return v.Ergonom
 // GetRecoilModifier returns GetWeaponsItemsItem.RecoilModifier, and is useful for accessing the field via an interface.
 func (v *GetWeaponsItemsItem) GetRecoilModifier() float64 { return v.RecoilModifier }
 
+// GetWeight returns GetWeaponsItemsItem.Weight, and is useful for accessing the field via an interface.
+func (v *GetWeaponsItemsItem) GetWeight() float64 { return v.Weight }
+
 // GetTypes returns GetWeaponsItemsItem.Types, and is useful for accessing the field via an interface.
 func (v *GetWeaponsItemsItem) GetTypes() []ItemType { return v.Types }
 
@@ -1435,6 +1495,8 @@ type __premarshalGetWeaponsItemsItem struct {
 
 	RecoilModifier float64 `json:"recoilModifier"`
 
+	Weight float64 `json:"weight"`
+
 	Types []ItemType `json:"types"`
 
 	ConflictingItems []GetWeaponsItemsItemConflictingItemsItem `json:"conflictingItems"`
@@ -1458,6 +1520,7 @@ func (v *GetWeaponsItemsItem) __premarshalJSON() (*__premarshalGetWeaponsItemsIt
 	retval.Id = v.Id
 	retval.ErgonomicsModifier = v.ErgonomicsModifier
 	retval.RecoilModifier = v.RecoilModifier
+	retval.Weight = v.Weight
 	retval.Types = v.Types
 	retval.ConflictingItems = v.ConflictingItems
 	{
@@ -2133,6 +2196,9 @@ type GetWeaponsItemsItemPropertiesItemPropertiesWeapon struct {
 	RecoilHorizontal  int                                                              `json:"recoilHorizontal"`
 	Ergonomics        float64                                                          `json:"ergonomics"`
 	DefaultErgonomics float64                                                          `json:"defaultErgonomics"`
+	CenterOfImpact    float64                                                          `json:"centerOfImpact"`
+	DeviationCurve    float64                                                          `json:"deviationCurve"`
+	DeviationMax      float64                                                          `json:"deviationMax"`
 	Slots             []GetWeaponsItemsItemPropertiesItemPropertiesWeaponSlotsItemSlot `json:"slots"`
 }
 
@@ -2159,6 +2225,21 @@ func (v *GetWeaponsItemsItemPropertiesItemPropertiesWeapon) GetDefaultErgonomics
 	return v.DefaultErgonomics
 }
 
+// GetCenterOfImpact returns GetWeaponsItemsItemPropertiesItemPropertiesWeapon.CenterOfImpact, and is useful for accessing the field via an interface.
+func (v *GetWeaponsItemsItemPropertiesItemPropertiesWeapon) GetCenterOfImpact() float64 {
+	return v.CenterOfImpact
+}
+
+// GetDeviationCurve returns GetWeaponsItemsItemPropertiesItemPropertiesWeapon.DeviationCurve, and is useful for accessing the field via an interface.
+func (v *GetWeaponsItemsItemPropertiesItemPropertiesWeapon) GetDeviationCurve() float64 {
+	return v.DeviationCurve
+}
+
+// GetDeviationMax returns GetWeaponsItemsItemPropertiesItemPropertiesWeapon.DeviationMax, and is useful for accessing the field via an interface.
+func (v *GetWeaponsItemsItemPropertiesItemPropertiesWeapon) GetDeviationMax() float64 {
+	return v.DeviationMax
+}
+
 // GetSlots returns GetWeaponsItemsItemPropertiesItemPropertiesWeapon.Slots, and is useful for accessing the field via an interface.
 func (v *GetWeaponsItemsItemPropertiesItemPropertiesWeapon) GetSlots() []GetWeaponsItemsItemPropertiesItemPropertiesWeaponSlotsItemSlot {
 	return v.Slots
@@ -2176,9 +2257,10 @@ func (v *GetWeaponsItemsItemPropertiesItemPropertiesWeaponMod) GetTypename() str
 
 // GetWeaponsItemsItemPropertiesItemPropertiesWeaponSlotsItemSlot includes the requested fields of the GraphQL type ItemSlot.
 type GetWeaponsItemsItemPropertiesItemPropertiesWeaponSlotsItemSlot struct {
-	Id      string                                                                           `json:"id"`
-	Name    string                                                                           `json:"name"`
-	Filters GetWeaponsItemsItemPropertiesItemPropertiesWeaponSlotsItemSlotFiltersItemFilters `json:"filters"`
+	Id       string                                                                           `json:"id"`
+	Name     string                                                                           `json:"name"`
+	Required bool                                                                             `json:"required"`
+	Filters  GetWeaponsItemsItemPropertiesItemPropertiesWeaponSlotsItemSlotFiltersItemFilters `json:"filters"`
 }
 
 // GetId returns GetWeaponsItemsItemPropertiesItemPropertiesWeaponSlotsItemSlot.Id, and is useful for accessing the field via an interface.
@@ -2189,6 +2271,11 @@ func (v *GetWeaponsItemsItemPropertiesItemPropertiesWeaponSlotsItemSlot) GetName
 	return v.Name
 }
 
+// GetRequired returns GetWeaponsItemsItemPropertiesItemPropertiesWeaponSlotsItemSlot.Required, and is useful for accessing the field via an interface.
+func (v *GetWeaponsItemsItemPropertiesItemPropertiesWeaponSlotsItemSlot) GetRequired() bool {
+	return v.Required
+}
+
 // GetFilters returns GetWeaponsItemsItemPropertiesItemPropertiesWeaponSlotsItemSlot.Filters, and is useful for accessing the field via an interface.
 func (v *GetWeaponsItemsItemPropertiesItemPropertiesWeaponSlotsItemSlot) GetFilters() GetWeaponsItemsItemPropertiesItemPropertiesWeaponSlotsItemSlotFiltersItemFilters {
 	return v.Filters
@@ -2316,6 +2403,8 @@ query GetWeaponMods {
 		id
 		ergonomicsModifier
 		recoilModifier
+		accuracyModifier
+		weight
 		types
 		conflictingItems {
 			id
@@ -2334,6 +2423,7 @@ query GetWeaponMods {
 				slots {
 					id
 					name
+					required
 					filters {
 						allowedItems {
 							id
@@ -2349,6 +2439,7 @@ query GetWeaponMods {
 				slots {
 					id
 					name
+					required
 					filters {
 						allowedItems {
 							id
@@ -2361,9 +2452,13 @@ query GetWeaponMods {
 				__typename
 				ergonomics
 				recoilModifier
+				centerOfImpact
+				deviationCurve
+				deviationMax
 				slots {
 					id
 					name
+					required
 					filters {
 						allowedItems {
 							id
@@ -2379,6 +2474,7 @@ query GetWeaponMods {
 				slots {
 					id
 					name
+					required
 					filters {
 						allowedItems {
 							id
@@ -2423,6 +2519,7 @@ query GetWeapons {
 		id
 		ergonomicsModifier
 		recoilModifier
+		weight
 		types
 		conflictingItems {
 			id
@@ -2436,9 +2533,13 @@ query GetWeapons {
 				recoilHorizontal
 				ergonomics
 				defaultErgonomics
+				centerOfImpact
+				deviationCurve
+				deviationMax
 				slots {
 					id
 					name
+					required
 					filters {
 						allowedItems {
 							id
@@ -0,0 +1,69 @@
+package tracer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+// kindOrder is the order summaries list the counts of each kind in
+var kindOrder = []Kind{EnterSlot, TryItem, PruneBound, PruneCost, PruneLimit, CacheHit, CacheSkip, Incumbent}
+
+// WriteReplay writes the events read from r on the slot and item, either may be empty to match every slot or item,
+// one per line indented by depth. Events are streamed, so traces of any size can be replayed. It returns the number
+// of events written.
+func WriteReplay(w io.Writer, r *Reader, slotID string, itemID string) (int, error) {
+	written := 0
+	for {
+		event, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return written, nil
+		}
+		if err != nil {
+			return written, err
+		}
+		if !event.Matches(slotID, itemID) {
+			continue
+		}
+
+		_, err = fmt.Fprintf(w, "%s%s\n", strings.Repeat("  ", event.Depth), event)
+		if err != nil {
+			return written, err
+		}
+		written++
+	}
+}
+
+// WriteSummary writes the counts of each kind of event followed by tables of the slots and items the search spent
+// longest on, limit rows each or all of them when limit is 0
+func WriteSummary(w io.Writer, summary Summary, limit int) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "EVENTS\t%d\n", summary.Events)
+	fmt.Fprintf(tw, "MAX DEPTH\t%d\n", summary.MaxDepth)
+	for _, kind := range kindOrder {
+		fmt.Fprintf(tw, "%s\t%d\n", strings.ToUpper(kind.String()), summary.Counts[kind])
+	}
+
+	slots := summary.Slots
+	if limit > 0 && len(slots) > limit {
+		slots = slots[:limit]
+	}
+	fmt.Fprint(tw, "\nSLOT\tENTERED\tTRIED\tPRUNED\tCACHE HITS\tCACHE SKIPS\tINCUMBENTS\n")
+	for _, slot := range slots {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%d\t%d\n", slot.SlotID, slot.Entered, slot.Tried, slot.Pruned,
+			slot.CacheHits, slot.CacheSkips, slot.Incumbents)
+	}
+
+	items := summary.Items
+	if limit > 0 && len(items) > limit {
+		items = items[:limit]
+	}
+	fmt.Fprint(tw, "\nITEM\tTRIED\tPRUNED\tINCUMBENTS\n")
+	for _, item := range items {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\n", item.ItemID, item.Tried, item.Pruned, item.Incumbents)
+	}
+
+	return tw.Flush()
+}
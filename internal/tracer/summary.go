@@ -0,0 +1,126 @@
+package tracer
+
+import (
+	"slices"
+	"strings"
+)
+
+// SlotSummary counts the decisions made on one slot
+type SlotSummary struct {
+	SlotID     string
+	Entered    int
+	Tried      int
+	Pruned     int
+	CacheHits  int
+	CacheSkips int
+	Incumbents int
+}
+
+// ItemSummary counts the decisions made on one item, in any slot
+type ItemSummary struct {
+	ItemID     string
+	Tried      int
+	Pruned     int
+	Incumbents int
+}
+
+// Summary is where a search spent its time
+type Summary struct {
+	Events   int
+	MaxDepth int
+	Counts   map[Kind]int
+	// Slots are ordered by the number of items tried, most first
+	Slots []SlotSummary
+	// Items are ordered by the number of times tried, most first, without leaving slots empty
+	Items []ItemSummary
+}
+
+// Summarise counts a trace's decisions by kind, slot and item
+func Summarise(events []Event) Summary {
+	summary := Summary{Events: len(events), Counts: make(map[Kind]int)}
+	slots := make(map[string]*SlotSummary)
+	items := make(map[string]*ItemSummary)
+
+	for _, event := range events {
+		summary.Counts[event.Kind]++
+		summary.MaxDepth = max(summary.MaxDepth, event.Depth)
+
+		slot, ok := slots[event.SlotID]
+		if !ok {
+			slot = &SlotSummary{SlotID: event.SlotID}
+			slots[event.SlotID] = slot
+		}
+		var item *ItemSummary
+		if event.ItemID != "" {
+			item, ok = items[event.ItemID]
+			if !ok {
+				item = &ItemSummary{ItemID: event.ItemID}
+				items[event.ItemID] = item
+			}
+		}
+
+		switch event.Kind {
+		case EnterSlot:
+			slot.Entered++
+		case TryItem:
+			slot.Tried++
+			if item != nil {
+				item.Tried++
+			}
+		case PruneBound, PruneCost, PruneLimit:
+			slot.Pruned++
+			if item != nil {
+				item.Pruned++
+			}
+		case CacheHit:
+			slot.CacheHits++
+		case CacheSkip:
+			slot.CacheSkips++
+		case Incumbent:
+			slot.Incumbents++
+			if item != nil {
+				item.Incumbents++
+			}
+		}
+	}
+
+	summary.Slots = make([]SlotSummary, 0, len(slots))
+	for _, slot := range slots {
+		summary.Slots = append(summary.Slots, *slot)
+	}
+	slices.SortFunc(summary.Slots, func(a, b SlotSummary) int {
+		if a.Tried != b.Tried {
+			return b.Tried - a.Tried
+		}
+		return strings.Compare(a.SlotID, b.SlotID)
+	})
+
+	summary.Items = make([]ItemSummary, 0, len(items))
+	for _, item := range items {
+		summary.Items = append(summary.Items, *item)
+	}
+	slices.SortFunc(summary.Items, func(a, b ItemSummary) int {
+		if a.Tried != b.Tried {
+			return b.Tried - a.Tried
+		}
+		return strings.Compare(a.ItemID, b.ItemID)
+	})
+
+	return summary
+}
+
+// Filter returns the events on the slot and item, either may be empty to match every slot or item
+func Filter(events []Event, slotID string, itemID string) []Event {
+	filtered := make([]Event, 0)
+	for _, event := range events {
+		if event.Matches(slotID, itemID) {
+			filtered = append(filtered, event)
+		}
+	}
+	return filtered
+}
+
+// Matches is whether the event is on the slot and item, either may be empty to match every slot or item
+func (e Event) Matches(slotID string, itemID string) bool {
+	return (slotID == "" || e.SlotID == slotID) && (itemID == "" || e.ItemID == itemID)
+}
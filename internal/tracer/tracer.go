@@ -0,0 +1,263 @@
+package tracer
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// Kind is a decision made while searching for a build
+type Kind byte
+
+const (
+	// EnterSlot is the search starting on a slot
+	EnterSlot Kind = iota + 1
+	// TryItem is an item being fitted to the slot and the rest of the build searched, an empty item tries leaving the
+	// slot empty
+	TryItem
+	// PruneBound is an item, or leaving the slot empty, skipped because the bound on the focused stat can't beat
+	// the best build found so far. Value is the bound.
+	PruneBound
+	// CacheHit is an item whose best children were found in the cache
+	CacheHit
+	// CacheSkip is an item skipped because its cached children can't beat the best build found so far. Value is
+	// the estimate.
+	CacheSkip
+	// Incumbent is a new best build for the slot, Value is its focused stat
+	Incumbent
+	// PruneCost is an item skipped by an upgrade path search because it costs more than the cheapest build found so
+	// far. Value is the purchase cost in roubles.
+	PruneCost
+	// PruneLimit is an item, or leaving the slot empty, skipped by an upgrade path search because the build can't
+	// reach the focused stat's limit
+	PruneLimit
+)
+
+// kindString defines the next string index, it isn't an event
+const kindString Kind = 0xff
+
+var kindNames = map[Kind]string{
+	EnterSlot:  "enter",
+	TryItem:    "try",
+	PruneBound: "prune",
+	CacheHit:   "cache_hit",
+	CacheSkip:  "cache_skip",
+	Incumbent:  "incumbent",
+	PruneCost:  "prune_cost",
+	PruneLimit: "prune_limit",
+}
+
+func (k Kind) String() string {
+	if name, ok := kindNames[k]; ok {
+		return name
+	}
+	return fmt.Sprintf("kind(%d)", byte(k))
+}
+
+// magic starts every trace file, followed by the format version
+const magic = "TBOTRACE"
+
+const version = 1
+
+// valueScale stores values in thousandths, the precision of models.Stat
+const valueScale = 1000
+
+// Event is one recorded decision
+type Event struct {
+	Kind Kind
+	// Depth is the number of items chosen above the decision
+	Depth  int
+	SlotID string
+	ItemID string
+	// Value is in the focused stat's units, see each Kind
+	Value float64
+}
+
+func (e Event) String() string {
+	parts := []string{e.Kind.String(), e.SlotID}
+	if e.ItemID != "" {
+		parts = append(parts, e.ItemID)
+	} else if e.Kind == TryItem || e.Kind == PruneBound || e.Kind == Incumbent {
+		parts = append(parts, "(empty)")
+	}
+	switch e.Kind {
+	case PruneBound, CacheSkip:
+		parts = append(parts, fmt.Sprintf("bound=%g", e.Value))
+	case PruneCost:
+		parts = append(parts, fmt.Sprintf("cost=%g", e.Value))
+	case Incumbent:
+		parts = append(parts, fmt.Sprintf("value=%g", e.Value))
+	}
+	return strings.Join(parts, " ")
+}
+
+// Writer records a search's decisions. Slot and item IDs are written once and referred to by index after, and
+// numbers are varints, which keeps traces of millions of decisions small. Write errors are kept and returned by
+// Close, so recording never fails mid search.
+type Writer struct {
+	w       *bufio.Writer
+	closer  io.Closer
+	strings map[string]uint64
+	buf     []byte
+	err     error
+}
+
+// NewWriter starts a trace on w, which is closed by Close if it is an io.Closer
+func NewWriter(w io.Writer) *Writer {
+	t := &Writer{
+		w:       bufio.NewWriter(w),
+		strings: map[string]uint64{"": 0},
+		buf:     make([]byte, 0, 4*binary.MaxVarintLen64+1),
+	}
+	if closer, ok := w.(io.Closer); ok {
+		t.closer = closer
+	}
+	_, t.err = t.w.WriteString(magic)
+	if t.err == nil {
+		t.err = t.w.WriteByte(version)
+	}
+	return t
+}
+
+// intern returns the index of s, defining it first if it hasn't been written yet
+func (t *Writer) intern(s string) uint64 {
+	index, ok := t.strings[s]
+	if ok {
+		return index
+	}
+
+	index = uint64(len(t.strings))
+	t.strings[s] = index
+	t.buf = append(t.buf[:0], byte(kindString))
+	t.buf = binary.AppendUvarint(t.buf, uint64(len(s)))
+	t.write(t.buf)
+	t.write([]byte(s))
+	return index
+}
+
+func (t *Writer) write(b []byte) {
+	if t.err != nil {
+		return
+	}
+	_, t.err = t.w.Write(b)
+}
+
+// Record writes an event, see Kind for what value holds
+func (t *Writer) Record(kind Kind, depth int, slotID string, itemID string, value float64) {
+	slot := t.intern(slotID)
+	item := t.intern(itemID)
+
+	t.buf = append(t.buf[:0], byte(kind))
+	t.buf = binary.AppendUvarint(t.buf, uint64(depth))
+	t.buf = binary.AppendUvarint(t.buf, slot)
+	t.buf = binary.AppendUvarint(t.buf, item)
+	t.buf = binary.AppendVarint(t.buf, int64(math.Round(value*valueScale)))
+	t.write(t.buf)
+}
+
+// Close flushes the trace, returning the first error writing it
+func (t *Writer) Close() error {
+	if t.err == nil {
+		t.err = t.w.Flush()
+	}
+	if t.closer != nil {
+		if err := t.closer.Close(); err != nil && t.err == nil {
+			t.err = err
+		}
+	}
+	return t.err
+}
+
+// ErrNotTrace is returned when reading a file which isn't a trace, or is of an unsupported version
+var ErrNotTrace = errors.New("not a search trace")
+
+// Reader reads the events of a trace in the order they were recorded
+type Reader struct {
+	r       *bufio.Reader
+	strings []string
+}
+
+// NewReader checks the trace's header and returns a Reader for its events
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(br, header); err != nil || string(header[:len(magic)]) != magic || header[len(magic)] != version {
+		return nil, ErrNotTrace
+	}
+	return &Reader{r: br, strings: []string{""}}, nil
+}
+
+func (r *Reader) lookup(index uint64) (string, error) {
+	if index >= uint64(len(r.strings)) {
+		return "", fmt.Errorf("undefined string %d", index)
+	}
+	return r.strings[index], nil
+}
+
+// Next returns the next event, io.EOF at the end of the trace
+func (r *Reader) Next() (Event, error) {
+	for {
+		kind, err := r.r.ReadByte()
+		if err != nil {
+			return Event{}, err
+		}
+
+		if Kind(kind) == kindString {
+			length, err := binary.ReadUvarint(r.r)
+			if err != nil {
+				return Event{}, io.ErrUnexpectedEOF
+			}
+			s := make([]byte, length)
+			if _, err := io.ReadFull(r.r, s); err != nil {
+				return Event{}, io.ErrUnexpectedEOF
+			}
+			r.strings = append(r.strings, string(s))
+			continue
+		}
+
+		var fields [3]uint64
+		for i := range fields {
+			fields[i], err = binary.ReadUvarint(r.r)
+			if err != nil {
+				return Event{}, io.ErrUnexpectedEOF
+			}
+		}
+		value, err := binary.ReadVarint(r.r)
+		if err != nil {
+			return Event{}, io.ErrUnexpectedEOF
+		}
+
+		event := Event{Kind: Kind(kind), Depth: int(fields[0]), Value: float64(value) / valueScale}
+		if event.SlotID, err = r.lookup(fields[1]); err != nil {
+			return Event{}, err
+		}
+		if event.ItemID, err = r.lookup(fields[2]); err != nil {
+			return Event{}, err
+		}
+		return event, nil
+	}
+}
+
+// ReadAll reads every event of a trace
+func ReadAll(r io.Reader) ([]Event, error) {
+	reader, err := NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	events := make([]Event, 0)
+	for {
+		event, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+}
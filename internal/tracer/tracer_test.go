@@ -0,0 +1,110 @@
+package tracer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTrace(t *testing.T) []byte {
+	t.Helper()
+	buf := bytes.Buffer{}
+	trace := NewWriter(&buf)
+	trace.Record(EnterSlot, 0, "slot-muzzle", "", 0)
+	trace.Record(TryItem, 0, "slot-muzzle", "item-brake", 0)
+	trace.Record(EnterSlot, 1, "slot-stock", "", 0)
+	trace.Record(PruneBound, 1, "slot-stock", "item-stock", -12.5)
+	trace.Record(Incumbent, 0, "slot-muzzle", "item-brake", -5.125)
+	trace.Record(CacheSkip, 0, "slot-muzzle", "item-compensator", -3)
+	trace.Record(TryItem, 0, "slot-muzzle", "", 0)
+	require.NoError(t, trace.Close())
+	return buf.Bytes()
+}
+
+func TestWriter_RoundTrip(t *testing.T) {
+	events, err := ReadAll(bytes.NewReader(writeTrace(t)))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Event{
+		{Kind: EnterSlot, Depth: 0, SlotID: "slot-muzzle"},
+		{Kind: TryItem, Depth: 0, SlotID: "slot-muzzle", ItemID: "item-brake"},
+		{Kind: EnterSlot, Depth: 1, SlotID: "slot-stock"},
+		{Kind: PruneBound, Depth: 1, SlotID: "slot-stock", ItemID: "item-stock", Value: -12.5},
+		{Kind: Incumbent, Depth: 0, SlotID: "slot-muzzle", ItemID: "item-brake", Value: -5.125},
+		{Kind: CacheSkip, Depth: 0, SlotID: "slot-muzzle", ItemID: "item-compensator", Value: -3},
+		{Kind: TryItem, Depth: 0, SlotID: "slot-muzzle"},
+	}, events)
+}
+
+func TestWriter_InternsStrings(t *testing.T) {
+	trace := writeTrace(t)
+	// every ID is written once however often it's recorded
+	assert.Equal(t, 1, bytes.Count(trace, []byte("slot-muzzle")))
+	assert.Equal(t, 1, bytes.Count(trace, []byte("item-brake")))
+}
+
+func TestReadAll_NotTrace(t *testing.T) {
+	_, err := ReadAll(strings.NewReader("not a trace at all"))
+	assert.ErrorIs(t, err, ErrNotTrace)
+
+	_, err = ReadAll(strings.NewReader(""))
+	assert.ErrorIs(t, err, ErrNotTrace)
+}
+
+func TestReadAll_Truncated(t *testing.T) {
+	trace := writeTrace(t)
+	_, err := ReadAll(bytes.NewReader(trace[:len(trace)-2]))
+	assert.Error(t, err)
+}
+
+func TestSummarise(t *testing.T) {
+	events, err := ReadAll(bytes.NewReader(writeTrace(t)))
+	require.NoError(t, err)
+
+	summary := Summarise(events)
+	assert.Equal(t, 7, summary.Events)
+	assert.Equal(t, 1, summary.MaxDepth)
+	assert.Equal(t, 2, summary.Counts[EnterSlot])
+	assert.Equal(t, 2, summary.Counts[TryItem])
+
+	assert.Equal(t, []SlotSummary{
+		{SlotID: "slot-muzzle", Entered: 1, Tried: 2, CacheSkips: 1, Incumbents: 1},
+		{SlotID: "slot-stock", Entered: 1, Pruned: 1},
+	}, summary.Slots)
+	assert.Equal(t, []ItemSummary{
+		{ItemID: "item-brake", Tried: 1, Incumbents: 1},
+		{ItemID: "item-compensator"},
+		{ItemID: "item-stock", Pruned: 1},
+	}, summary.Items)
+
+	out := bytes.Buffer{}
+	require.NoError(t, WriteSummary(&out, summary, 1))
+	assert.Contains(t, out.String(), "slot-muzzle")
+	assert.NotContains(t, out.String(), "slot-stock", "expected the summary to be limited to one slot")
+}
+
+func TestFilter(t *testing.T) {
+	events, err := ReadAll(bytes.NewReader(writeTrace(t)))
+	require.NoError(t, err)
+
+	assert.Len(t, Filter(events, "slot-muzzle", ""), 5)
+	assert.Equal(t, []Event{
+		{Kind: TryItem, Depth: 0, SlotID: "slot-muzzle", ItemID: "item-brake"},
+		{Kind: Incumbent, Depth: 0, SlotID: "slot-muzzle", ItemID: "item-brake", Value: -5.125},
+	}, Filter(events, "", "item-brake"))
+	assert.Empty(t, Filter(events, "slot-stock", "item-brake"))
+}
+
+func TestWriteReplay(t *testing.T) {
+	reader, err := NewReader(bytes.NewReader(writeTrace(t)))
+	require.NoError(t, err)
+
+	out := bytes.Buffer{}
+	written, err := WriteReplay(&out, reader, "slot-stock", "")
+	require.NoError(t, err)
+	assert.Equal(t, 2, written)
+	assert.Equal(t, "  enter slot-stock\n  prune slot-stock item-stock bound=-12.5\n", out.String())
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upBuildQueue, downBuildQueue)
+}
+
+func upBuildQueue(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE optimal_build_status
+		ADD COLUMN worker_id VARCHAR,
+		ADD COLUMN heartbeat_at TIMESTAMP WITH TIME ZONE;
+
+		CREATE INDEX idx_optimal_build_status_status ON optimal_build_status (status, build_id);
+	`)
+	return err
+}
+
+func downBuildQueue(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		DROP INDEX IF EXISTS idx_optimal_build_status_status;
+
+		ALTER TABLE optimal_build_status
+		DROP COLUMN worker_id,
+		DROP COLUMN heartbeat_at;
+	`)
+	return err
+}
@@ -0,0 +1,32 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upBuildFailures, downBuildFailures)
+}
+
+func upBuildFailures(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE optimal_build_status
+		ADD COLUMN failure_reason TEXT,
+		ADD COLUMN attempts INTEGER NOT NULL DEFAULT 0,
+		ADD COLUMN last_error_at TIMESTAMP WITH TIME ZONE;
+	`)
+	return err
+}
+
+func downBuildFailures(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE optimal_build_status
+		DROP COLUMN failure_reason,
+		DROP COLUMN attempts,
+		DROP COLUMN last_error_at;
+	`)
+	return err
+}
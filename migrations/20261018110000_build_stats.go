@@ -0,0 +1,42 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upBuildStats, downBuildStats)
+}
+
+func upBuildStats(ctx context.Context, tx *sql.Tx) error {
+	// build_id deliberately has no foreign key so stats survive optimum builds being purged between runs
+	_, err := tx.ExecContext(ctx, `
+		CREATE TABLE build_stats (
+			stat_id SERIAL PRIMARY KEY,
+			build_id INTEGER NOT NULL,
+			item_id VARCHAR NOT NULL,
+			build_type VARCHAR NOT NULL,
+			worker_id VARCHAR,
+			duration_ms BIGINT NOT NULL,
+			items_evaluated BIGINT NOT NULL,
+			cache_hits BIGINT NOT NULL,
+			cache_misses BIGINT NOT NULL,
+			allowed_items_before INTEGER NOT NULL,
+			allowed_items_after INTEGER NOT NULL,
+			slots_before INTEGER NOT NULL,
+			slots_after INTEGER NOT NULL,
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+		);
+
+		CREATE INDEX idx_build_stats_item_id ON build_stats (item_id);
+	`)
+	return err
+}
+
+func downBuildStats(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `DROP TABLE IF EXISTS build_stats;`)
+	return err
+}
@@ -0,0 +1,35 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upBuildShardKey, downBuildShardKey)
+}
+
+// shard_key must stay in step with models.ShardKey, which computes the same hash before a build is inserted
+func upBuildShardKey(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE optimum_builds
+		ADD COLUMN shard_key BIGINT GENERATED ALWAYS AS (
+			('x' || substr(md5(
+				item_id || ':' || build_type || ':' ||
+				jaeger_level::text || ',' || prapor_level::text || ',' || peacekeeper_level::text || ',' ||
+				mechanic_level::text || ',' || skier_level::text
+			), 1, 8))::bit(32)::bigint
+		) STORED;
+	`)
+	return err
+}
+
+func downBuildShardKey(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE optimum_builds
+		DROP COLUMN shard_key;
+	`)
+	return err
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upBuildPriority, downBuildPriority)
+}
+
+func upBuildPriority(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE optimal_build_status
+		ADD COLUMN priority INTEGER NOT NULL DEFAULT 0,
+		ADD COLUMN requested_at TIMESTAMP WITH TIME ZONE;
+
+		CREATE INDEX idx_optimal_build_status_priority ON optimal_build_status (status, priority DESC, build_id);
+	`)
+	return err
+}
+
+func downBuildPriority(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		DROP INDEX IF EXISTS idx_optimal_build_status_priority;
+
+		ALTER TABLE optimal_build_status
+		DROP COLUMN priority,
+		DROP COLUMN requested_at;
+	`)
+	return err
+}
@@ -0,0 +1,74 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upConstraintProfiles, downConstraintProfiles)
+}
+
+// Builds and conflict-free cache entries are keyed by profile, existing rows were evaluated with the default profile.
+// The unnamed unique constraints from the initial migrations are looked up as their generated names are truncated.
+func upConstraintProfiles(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		CREATE TABLE constraint_profiles (
+			name VARCHAR PRIMARY KEY,
+			description TEXT NOT NULL DEFAULT '',
+			ignored_slot_names TEXT[] NOT NULL DEFAULT '{}',
+			ignored_item_ids TEXT[] NOT NULL DEFAULT '{}',
+			created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now()
+		);
+
+		INSERT INTO constraint_profiles (name, description, ignored_slot_names) VALUES
+			('default', 'Optics, underbarrel launchers and tactical devices left empty', '{Scope,Ubgl,Tactical}'),
+			('iron-sights', 'As default, with mounts left empty too', '{Scope,Ubgl,Tactical,Mount}');
+
+		DO $$
+		DECLARE
+			constraint_name TEXT;
+		BEGIN
+			SELECT conname INTO constraint_name FROM pg_constraint
+			WHERE conrelid = 'optimum_builds'::regclass AND contype = 'u';
+			EXECUTE format('ALTER TABLE optimum_builds DROP CONSTRAINT %I', constraint_name);
+
+			SELECT conname INTO constraint_name FROM pg_constraint
+			WHERE conrelid = 'conflict_free_cache'::regclass AND contype = 'u';
+			EXECUTE format('ALTER TABLE conflict_free_cache DROP CONSTRAINT %I', constraint_name);
+		END $$;
+
+		ALTER TABLE optimum_builds
+		ADD COLUMN profile VARCHAR NOT NULL DEFAULT 'default' REFERENCES constraint_profiles (name),
+		ADD CONSTRAINT optimum_builds_build_key
+			UNIQUE (item_id, build_type, profile, jaeger_level, prapor_level, peacekeeper_level, mechanic_level, skier_level);
+
+		ALTER TABLE conflict_free_cache
+		ADD COLUMN profile VARCHAR NOT NULL DEFAULT 'default' REFERENCES constraint_profiles (name) ON DELETE CASCADE,
+		ADD CONSTRAINT conflict_free_cache_entry_key
+			UNIQUE (item_id, focused_stat, profile, jaeger_level, prapor_level, peacekeeper_level, mechanic_level, skier_level);
+	`)
+	return err
+}
+
+func downConstraintProfiles(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		DELETE FROM optimum_builds WHERE profile <> 'default';
+		DELETE FROM conflict_free_cache WHERE profile <> 'default';
+
+		ALTER TABLE optimum_builds
+		DROP CONSTRAINT optimum_builds_build_key,
+		DROP COLUMN profile,
+		ADD UNIQUE (item_id, build_type, jaeger_level, prapor_level, peacekeeper_level, mechanic_level, skier_level);
+
+		ALTER TABLE conflict_free_cache
+		DROP CONSTRAINT conflict_free_cache_entry_key,
+		DROP COLUMN profile,
+		ADD UNIQUE (item_id, focused_stat, jaeger_level, prapor_level, peacekeeper_level, mechanic_level, skier_level);
+
+		DROP TABLE constraint_profiles;
+	`)
+	return err
+}
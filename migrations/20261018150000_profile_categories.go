@@ -0,0 +1,37 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upProfileCategories, downProfileCategories)
+}
+
+// Category rules match weapon_mods.category_name or category_id
+func upProfileCategories(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE constraint_profiles
+		ADD COLUMN excluded_categories TEXT[] NOT NULL DEFAULT '{}',
+		ADD COLUMN required_categories TEXT[] NOT NULL DEFAULT '{}';
+
+		INSERT INTO constraint_profiles (name, description, ignored_slot_names, excluded_categories) VALUES
+			('no-suppressor', 'As default, without suppressors', '{Scope,Ubgl,Tactical}', '{Silencer}');
+	`)
+	return err
+}
+
+func downProfileCategories(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		DELETE FROM optimum_builds WHERE profile = 'no-suppressor';
+		DELETE FROM constraint_profiles WHERE name = 'no-suppressor';
+
+		ALTER TABLE constraint_profiles
+		DROP COLUMN excluded_categories,
+		DROP COLUMN required_categories;
+	`)
+	return err
+}
@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upItemWeight, downItemWeight)
+}
+
+// Weights are in kg as given by tarkov.dev, 0 until the next import. A max_weight of 0 means no limit.
+func upItemWeight(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE weapons ADD COLUMN weight DOUBLE PRECISION NOT NULL DEFAULT 0;
+		ALTER TABLE weapon_mods ADD COLUMN weight DOUBLE PRECISION NOT NULL DEFAULT 0;
+		ALTER TABLE constraint_profiles ADD COLUMN max_weight DOUBLE PRECISION NOT NULL DEFAULT 0;
+	`)
+	return err
+}
+
+func downItemWeight(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE weapons DROP COLUMN weight;
+		ALTER TABLE weapon_mods DROP COLUMN weight;
+		ALTER TABLE constraint_profiles DROP COLUMN max_weight;
+	`)
+	return err
+}
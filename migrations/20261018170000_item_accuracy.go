@@ -0,0 +1,40 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upItemAccuracy, downItemAccuracy)
+}
+
+// Accuracy data as given by tarkov.dev, 0 until the next import. Only weapons and barrels have a center of impact and
+// deviation.
+func upItemAccuracy(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE weapons ADD COLUMN center_of_impact DOUBLE PRECISION NOT NULL DEFAULT 0;
+		ALTER TABLE weapons ADD COLUMN deviation_curve DOUBLE PRECISION NOT NULL DEFAULT 0;
+		ALTER TABLE weapons ADD COLUMN deviation_max DOUBLE PRECISION NOT NULL DEFAULT 0;
+		ALTER TABLE weapon_mods ADD COLUMN accuracy_modifier DOUBLE PRECISION NOT NULL DEFAULT 0;
+		ALTER TABLE weapon_mods ADD COLUMN center_of_impact DOUBLE PRECISION NOT NULL DEFAULT 0;
+		ALTER TABLE weapon_mods ADD COLUMN deviation_curve DOUBLE PRECISION NOT NULL DEFAULT 0;
+		ALTER TABLE weapon_mods ADD COLUMN deviation_max DOUBLE PRECISION NOT NULL DEFAULT 0;
+	`)
+	return err
+}
+
+func downItemAccuracy(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE weapons DROP COLUMN center_of_impact;
+		ALTER TABLE weapons DROP COLUMN deviation_curve;
+		ALTER TABLE weapons DROP COLUMN deviation_max;
+		ALTER TABLE weapon_mods DROP COLUMN accuracy_modifier;
+		ALTER TABLE weapon_mods DROP COLUMN center_of_impact;
+		ALTER TABLE weapon_mods DROP COLUMN deviation_curve;
+		ALTER TABLE weapon_mods DROP COLUMN deviation_max;
+	`)
+	return err
+}
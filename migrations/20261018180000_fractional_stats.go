@@ -0,0 +1,46 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upFractionalStats, downFractionalStats)
+}
+
+// Stats are stored as decimals to three places, see models.Stat. Existing modifiers were truncated on import, so are
+// only exact again after the next import. Conflict-free cache entries were summed from the truncated modifiers and
+// are cleared, optimum builds are kept until they're purged and re-evaluated.
+func upFractionalStats(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE weapons ALTER COLUMN recoil_modifier TYPE NUMERIC(12, 3);
+		ALTER TABLE weapons ALTER COLUMN ergonomics_modifier TYPE NUMERIC(12, 3);
+		ALTER TABLE weapon_mods ALTER COLUMN recoil_modifier TYPE NUMERIC(12, 3);
+		ALTER TABLE weapon_mods ALTER COLUMN ergonomics_modifier TYPE NUMERIC(12, 3);
+		ALTER TABLE optimum_builds ALTER COLUMN recoil_sum TYPE NUMERIC(12, 3);
+		ALTER TABLE optimum_builds ALTER COLUMN ergonomics_sum TYPE NUMERIC(12, 3);
+		DELETE FROM conflict_free_cache;
+		ALTER TABLE conflict_free_cache ALTER COLUMN recoil_sum TYPE NUMERIC(12, 3);
+		ALTER TABLE conflict_free_cache ALTER COLUMN ergonomics_sum TYPE NUMERIC(12, 3);
+	`)
+	return err
+}
+
+// downFractionalStats rounds stats back to integers, unlike the original import which truncated them
+func downFractionalStats(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE weapons ALTER COLUMN recoil_modifier TYPE INT USING round(recoil_modifier);
+		ALTER TABLE weapons ALTER COLUMN ergonomics_modifier TYPE INT USING round(ergonomics_modifier);
+		ALTER TABLE weapon_mods ALTER COLUMN recoil_modifier TYPE INT USING round(recoil_modifier);
+		ALTER TABLE weapon_mods ALTER COLUMN ergonomics_modifier TYPE INT USING round(ergonomics_modifier);
+		ALTER TABLE optimum_builds ALTER COLUMN recoil_sum TYPE INTEGER USING round(recoil_sum);
+		ALTER TABLE optimum_builds ALTER COLUMN ergonomics_sum TYPE INTEGER USING round(ergonomics_sum);
+		DELETE FROM conflict_free_cache;
+		ALTER TABLE conflict_free_cache ALTER COLUMN recoil_sum TYPE INT;
+		ALTER TABLE conflict_free_cache ALTER COLUMN ergonomics_sum TYPE INT;
+	`)
+	return err
+}
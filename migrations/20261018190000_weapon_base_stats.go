@@ -0,0 +1,31 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upWeaponBaseStats, downWeaponBaseStats)
+}
+
+// The bare weapon's recoil and ergonomics as given by tarkov.dev, 0 until the next import
+func upWeaponBaseStats(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE weapons ADD COLUMN recoil_vertical INT NOT NULL DEFAULT 0;
+		ALTER TABLE weapons ADD COLUMN recoil_horizontal INT NOT NULL DEFAULT 0;
+		ALTER TABLE weapons ADD COLUMN ergonomics DOUBLE PRECISION NOT NULL DEFAULT 0;
+	`)
+	return err
+}
+
+func downWeaponBaseStats(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE weapons DROP COLUMN recoil_vertical;
+		ALTER TABLE weapons DROP COLUMN recoil_horizontal;
+		ALTER TABLE weapons DROP COLUMN ergonomics;
+	`)
+	return err
+}
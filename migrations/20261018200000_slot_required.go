@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upSlotRequired, downSlotRequired)
+}
+
+// Whether the game requires a slot to be filled, false until the next import
+func upSlotRequired(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE slots ADD COLUMN required BOOLEAN NOT NULL DEFAULT false;
+	`)
+	return err
+}
+
+func downSlotRequired(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE slots DROP COLUMN required;
+	`)
+	return err
+}
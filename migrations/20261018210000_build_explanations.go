@@ -0,0 +1,27 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/pressly/goose/v3"
+)
+
+func init() {
+	goose.AddMigrationContext(upBuildExplanations, downBuildExplanations)
+}
+
+// Why each item of a build was chosen, null for builds evaluated before explanations were stored
+func upBuildExplanations(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE optimum_builds ADD COLUMN explanation JSONB;
+	`)
+	return err
+}
+
+func downBuildExplanations(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `
+		ALTER TABLE optimum_builds DROP COLUMN explanation;
+	`)
+	return err
+}